@@ -0,0 +1,266 @@
+package simba
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed openapi.json
+var OpenAPIDocument []byte
+
+const (
+	DefaultPerPage = 50
+	MaxPerPage     = 500
+)
+
+// Page is the slice of results asked for, pages start at 1.
+type Page struct {
+	Number  int
+	PerPage int
+}
+
+// ParsePage reads the page and per_page query parameters, empty ones taking their defaults.
+func ParsePage(page, perPage string) (Page, error) {
+	p := Page{Number: 1, PerPage: DefaultPerPage}
+	if page != "" {
+		number, err := strconv.Atoi(page)
+		if err != nil || number < 1 {
+			return p, fmt.Errorf("wrong page %q, expected a number from 1", page)
+		}
+		p.Number = number
+	}
+	if perPage != "" {
+		size, err := strconv.Atoi(perPage)
+		if err != nil || size < 1 || size > MaxPerPage {
+			return p, fmt.Errorf("wrong per_page %q, expected a number from 1 to %d", perPage, MaxPerPage)
+		}
+		p.PerPage = size
+	}
+	return p, nil
+}
+
+func (p Page) offset() int {
+	return (p.Number - 1) * p.PerPage
+}
+
+// PageResponse is the envelope of every paginated API answer.
+type PageResponse struct {
+	Data    interface{} `json:"data"`
+	Page    int         `json:"page"`
+	PerPage int         `json:"per_page"`
+	Total   int64       `json:"total"`
+}
+
+func newPageResponse(data interface{}, page Page, total int64) *PageResponse {
+	return &PageResponse{Data: data, Page: page.Number, PerPage: page.PerPage, Total: total}
+}
+
+// APIUser is a user as exposed by the API.
+type APIUser struct {
+	SlackUserID string     `json:"slack_user_id"`
+	Username    string     `json:"username"`
+	ChannelID   string     `json:"channel_id"`
+	IsManager   bool       `json:"is_manager"`
+	ManagerID   string     `json:"manager_slack_user_id"`
+	PausedUntil *time.Time `json:"paused_until"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+func newAPIUser(user *User) APIUser {
+	return APIUser{
+		SlackUserID: user.SlackUserID,
+		Username:    user.Username,
+		ChannelID:   user.SlackChannelId,
+		IsManager:   user.IsManager,
+		ManagerID:   user.ManagerSlackUserID,
+		PausedUntil: user.PausedUntil,
+		CreatedAt:   user.CreatedAt,
+	}
+}
+
+// FetchUsersPage lists the users of channelId (all when empty), oldest first.
+func FetchUsersPage(dbClient *gorm.DB, channelId string, page Page) (*PageResponse, error) {
+	tx := dbClient.Model(&User{})
+	if channelId != "" {
+		tx = tx.Where("slack_channel_id = ?", channelId)
+	}
+	var total int64
+	if err := tx.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	var users []*User
+	if err := tx.Order("id").Offset(page.offset()).Limit(page.PerPage).Find(&users).Error; err != nil {
+		return nil, err
+	}
+	data := make([]APIUser, len(users))
+	for idx, user := range users {
+		data[idx] = newAPIUser(user)
+	}
+	return newPageResponse(data, page, total), nil
+}
+
+// FetchAPIUser returns the user of slackUserId or nil when unknown.
+func FetchAPIUser(dbClient *gorm.DB, slackUserId string) (*APIUser, error) {
+	var user User
+	if tx := dbClient.Find(&user, "slack_user_id = ?", slackUserId); tx.Error != nil {
+		return nil, tx.Error
+	} else if user.ID == 0 {
+		return nil, nil
+	}
+	apiUser := newAPIUser(&user)
+	return &apiUser, nil
+}
+
+// FetchMoodsPage lists the moods matching filter, newest first.
+func FetchMoodsPage(dbClient *gorm.DB, filter *ExportFilter, page Page) (*PageResponse, error) {
+	var total int64
+	if err := filteredMoods(dbClient, filter).Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	moods := []ExportRow{}
+	tx := filteredMoods(dbClient, filter).
+		Select(exportColumns).
+		Order("daily_moods.created_at DESC").
+		Offset(page.offset()).
+		Limit(page.PerPage).
+		Scan(&moods)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return newPageResponse(moods, page, total), nil
+}
+
+// DailySession is a daily mood message and the answers it got.
+type DailySession struct {
+	ThreadTS string    `json:"thread_ts"`
+	PostedAt time.Time `json:"posted_at"`
+	Answers  int       `json:"answers"`
+	DaysOff  int       `json:"days_off"`
+	Good     int       `json:"good_mood"`
+	Average  int       `json:"average_mood"`
+	Bad      int       `json:"bad_mood"`
+	// Suppressed is true when the moods, answers and days off are hidden, too few people having answered
+	Suppressed bool `json:"suppressed"`
+}
+
+// FetchDailySessionsPage lists the daily mood messages answered by people matching filter, newest first.
+// It returns ErrGroupTooSmall when filter matches fewer than filter.MinGroupSize people, the days
+// listed telling when they answered (ie: the days someone was in a bad mood).
+func FetchDailySessionsPage(dbClient *gorm.DB, filter *ExportFilter, page Page) (*PageResponse, error) {
+	if err := CheckGroupSize(dbClient, filter); err != nil {
+		return nil, err
+	}
+	var total int64
+	countTx := dbClient.Table("(?) AS sessions", filteredMoods(dbClient, filter).
+		Select("daily_moods.thread_ts").
+		Group("daily_moods.thread_ts"),
+	).Count(&total)
+	if countTx.Error != nil {
+		return nil, countTx.Error
+	}
+
+	sessions := []DailySession{}
+	tx := filteredMoods(dbClient, filter).
+		Select(
+			"daily_moods.thread_ts AS thread_ts, "+
+				"SUM(CASE WHEN daily_moods.status <> ? THEN 1 ELSE 0 END) AS answers, "+
+				"SUM(CASE WHEN daily_moods.status = ? THEN 1 ELSE 0 END) AS days_off, "+
+				"SUM(CASE WHEN daily_moods.status <> ? AND daily_moods.mood = 'good_mood' THEN 1 ELSE 0 END) AS good, "+
+				"SUM(CASE WHEN daily_moods.status <> ? AND daily_moods.mood = 'average_mood' THEN 1 ELSE 0 END) AS average, "+
+				"SUM(CASE WHEN daily_moods.status <> ? AND daily_moods.mood = 'bad_mood' THEN 1 ELSE 0 END) AS bad",
+			DailyMoodStatusOff, DailyMoodStatusOff, DailyMoodStatusOff, DailyMoodStatusOff, DailyMoodStatusOff,
+		).
+		Group("daily_moods.thread_ts").
+		Order("daily_moods.thread_ts DESC").
+		Offset(page.offset()).
+		Limit(page.PerPage).
+		Scan(&sessions)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	for idx := range sessions {
+		if postedAt, err := ThreadTSTime(sessions[idx].ThreadTS); err == nil {
+			sessions[idx].PostedAt = postedAt
+		}
+		// Everyone answers a daily mood once
+		if !IsAnonymous(sessions[idx].Answers, filter.MinGroupSize) {
+			sessions[idx].Good, sessions[idx].Average, sessions[idx].Bad = 0, 0, 0
+			sessions[idx].Answers, sessions[idx].DaysOff = 0, 0
+			sessions[idx].Suppressed = true
+		}
+	}
+	return newPageResponse(sessions, page, total), nil
+}
+
+// MoodStats aggregates the moods matching a filter.
+type MoodStats struct {
+	Answers     int                `json:"answers"`
+	DaysOff     int                `json:"days_off"`
+	People      int                `json:"people"`
+	Moods       map[string]int     `json:"moods"`
+	Percentages map[string]float64 `json:"percentages"`
+	// Score is the average mood from 0 (all bad) to 100 (all good), null without answer
+	Score *float64 `json:"score"`
+	// Suppressed is true when every count, percentage and score is hidden, too few people having answered
+	Suppressed bool `json:"suppressed"`
+}
+
+// ComputeMoodStats aggregates moods, days off included. Everything but Suppressed is zeroed
+// when fewer than minGroupSize people shared a mood, counts narrowed by a filter (ie: the bad
+// moods of someone) telling as much as the moods themselves.
+func ComputeMoodStats(moods []DailyMood, minGroupSize int) *MoodStats {
+	stats := &MoodStats{Moods: map[string]int{}, Percentages: map[string]float64{}}
+	people := map[uint]bool{}
+	scoreSum := 0.0
+	for _, m := range moods {
+		people[m.UserID] = true
+		if m.IsOff() {
+			stats.DaysOff++
+			continue
+		}
+		if score, isMood := moodScores[m.Mood]; isMood {
+			stats.Answers++
+			stats.Moods[m.Mood]++
+			scoreSum += score
+		}
+	}
+	stats.People = len(people)
+	for _, mood := range Moods {
+		// Every mood is listed, even without answer
+		count := stats.Moods[mood]
+		stats.Moods[mood] = count
+		if stats.Answers > 0 {
+			stats.Percentages[mood] = float64(count) / float64(stats.Answers) * 100
+		}
+	}
+	if !IsAnonymous(CountPeopleWithMood(moods), minGroupSize) {
+		stats.Suppressed = true
+		stats.Answers, stats.DaysOff, stats.People = 0, 0, 0
+		for _, mood := range Moods {
+			stats.Moods[mood] = 0
+		}
+		stats.Percentages = map[string]float64{}
+	} else if stats.Answers > 0 {
+		score := scoreSum / float64(stats.Answers)
+		stats.Score = &score
+	}
+	return stats
+}
+
+// FetchMoodStats aggregates the moods matching filter.
+func FetchMoodStats(dbClient *gorm.DB, filter *ExportFilter) (*MoodStats, error) {
+	var moods []DailyMood
+	tx := filteredMoods(dbClient, filter).
+		Select("daily_moods.user_id, daily_moods.mood, daily_moods.status").
+		Scan(&moods)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return ComputeMoodStats(moods, filter.MinGroupSize), nil
+}
@@ -0,0 +1,69 @@
+package simba_test
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"testing"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePage(t *testing.T) {
+	page, err := simba.ParsePage("", "")
+	assert.Nil(t, err)
+	assert.Equal(t, simba.Page{Number: 1, PerPage: simba.DefaultPerPage}, page)
+
+	page, err = simba.ParsePage("3", "20")
+	assert.Nil(t, err)
+	assert.Equal(t, simba.Page{Number: 3, PerPage: 20}, page)
+
+	_, err = simba.ParsePage("0", "")
+	assert.NotNil(t, err)
+	_, err = simba.ParsePage("", "501")
+	assert.NotNil(t, err)
+	_, err = simba.ParsePage("one", "")
+	assert.NotNil(t, err)
+}
+
+func TestComputeMoodStats(t *testing.T) {
+	stats := simba.ComputeMoodStats([]simba.DailyMood{
+		{UserID: 1, Mood: "good_mood"},
+		{UserID: 1, Mood: "good_mood"},
+		{UserID: 2, Mood: "bad_mood"},
+		{UserID: 3, Status: simba.DailyMoodStatusOff},
+	}, 1)
+	assert.Equal(t, 3, stats.Answers)
+	assert.Equal(t, 1, stats.DaysOff)
+	assert.Equal(t, 3, stats.People)
+	assert.Equal(t, map[string]int{"good_mood": 2, "average_mood": 0, "bad_mood": 1}, stats.Moods)
+	assert.InDelta(t, 66.67, stats.Percentages["good_mood"], 0.01)
+	assert.InDelta(t, 66.67, *stats.Score, 0.01)
+
+	empty := simba.ComputeMoodStats(nil, 1)
+	assert.Nil(t, empty.Score)
+	assert.Equal(t, 0, empty.Moods["bad_mood"])
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	var document struct {
+		OpenAPI string                 `json:"openapi"`
+		Paths   map[string]interface{} `json:"paths"`
+	}
+	assert.Nil(t, json.Unmarshal(simba.OpenAPIDocument, &document))
+	assert.Equal(t, "3.0.3", document.OpenAPI)
+	for _, path := range []string{"/users", "/users/{id}", "/moods", "/sessions", "/stats"} {
+		assert.Contains(t, document.Paths, path)
+	}
+}
+
+func TestFetchDailySessionsPageGroupTooSmall(t *testing.T) {
+	// The days a single person was in a bad mood, as filtered by /sessions?user=U&mood=bad_mood
+	db, fake := newFakeGormDB(t, map[string]fakeTable{
+		"daily_moods": {Columns: []string{"count"}, Rows: [][]driver.Value{{int64(1)}}},
+	})
+	filter := &simba.ExportFilter{SlackUserID: "U1", Mood: "bad_mood", MinGroupSize: 3}
+	_, err := simba.FetchDailySessionsPage(db, filter, simba.Page{Number: 1, PerPage: simba.DefaultPerPage})
+	assert.ErrorIs(t, err, simba.ErrGroupTooSmall)
+	assert.Len(t, fake.Queries("SELECT daily_moods.thread_ts"), 0)
+}
@@ -0,0 +1,145 @@
+package simba
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ScopeReadStats  = "read:stats"
+	ScopeReadMoods  = "read:moods"
+	ScopeWriteMoods = "write:moods"
+
+	apiTokenPrefix = "simba_"
+)
+
+// APIScopes lists every scope a token can be given.
+var APIScopes = []string{ScopeReadStats, ScopeReadMoods, ScopeWriteMoods}
+
+// APIToken is a token of a non-Slack client, only its hash is stored.
+type APIToken struct {
+	gorm.Model
+	Name string
+	// Hint is the beginning of the token so people can tell them apart
+	Hint                 string
+	Hash                 string `gorm:"uniqueIndex"`
+	Scopes               string
+	CreatedBySlackUserID string
+	LastUsedAt           *time.Time
+}
+
+// ScopeList returns the scopes of the token.
+func (t *APIToken) ScopeList() []string {
+	return splitList(t.Scopes)
+}
+
+// HasScope tells if the token has been given scope.
+func (t *APIToken) HasScope(scope string) bool {
+	for _, s := range t.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateScopes checks scopes are known and that there is at least one.
+func ValidateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return fmt.Errorf("a token needs at least one scope")
+	}
+	for _, scope := range scopes {
+		known := false
+		for _, apiScope := range APIScopes {
+			known = known || scope == apiScope
+		}
+		if !known {
+			return fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+	return nil
+}
+
+// HashAPIToken is the stored form of a token.
+func HashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CreateAPIToken generates a token with scopes and returns it in clear, which is the only time it is.
+func CreateAPIToken(
+	dbClient *gorm.DB,
+	name string,
+	scopes []string,
+	createdBySlackUserId string,
+) (string, *APIToken, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", nil, fmt.Errorf("a token needs a name")
+	} else if err := ValidateScopes(scopes); err != nil {
+		return "", nil, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+	token := apiTokenPrefix + hex.EncodeToString(secret)
+	apiToken := &APIToken{
+		Name:                 strings.TrimSpace(name),
+		Hint:                 token[:len(apiTokenPrefix)+6],
+		Hash:                 HashAPIToken(token),
+		Scopes:               strings.Join(scopes, ","),
+		CreatedBySlackUserID: createdBySlackUserId,
+	}
+	if tx := dbClient.Create(apiToken); tx.Error != nil {
+		return "", nil, tx.Error
+	}
+	return token, apiToken, nil
+}
+
+// FetchAPITokens lists the tokens not revoked, newest first.
+func FetchAPITokens(dbClient *gorm.DB) ([]APIToken, error) {
+	var tokens []APIToken
+	if tx := dbClient.Order("created_at DESC").Find(&tokens); tx.Error != nil {
+		return nil, tx.Error
+	}
+	return tokens, nil
+}
+
+// RevokeAPIToken deletes the token so it is refused from now on.
+func RevokeAPIToken(dbClient *gorm.DB, id uint) error {
+	tx := dbClient.Delete(&APIToken{}, id)
+	if tx.Error != nil {
+		return tx.Error
+	} else if tx.RowsAffected == 0 {
+		return fmt.Errorf("API token %d does not exist", id)
+	}
+	return nil
+}
+
+// AuthenticateAPIToken returns the token matching the clear token, or nil if there is none,
+// and records when it has been used.
+func AuthenticateAPIToken(dbClient *gorm.DB, token string) (*APIToken, error) {
+	if !strings.HasPrefix(token, apiTokenPrefix) {
+		return nil, nil
+	}
+	var apiToken APIToken
+	if tx := dbClient.Find(&apiToken, "hash = ?", HashAPIToken(token)); tx.Error != nil {
+		return nil, tx.Error
+	} else if apiToken.ID == 0 {
+		return nil, nil
+	}
+
+	now := time.Now()
+	if tx := dbClient.Model(&apiToken).UpdateColumn("last_used_at", now); tx.Error != nil {
+		return nil, tx.Error
+	}
+	apiToken.LastUsedAt = &now
+	return &apiToken, nil
+}
@@ -0,0 +1,29 @@
+package simba_test
+
+import (
+	"testing"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashAPIToken(t *testing.T) {
+	hash := simba.HashAPIToken("simba_fake_token")
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, simba.HashAPIToken("simba_fake_token"))
+	assert.NotEqual(t, hash, simba.HashAPIToken("simba_other_token"))
+}
+
+func TestValidateScopes(t *testing.T) {
+	assert.Nil(t, simba.ValidateScopes([]string{simba.ScopeReadStats, simba.ScopeWriteMoods}))
+	assert.EqualError(t, simba.ValidateScopes(nil), "a token needs at least one scope")
+	assert.EqualError(t, simba.ValidateScopes([]string{"admin"}), "unknown scope \"admin\"")
+}
+
+func TestAPITokenHasScope(t *testing.T) {
+	token := &simba.APIToken{Scopes: "read:stats,read:moods"}
+	assert.Equal(t, []string{simba.ScopeReadStats, simba.ScopeReadMoods}, token.ScopeList())
+	assert.True(t, token.HasScope(simba.ScopeReadMoods))
+	assert.False(t, token.HasScope(simba.ScopeWriteMoods))
+	assert.False(t, (&simba.APIToken{}).HasScope(simba.ScopeReadStats))
+}
@@ -0,0 +1,44 @@
+package simba
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// FetchChannelSetting returns the settings of a channel (empty ones if never set).
+func FetchChannelSetting(dbClient *gorm.DB, channelId string) (*ChannelSetting, error) {
+	setting := &ChannelSetting{ChannelID: channelId}
+	if tx := dbClient.Find(setting, "channel_id = ?", channelId); tx.Error != nil {
+		return nil, tx.Error
+	}
+	return setting, nil
+}
+
+// SetChannelQuoteProvider chooses the quote provider used by the daily mood of a channel.
+// Local files can only be chosen by whoever deploys Simba, with APP_QUOTE_PROVIDER.
+func SetChannelQuoteProvider(dbClient *gorm.DB, channelId, kind, source string) (*ChannelSetting, error) {
+	if kind == QuoteProviderFile {
+		return nil, fmt.Errorf("quote provider %s can only be set with APP_QUOTE_PROVIDER", kind)
+	}
+	if _, err := NewQuoteProvider(dbClient, channelId, kind, source); err != nil {
+		return nil, err
+	}
+	setting, err := FetchChannelSetting(dbClient, channelId)
+	if err != nil {
+		return nil, err
+	}
+	setting.QuoteProvider = kind
+	setting.QuoteSource = source
+	if tx := dbClient.Save(setting); tx.Error != nil {
+		return nil, tx.Error
+	}
+	return setting, nil
+}
+
+type ChannelSetting struct {
+	gorm.Model
+	ChannelID     string `gorm:"uniqueIndex"`
+	QuoteProvider string
+	QuoteSource   string
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/saisona/simba"
+	"gorm.io/gorm"
+)
+
+// apiError is the body of every API error
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// @desc Reject API calls without a bearer API token having scope
+func requireScope(dbClient *gorm.DB, scope string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token := strings.TrimPrefix(c.Request().Header.Get(echo.HeaderAuthorization), "Bearer ")
+			apiToken, err := simba.AuthenticateAPIToken(dbClient, token)
+			if err != nil {
+				return apiInternalError(c, err)
+			} else if apiToken == nil {
+				return c.JSON(http.StatusUnauthorized, apiError{Error: "missing or wrong API token"})
+			} else if !apiToken.HasScope(scope) {
+				return c.JSON(http.StatusForbidden, apiError{Error: fmt.Sprintf("the API token needs the %s scope", scope)})
+			}
+			return next(c)
+		}
+	}
+}
+
+// @desc Register the read-only /api/v1 routes, the OpenAPI document being public
+func registerAPIRoutes(e *echo.Echo, dbClient *gorm.DB, config *simba.Config) {
+	e.GET("/api/v1/openapi.json", func(c echo.Context) error {
+		return c.Blob(http.StatusOK, echo.MIMEApplicationJSON, simba.OpenAPIDocument)
+	})
+
+	readMoods := requireScope(dbClient, simba.ScopeReadMoods)
+	readStats := requireScope(dbClient, simba.ScopeReadStats)
+	api := e.Group("/api/v1")
+	api.GET("/users", func(c echo.Context) error {
+		return handleAPIUsers(c, dbClient)
+	}, readMoods)
+	api.GET("/users/:id", func(c echo.Context) error {
+		return handleAPIUser(c, dbClient)
+	}, readMoods)
+	api.GET("/moods", func(c echo.Context) error {
+		return handleAPIMoods(c, dbClient, config)
+	}, readMoods)
+	api.GET("/sessions", func(c echo.Context) error {
+		return handleAPISessions(c, dbClient, config)
+	}, readStats)
+	api.GET("/stats", func(c echo.Context) error {
+		return handleAPIStats(c, dbClient, config)
+	}, readStats)
+}
+
+func apiBadRequest(c echo.Context, err error) error {
+	return c.JSON(http.StatusBadRequest, apiError{Error: err.Error()})
+}
+
+func apiInternalError(c echo.Context, err error) error {
+	c.Logger().Errorf("API %s failed : %s", c.Path(), err.Error())
+	return c.JSON(http.StatusInternalServerError, apiError{Error: "internal error"})
+}
+
+// @desc Answer 422 when a filter matches too few people to stay anonymous, 500 for other errors
+func apiGroupSizeError(c echo.Context, err error) error {
+	if errors.Is(err, simba.ErrGroupTooSmall) {
+		return c.JSON(http.StatusUnprocessableEntity, apiError{Error: err.Error()})
+	}
+	return apiInternalError(c, err)
+}
+
+// @desc Read the filters shared by moods, sessions and stats from the query
+func apiMoodFilter(c echo.Context) (*simba.ExportFilter, error) {
+	return simba.NewExportFilter(
+		c.QueryParam("from"),
+		c.QueryParam("to"),
+		c.QueryParam("channel"),
+		c.QueryParam("user"),
+		c.QueryParam("mood"),
+	)
+}
+
+func handleAPIUsers(c echo.Context, dbClient *gorm.DB) error {
+	page, err := simba.ParsePage(c.QueryParam("page"), c.QueryParam("per_page"))
+	if err != nil {
+		return apiBadRequest(c, err)
+	}
+	users, err := simba.FetchUsersPage(dbClient, c.QueryParam("channel"), page)
+	if err != nil {
+		return apiInternalError(c, err)
+	}
+	return c.JSON(http.StatusOK, users)
+}
+
+func handleAPIUser(c echo.Context, dbClient *gorm.DB) error {
+	user, err := simba.FetchAPIUser(dbClient, c.Param("id"))
+	if err != nil {
+		return apiInternalError(c, err)
+	} else if user == nil {
+		return c.JSON(http.StatusNotFound, apiError{Error: "unknown user"})
+	}
+	return c.JSON(http.StatusOK, user)
+}
+
+func handleAPIMoods(c echo.Context, dbClient *gorm.DB, config *simba.Config) error {
+	page, err := simba.ParsePage(c.QueryParam("page"), c.QueryParam("per_page"))
+	if err != nil {
+		return apiBadRequest(c, err)
+	}
+	filter, err := apiMoodFilter(c)
+	if err != nil {
+		return apiBadRequest(c, err)
+	}
+	filter.MinGroupSize = config.MIN_GROUP_SIZE
+	if err := simba.CheckGroupSize(dbClient, filter); err != nil {
+		return apiGroupSizeError(c, err)
+	}
+	moods, err := simba.FetchMoodsPage(dbClient, filter, page)
+	if err != nil {
+		return apiInternalError(c, err)
+	}
+	return c.JSON(http.StatusOK, moods)
+}
+
+func handleAPISessions(c echo.Context, dbClient *gorm.DB, config *simba.Config) error {
+	page, err := simba.ParsePage(c.QueryParam("page"), c.QueryParam("per_page"))
+	if err != nil {
+		return apiBadRequest(c, err)
+	}
+	filter, err := apiMoodFilter(c)
+	if err != nil {
+		return apiBadRequest(c, err)
+	}
+	filter.MinGroupSize = config.MIN_GROUP_SIZE
+	sessions, err := simba.FetchDailySessionsPage(dbClient, filter, page)
+	if err != nil {
+		return apiGroupSizeError(c, err)
+	}
+	return c.JSON(http.StatusOK, sessions)
+}
+
+func handleAPIStats(c echo.Context, dbClient *gorm.DB, config *simba.Config) error {
+	filter, err := apiMoodFilter(c)
+	if err != nil {
+		return apiBadRequest(c, err)
+	}
+	filter.MinGroupSize = config.MIN_GROUP_SIZE
+	stats, err := simba.FetchMoodStats(dbClient, filter)
+	if err != nil {
+		return apiInternalError(c, err)
+	}
+	return c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,492 @@
+package main
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/saisona/simba"
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+const (
+	dashboardSessionCookie = "simba_session"
+	dashboardStateCookie   = "simba_oauth_state"
+)
+
+//go:embed templates/*.html
+var dashboardTemplates embed.FS
+
+var dashboardFuncs = template.FuncMap{
+	"t": simba.T,
+	"day": func(locale string, t time.Time) string {
+		return simba.FormatDay(t, locale)
+	},
+	"percent": func(value float64) string {
+		if math.IsNaN(value) {
+			return "–"
+		}
+		return fmt.Sprintf("%.0f%%", value)
+	},
+}
+
+// dashboardPages are the pages of the dashboard, each one rendered inside the layout
+var dashboardPages = map[string]*template.Template{}
+
+func init() {
+	for _, page := range []string{"message", "team", "person"} {
+		dashboardPages[page] = template.Must(template.New(page).Funcs(dashboardFuncs).ParseFS(
+			dashboardTemplates,
+			"templates/layout.html",
+			fmt.Sprintf("templates/%s.html", page),
+		))
+	}
+}
+
+// dashboardBase is what the layout needs on every page
+type dashboardBase struct {
+	Locale    string
+	SignedIn  bool
+	CanExport bool
+}
+
+type dashboardMessagePage struct {
+	dashboardBase
+	Title string
+	Text  string
+}
+
+// dashboardPerson is a row of the team page, Shares following simba.Moods
+type dashboardPerson struct {
+	SlackUserID   string
+	Username      string
+	Participation string
+	Shares        []float64
+}
+
+type dashboardTeamPage struct {
+	dashboardBase
+	Weeks   int
+	Windows []int
+	Trend   []simba.TrendPoint
+	Team    string
+	Moods   []string
+	People  []dashboardPerson
+	// PeopleHidden tells the mood shares of each person are hidden, MinGroupSize being above one
+	PeopleHidden bool
+	MinGroupSize int
+	From         time.Time
+	To           time.Time
+	ExportFrom   string
+	ExportTo     string
+}
+
+type dashboardPersonPage struct {
+	dashboardBase
+	User          *simba.APIUser
+	Participation string
+	Score         string
+	Moods         []string
+	Shares        []float64
+	DaysOff       int
+	// Hidden tells the mood shares, score and history are hidden, MinGroupSize being above one
+	Hidden       bool
+	MinGroupSize int
+	History      []simba.ExportRow
+	PreviousPage int
+	NextPage     int
+}
+
+func renderDashboard(c echo.Context, status int, page string, data interface{}) error {
+	var html bytes.Buffer
+	if err := dashboardPages[page].ExecuteTemplate(&html, "layout", data); err != nil {
+		return err
+	}
+	return c.HTMLBlob(status, html.Bytes())
+}
+
+func renderDashboardMessage(c echo.Context, status int, base dashboardBase, title, text string) error {
+	return renderDashboard(c, status, "message", dashboardMessagePage{dashboardBase: base, Title: title, Text: text})
+}
+
+// dashboardCookie is only sent back to the dashboard, and only over HTTPS when it is served over HTTPS
+func dashboardCookie(config *simba.Config, name, value string, maxAge time.Duration) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/dashboard",
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   strings.HasPrefix(config.DASHBOARD_URL, "https://"),
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// @desc Register the dashboard routes, only when Sign in with Slack is configured
+func registerDashboardRoutes(e *echo.Echo, dbClient *gorm.DB, slackClient *slack.Client, config *simba.Config) {
+	signIn := simba.NewSlackSignIn(config)
+	if signIn == nil {
+		return
+	}
+	// Only the workspace of the bot can sign in, any Slack account being able to try
+	auth, err := slackClient.AuthTest()
+	if err != nil {
+		log.Printf("[ERROR] dashboard disabled, the Slack workspace is unknown : %s", err.Error())
+		return
+	}
+	signIn.TeamID = auth.TeamID
+
+	e.GET("/dashboard/login", func(c echo.Context) error {
+		return handleDashboardLogin(c, signIn, config)
+	})
+	e.GET("/dashboard/callback", func(c echo.Context) error {
+		return handleDashboardCallback(c, signIn, dbClient, config)
+	})
+	e.POST("/dashboard/logout", func(c echo.Context) error {
+		return handleDashboardLogout(c, dbClient, config)
+	})
+
+	dashboard := e.Group("/dashboard", requireDashboardLead(dbClient, slackClient))
+	dashboard.GET("", func(c echo.Context) error {
+		return handleDashboardTeam(c, dbClient, slackClient, config)
+	})
+	dashboard.GET("/trend.png", func(c echo.Context) error {
+		return handleDashboardTrendChart(c, dbClient, config)
+	})
+	dashboard.GET("/people/:id", func(c echo.Context) error {
+		return handleDashboardPerson(c, dbClient, config)
+	})
+	dashboard.GET("/export", func(c echo.Context) error {
+		return handleRouteExport(c, dbClient, config)
+	}, requireDashboardPermission(simba.PermExport))
+}
+
+// @desc Send people without a session to the sign in, and those not allowed to view the team away
+func requireDashboardLead(dbClient *gorm.DB, slackClient *slack.Client) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			token := ""
+			if cookie, err := c.Cookie(dashboardSessionCookie); err == nil {
+				token = cookie.Value
+			}
+			session, err := simba.FetchDashboardSession(dbClient, token, time.Now())
+			if err != nil {
+				return err
+			} else if session == nil {
+				return c.Redirect(http.StatusFound, "/dashboard/login")
+			}
+
+			role, _, _, err := simba.FetchRole(dbClient, slackClient, session.SlackUserID)
+			if err != nil {
+				return err
+			} else if !simba.Can(role, simba.PermViewTeam) {
+				return renderDashboardMessage(
+					c,
+					http.StatusForbidden,
+					dashboardBase{Locale: session.Locale, SignedIn: true},
+					"dashboard.forbidden.title",
+					"dashboard.forbidden.text",
+				)
+			}
+			audience, err := simba.FetchAudience(dbClient, session.SlackUserID, simba.Can(role, simba.PermViewEveryone))
+			if err != nil {
+				return err
+			}
+			c.Set("locale", session.Locale)
+			c.Set("role", role)
+			c.Set("audience", audience)
+			return next(c)
+		}
+	}
+}
+
+// @desc Forbid a dashboard route to those whose role, set by requireDashboardLead, lacks permission
+func requireDashboardPermission(permission string) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if role, _ := c.Get("role").(string); !simba.Can(role, permission) {
+				locale, _ := c.Get("locale").(string)
+				return renderDashboardMessage(
+					c,
+					http.StatusForbidden,
+					dashboardBase{Locale: locale, SignedIn: true},
+					"dashboard.forbidden.title",
+					"dashboard.forbidden.text",
+				)
+			}
+			return next(c)
+		}
+	}
+}
+
+// dashboardSignedIn is the base of the pages behind requireDashboardLead
+func dashboardSignedIn(c echo.Context, locale string) dashboardBase {
+	role, _ := c.Get("role").(string)
+	return dashboardBase{Locale: locale, SignedIn: true, CanExport: simba.Can(role, simba.PermExport)}
+}
+
+// @desc Start Sign in with Slack, the state being kept in a short lived cookie
+func handleDashboardLogin(c echo.Context, signIn *simba.SlackSignIn, config *simba.Config) error {
+	state, err := simba.RandomToken(16)
+	if err != nil {
+		return err
+	}
+	c.SetCookie(dashboardCookie(config, dashboardStateCookie, state, 10*time.Minute))
+	return c.Redirect(http.StatusFound, signIn.AuthorizeURL(state))
+}
+
+// @desc Finish Sign in with Slack: check the state, trade the code for an identity and open a session
+func handleDashboardCallback(
+	c echo.Context,
+	signIn *simba.SlackSignIn,
+	dbClient *gorm.DB,
+	config *simba.Config,
+) error {
+	base := dashboardBase{Locale: config.LOCALE}
+	state := ""
+	if cookie, err := c.Cookie(dashboardStateCookie); err == nil {
+		state = cookie.Value
+	}
+	c.SetCookie(dashboardCookie(config, dashboardStateCookie, "", -time.Second))
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(c.QueryParam("state"))) != 1 {
+		return renderDashboardMessage(c, http.StatusBadRequest, base, "dashboard.login.failed", "dashboard.login.state")
+	} else if c.QueryParam("error") != "" {
+		return renderDashboardMessage(c, http.StatusUnauthorized, base, "dashboard.login.failed", "dashboard.login.denied")
+	}
+
+	identity, err := signIn.Exchange(c.QueryParam("code"))
+	if errors.Is(err, simba.ErrWrongTeam) {
+		c.Logger().Warnf("Sign in with Slack refused : %s", err.Error())
+		return renderDashboardMessage(c, http.StatusForbidden, base, "dashboard.login.failed", "dashboard.login.team")
+	} else if err != nil {
+		c.Logger().Errorf("Sign in with Slack failed : %s", err.Error())
+		return renderDashboardMessage(c, http.StatusBadGateway, base, "dashboard.login.failed", "dashboard.login.error")
+	}
+	token, err := simba.CreateDashboardSession(dbClient, identity.SlackUserID, identity.Locale, time.Now())
+	if err != nil {
+		return err
+	}
+	c.SetCookie(dashboardCookie(config, dashboardSessionCookie, token, simba.DashboardSessionDuration))
+	return c.Redirect(http.StatusFound, "/dashboard")
+}
+
+// @desc Close the session of the browser
+func handleDashboardLogout(c echo.Context, dbClient *gorm.DB, config *simba.Config) error {
+	if cookie, err := c.Cookie(dashboardSessionCookie); err == nil {
+		if err := simba.DeleteDashboardSession(dbClient, cookie.Value, time.Now()); err != nil {
+			return err
+		}
+	}
+	c.SetCookie(dashboardCookie(config, dashboardSessionCookie, "", -time.Second))
+	return renderDashboardMessage(
+		c,
+		http.StatusOK,
+		dashboardBase{Locale: config.LOCALE},
+		"dashboard.logout.title",
+		"dashboard.logout.text",
+	)
+}
+
+// dashboardWeeks reads the weeks query parameter, only simba.TrendWindows being allowed
+func dashboardWeeks(value string) int {
+	weeks, err := strconv.Atoi(value)
+	if err == nil {
+		for _, window := range simba.TrendWindows {
+			if weeks == window {
+				return weeks
+			}
+		}
+	}
+	return simba.TrendWindows[0]
+}
+
+// @desc Render the team page: trend, participation and mood shares of everyone, and exports
+// @params query weeks, one of simba.TrendWindows
+func handleDashboardTeam(
+	c echo.Context,
+	dbClient *gorm.DB,
+	slackClient *slack.Client,
+	config *simba.Config,
+) error {
+	locale := c.Get("locale").(string)
+	audience := c.Get("audience").(simba.Audience)
+	weeks := dashboardWeeks(c.QueryParam("weeks"))
+	now := time.Now()
+	from := now.AddDate(0, 0, -7*weeks)
+
+	points, err := simba.FetchMoodTrend(
+		dbClient,
+		config.CHANNEL_ID,
+		audience.SlackUserIDList(),
+		config.MIN_GROUP_SIZE,
+		from,
+		now,
+	)
+	if err != nil {
+		return err
+	}
+	members, err := simba.FetchTeamParticipation(
+		dbClient,
+		slackClient,
+		config.CHANNEL_ID,
+		now.Add(-simba.ParticipationPeriod),
+		now,
+	)
+	if err != nil {
+		return err
+	}
+	members = audienceParticipation(audience, members)
+	hvai, err := NewHomeViewAdminInfo(dbClient, audience, members)
+	if err != nil {
+		return err
+	}
+
+	page := dashboardTeamPage{
+		dashboardBase: dashboardSignedIn(c, locale),
+		Weeks:         weeks,
+		Windows:       simba.TrendWindows,
+		Trend:         points,
+		Moods:         simba.Moods,
+		People:        []dashboardPerson{},
+		From:          from,
+		To:            now,
+		ExportFrom:    from.Format(trendDateLayout),
+		ExportTo:      now.Format(trendDateLayout),
+	}
+	if len(members) > 0 {
+		page.Team = simba.ParticipationText(simba.TeamParticipation(members), locale)
+	}
+
+	page.MinGroupSize = config.MIN_GROUP_SIZE
+	page.PeopleHidden = !simba.IsAnonymous(1, config.MIN_GROUP_SIZE)
+	sharesByUser := hvai.avgByUser(hvai.mapByUserCount())
+	for _, coworker := range hvai.Coworkers {
+		person := dashboardPerson{SlackUserID: coworker.SlackUserID, Username: coworker.Username}
+		if participation, ok := hvai.Participation[coworker.Username]; ok {
+			person.Participation = simba.ParticipationText(participation, locale)
+		}
+		shares, hasMoods := sharesByUser[coworker.Username]
+		hasMoods = hasMoods && !page.PeopleHidden
+		for _, mood := range simba.Moods {
+			if hasMoods {
+				person.Shares = append(person.Shares, shares[mood])
+			} else {
+				person.Shares = append(person.Shares, math.NaN())
+			}
+		}
+		page.People = append(page.People, person)
+	}
+	sort.Slice(page.People, func(i, j int) bool {
+		return page.People[i].Username < page.People[j].Username
+	})
+
+	return renderDashboard(c, http.StatusOK, "team", page)
+}
+
+// @desc Render the trend chart of the team page as PNG
+func handleDashboardTrendChart(c echo.Context, dbClient *gorm.DB, config *simba.Config) error {
+	now := time.Now()
+	from := now.AddDate(0, 0, -7*dashboardWeeks(c.QueryParam("weeks")))
+	audience := c.Get("audience").(simba.Audience)
+	points, err := simba.FetchMoodTrend(
+		dbClient,
+		config.CHANNEL_ID,
+		audience.SlackUserIDList(),
+		config.MIN_GROUP_SIZE,
+		from,
+		now,
+	)
+	if err != nil {
+		return err
+	}
+	var chart bytes.Buffer
+	if err := simba.RenderMoodTrend(points, c.Get("locale").(string), &chart); err != nil {
+		return err
+	}
+	return c.Blob(http.StatusOK, "image/png", chart.Bytes())
+}
+
+// @desc Render the history of a person: participation, mood shares and every answer, newest first
+// @params query page
+func handleDashboardPerson(c echo.Context, dbClient *gorm.DB, config *simba.Config) error {
+	locale := c.Get("locale").(string)
+	base := dashboardSignedIn(c, locale)
+	slackUserId := c.Param("id")
+	user, err := simba.FetchAPIUser(dbClient, slackUserId)
+	if err != nil {
+		return err
+	} else if user == nil || !c.Get("audience").(simba.Audience).Includes(slackUserId) {
+		return renderDashboardMessage(c, http.StatusNotFound, base, "dashboard.person.unknown", "dashboard.person.unknown.text")
+	}
+	pageNumber, err := simba.ParsePage(c.QueryParam("page"), "")
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+
+	now := time.Now()
+	participation, err := simba.FetchUserParticipation(
+		dbClient,
+		config.CHANNEL_ID,
+		slackUserId,
+		now.Add(-simba.ParticipationPeriod),
+		now,
+	)
+	if err != nil {
+		return err
+	}
+	filter := &simba.ExportFilter{SlackUserID: slackUserId, MinGroupSize: config.MIN_GROUP_SIZE}
+	stats, err := simba.FetchMoodStats(dbClient, filter)
+	if err != nil {
+		return err
+	}
+
+	page := dashboardPersonPage{
+		dashboardBase: base,
+		User:          user,
+		Participation: simba.ParticipationText(participation, locale),
+		Moods:         simba.Moods,
+		DaysOff:       stats.DaysOff,
+		Hidden:        !simba.IsAnonymous(1, config.MIN_GROUP_SIZE),
+		MinGroupSize:  config.MIN_GROUP_SIZE,
+	}
+	if stats.Score != nil {
+		page.Score = fmt.Sprintf("%.0f", *stats.Score)
+	}
+	for _, mood := range simba.Moods {
+		if stats.Answers > 0 && !page.Hidden {
+			page.Shares = append(page.Shares, stats.Percentages[mood])
+		} else {
+			page.Shares = append(page.Shares, math.NaN())
+		}
+	}
+	if page.Hidden {
+		// The answers of a single person are no more anonymous than their shares
+		return renderDashboard(c, http.StatusOK, "person", page)
+	}
+
+	history, err := simba.FetchMoodsPage(dbClient, filter, pageNumber)
+	if err != nil {
+		return err
+	}
+	page.History = history.Data.([]simba.ExportRow)
+	if pageNumber.Number > 1 {
+		page.PreviousPage = pageNumber.Number - 1
+	}
+	if int64(pageNumber.Number*pageNumber.PerPage) < history.Total {
+		page.NextPage = pageNumber.Number + 1
+	}
+
+	return renderDashboard(c, http.StatusOK, "person", page)
+}
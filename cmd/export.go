@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/saisona/simba"
+	"gorm.io/gorm"
+)
+
+// @desc Stream the moods as CSV or NDJSON
+// @params query from, to (YYYY-MM-DD), channel, user, mood, format and pseudonymise
+func handleRouteExport(c echo.Context, dbClient *gorm.DB, config *simba.Config) error {
+	filter, err := simba.NewExportFilter(
+		c.QueryParam("from"),
+		c.QueryParam("to"),
+		c.QueryParam("channel"),
+		c.QueryParam("user"),
+		c.QueryParam("mood"),
+	)
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	if pseudonymise := c.QueryParam("pseudonymise"); pseudonymise != "" {
+		if filter.Pseudonymise, err = strconv.ParseBool(pseudonymise); err != nil {
+			return c.String(http.StatusBadRequest, fmt.Sprintf("wrong pseudonymise %q", pseudonymise))
+		}
+	}
+	filter.Salt = config.EXPORT_SALT
+	filter.MinGroupSize = config.MIN_GROUP_SIZE
+	// The dashboard only lets managers export the moods of their reports
+	if audience, ok := c.Get("audience").(simba.Audience); ok {
+		filter.Audience = audience.SlackUserIDList()
+	}
+
+	format := c.QueryParam("format")
+	writer, err := simba.NewExportWriter(format, c.Response())
+	if err != nil {
+		return c.String(http.StatusBadRequest, err.Error())
+	}
+	if err := simba.CheckGroupSize(dbClient, filter); errors.Is(err, simba.ErrGroupTooSmall) {
+		return c.String(http.StatusUnprocessableEntity, err.Error())
+	} else if err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, simba.ExportContentType(format))
+	c.Response().Header().Set(
+		echo.HeaderContentDisposition,
+		fmt.Sprintf("attachment; filename=simba_moods.%s", exportExtension(format)),
+	)
+	c.Response().WriteHeader(http.StatusOK)
+	if count, err := simba.ExportMoods(dbClient, filter, writer); err != nil {
+		// Headers are gone already, the client gets a truncated file
+		c.Logger().Errorf("ExportMoods failed after %d rows : %s", count, err.Error())
+		return nil
+	}
+	return nil
+}
+
+func exportExtension(format string) string {
+	if format == "" {
+		return simba.ExportFormatCSV
+	}
+	return format
+}
+
+// @desc Run the export subcommand: simba export [flags], written to stdout unless -output is given
+// @returns the exit code of the process
+func runExportCommand(args []string) int {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := flags.String("format", simba.ExportFormatCSV, "csv or ndjson")
+	from := flags.String("from", "", "first day exported (YYYY-MM-DD)")
+	to := flags.String("to", "", "last day exported (YYYY-MM-DD)")
+	channelId := flags.String("channel", "", "Slack channel id")
+	slackUserId := flags.String("user", "", "Slack user id")
+	mood := flags.String("mood", "", "good_mood, average_mood, bad_mood or off")
+	pseudonymise := flags.Bool("pseudonymise", false, "replace usernames and Slack ids with pseudonyms")
+	output := flags.String("output", "", "file to write, stdout by default")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	filter, err := simba.NewExportFilter(*from, *to, *channelId, *slackUserId, *mood)
+	if err != nil {
+		log.Printf("Wrong filter : %s", err.Error())
+		return 2
+	}
+	filter.Pseudonymise = *pseudonymise
+
+	config, err := simba.InitConfig(false)
+	if err != nil {
+		log.Printf("failed initConfig: %s", err.Error())
+		return 1
+	}
+	filter.Salt = config.EXPORT_SALT
+	filter.MinGroupSize = config.MIN_GROUP_SIZE
+	dbClient := simba.InitDbClient(
+		config.DB.Host,
+		config.DB.Username,
+		config.DB.Password,
+		config.DB.Name,
+		false,
+	)
+	if err := simba.CheckGroupSize(dbClient, filter); err != nil {
+		log.Printf("Cannot export : %s", err.Error())
+		return 1
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			log.Printf("Cannot create %s : %s", *output, err.Error())
+			return 1
+		}
+		defer file.Close()
+		out = file
+	}
+
+	writer, err := simba.NewExportWriter(*format, out)
+	if err != nil {
+		log.Printf("%s", err.Error())
+		return 2
+	}
+	count, err := simba.ExportMoods(dbClient, filter, writer)
+	if err != nil {
+		log.Printf("Export failed after %d rows : %s", count, err.Error())
+		return 1
+	}
+	log.Printf("Exported %d moods", count)
+	return 0
+}
@@ -58,6 +58,7 @@ func initApplication(
 		config.SLACK_API_TOKEN,
 		slack.OptionDebug(true),
 		slack.OptionLog(log.Default()),
+		slack.OptionHTTPClient(simba.NewSlackHTTPClient()),
 	)
 
 	scheduler, _, err := simba.InitScheduler(dbClient, slackClient, config, threadTS)
@@ -76,3 +77,14 @@ func watchValueChanged(valueToChange *string, channel chan string, logger echo.L
 		logger.Printf("Changed slackTS from %s to %s", oldValue, *valueToChange)
 	}
 }
+
+// fetchUsername returns the Slack display name of the user, or its real name as fallback
+func fetchUsername(slackClient *slack.Client, userId string) (string, error) {
+	profile, err := slackClient.GetUserProfile(&slack.GetUserProfileParameters{UserID: userId})
+	if err != nil {
+		return "", err
+	} else if profile.DisplayName != "" {
+		return profile.DisplayName, nil
+	}
+	return profile.RealName, nil
+}
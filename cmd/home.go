@@ -24,16 +24,6 @@ func (hvai homeViewInfo) avgTotal(m map[string]int) map[string]float64 {
 	return avg
 }
 
-func findWorseMoodyPerson(dbClient *gorm.DB) (*simba.User, error) {
-	var moodyUser *simba.User
-	if tx := dbClient.Debug().Find(&moodyUser); tx.Error != nil {
-		return nil, tx.Error
-	} else {
-		// TODO: handle return the right person
-		return moodyUser, nil
-	}
-}
-
 func NewHomeViewInfo(dbClient *gorm.DB, slackUserId, simbaUserId string) (*homeViewInfo, error) {
 	hvi := &homeViewInfo{
 		SlackUserId: slackUserId,
@@ -47,7 +37,7 @@ func NewHomeViewInfo(dbClient *gorm.DB, slackUserId, simbaUserId string) (*homeV
 
 func (hvi *homeViewInfo) fetchWeeklyMoods(dbClient *gorm.DB, simbaUserId string) error {
 	var weeklyMoods []simba.DailyMood
-	if tx := dbClient.Debug().Where("user_id=?", simbaUserId).Limit(7).Order("created_at DESC").Find(&weeklyMoods); tx.Error != nil {
+	if tx := dbClient.Debug().Where("user_id=? AND status <> ?", simbaUserId, simba.DailyMoodStatusOff).Limit(7).Order("created_at DESC").Find(&weeklyMoods); tx.Error != nil {
 		return tx.Error
 	}
 
@@ -72,9 +62,10 @@ func handleAppHomeViewNotAdmin(
 	user *simba.User,
 	config *simba.Config,
 	dbClient *gorm.DB,
+	locale string,
 ) slack.Blocks {
 	// Header
-	basicText := slackTextBlock("Simba Application (Not Admin)")
+	basicText := slackTextBlock(simba.T(locale, "home.title.member"))
 	hvi, err := NewHomeViewInfo(dbClient, user.SlackUserID, fmt.Sprint(user.ID))
 	if err != nil {
 		panic(err)
@@ -94,20 +85,64 @@ func handleAppHomeViewNotAdmin(
 	actionBlock := slack.NewActionBlock(
 		fmt.Sprintf("action_moods_block_%d", time.Now().Unix()),
 		buttonBlockSet...)
-	return slack.Blocks{
-		BlockSet: []slack.Block{
-			slackHeaderBlock,
-			slack.NewDividerBlock(),
-			actionBlock,
-		},
+	blockSet := []slack.Block{slackHeaderBlock, slack.NewDividerBlock(), actionBlock}
+
+	now := time.Now()
+	participation, err := simba.FetchUserParticipation(
+		dbClient,
+		config.CHANNEL_ID,
+		user.SlackUserID,
+		now.Add(-simba.ParticipationPeriod),
+		now,
+	)
+	if err != nil {
+		log.Printf("[ERROR] FetchUserParticipation failed : %s", err.Error())
+	} else {
+		participationText := simba.ParticipationText(participation, locale)
+		blockSet = append(blockSet, slack.NewContextBlock("", slackMkDownBlock(participationText)))
+	}
+	return slack.Blocks{BlockSet: blockSet}
+}
+
+// @desc Render the pause section of the Home tab to tell Simba the user is away
+// @params user is a DB representation of a Simba user (ID is 0 if never registered)
+// @returns Blocks with a date picker and a resume button if currently paused
+func pauseBlocks(user *simba.User, slackUser *slack.User, locale string) []slack.Block {
+	now := time.Now()
+	datePicker := slack.NewDatePickerBlockElement("pause_until")
+	datePicker.Placeholder = slackTextBlock(simba.T(locale, "home.pause.placeholder"))
+
+	var text string
+	switch {
+	case user.IsPaused(now):
+		text = simba.T(locale, "home.pause.active", simba.FormatDay(*user.PausedUntil, locale))
+		datePicker.InitialDate = user.PausedUntil.Format("2006-01-02")
+	case slackUser != nil && simba.IsOutOfOfficeStatus(slackUser.Profile, now):
+		text = simba.T(locale, "home.pause.status")
+	default:
+		text = simba.T(locale, "home.pause.inactive")
 	}
+
+	blocks := []slack.Block{
+		slack.NewDividerBlock(),
+		slack.NewSectionBlock(slackMkDownBlock(text), nil, slack.NewAccessory(datePicker)),
+	}
+	if user.IsPaused(now) {
+		resumeButton := slack.NewButtonBlockElement(
+			"pause_resume",
+			"pause_resume",
+			slackTextBlock(simba.T(locale, "home.pause.resume")),
+		)
+		blocks = append(blocks, slack.NewActionBlock("pause_actions", resumeButton))
+	}
+	return blocks
 }
 
 func handleAppHomeView(
 	slackClient *slack.Client,
 	dbClient *gorm.DB,
 	config *simba.Config,
-	userId string,
+	userId, privateMetadata string,
 ) slack.HomeTabViewRequest {
 	callbackId := fmt.Sprintf("app_home_callback_%d", time.Now().UnixMilli())
 	externalId := fmt.Sprintf("app_home_external_%d", time.Now().UnixMilli())
@@ -116,17 +151,23 @@ func handleAppHomeView(
 		log.Printf("Error during OpenView to fetch Admin = %s", err.Error())
 	}
 	var blocks slack.Blocks
-	if user.IsManager || slackUser.IsAdmin {
-		blocks = handleAppHomeViewAdmin(user, config, dbClient)
+	locale := simba.NormalizeLocale(slackUser.Locale)
+	role := simba.RoleOf(user, slackUser)
+	if simba.Can(role, simba.PermViewTeam) {
+		tr := parseTrendRange(privateMetadata)
+		blocks = handleAppHomeViewAdmin(slackClient, user, role, config, dbClient, locale, tr)
 	} else {
-		blocks = handleAppHomeViewNotAdmin(user, config, dbClient)
+		blocks = handleAppHomeViewNotAdmin(user, config, dbClient, locale)
 	}
+	blocks.BlockSet = append(blocks.BlockSet, pauseBlocks(user, slackUser, locale)...)
+	blocks.BlockSet = append(blocks.BlockSet, emailBlocks(user, slackUser, role, config, locale)...)
 
 	slackModalViewRequest := slack.HomeTabViewRequest{
-		Type:       slack.VTHomeTab,
-		CallbackID: callbackId,
-		ExternalID: externalId,
-		Blocks:     blocks,
+		Type:            slack.VTHomeTab,
+		CallbackID:      callbackId,
+		ExternalID:      externalId,
+		Blocks:          blocks,
+		PrivateMetadata: privateMetadata,
 	}
 
 	return slackModalViewRequest
@@ -139,19 +180,28 @@ func handleAppHomeViewUpdated(
 	userId string,
 	channelId string,
 	channelUsers []*slack.User,
+	privateMetadata string,
 ) slack.HomeTabViewRequest {
 	callbackId := fmt.Sprintf("app_home_callback_%d", time.Now().UnixMilli())
 	externalId := fmt.Sprintf("app_home_external_%d", time.Now().UnixMilli())
-	user, _, err := simba.FechCurrent(dbClient, slackClient, userId)
+	user, slackUser, err := simba.FechCurrent(dbClient, slackClient, userId)
 	if err != nil {
 		log.Printf("Error during OpenView to fetch Admin = %s", err.Error())
 		return slack.HomeTabViewRequest{}
 	}
-	var blocks slack.Blocks = handleAppHomeViewAdmin(user, config, dbClient)
+	role := simba.RoleOf(user, slackUser)
+	if !simba.Can(role, simba.PermViewTeam) {
+		return handleAppHomeView(slackClient, dbClient, config, userId, privateMetadata)
+	}
+	locale := simba.NormalizeLocale(slackUser.Locale)
+	tr := parseTrendRange(privateMetadata)
+	var blocks slack.Blocks = handleAppHomeViewAdmin(slackClient, user, role, config, dbClient, locale, tr)
+	blocks.BlockSet = append(blocks.BlockSet, pauseBlocks(user, slackUser, locale)...)
+	blocks.BlockSet = append(blocks.BlockSet, emailBlocks(user, slackUser, role, config, locale)...)
 
 	for _, user := range channelUsers {
 		userProfile, err := slackClient.GetUserProfile(
-			&slack.GetUserProfileParameters{UserID: userId},
+			&slack.GetUserProfileParameters{UserID: user.ID},
 		)
 		if err != nil {
 			log.Printf("Cannot fetch UserProfile : %s", err.Error())
@@ -163,22 +213,26 @@ func handleAppHomeViewUpdated(
 			}
 		}
 		userListName := slackMkDownBlock(fmt.Sprintf("*%s*", userProfile.DisplayName))
-		msgAction := slack.NewAccessory(
-			slack.NewButtonBlockElement(
-				fmt.Sprintf("direct_message_%s", user.ID),
-				user.ID,
-				slackTextBlock("Send IM"),
-			),
-		)
+		var msgAction *slack.Accessory
+		if simba.Can(role, simba.PermReachOut) {
+			msgAction = slack.NewAccessory(
+				slack.NewButtonBlockElement(
+					fmt.Sprintf("direct_message_%s", user.ID),
+					user.ID,
+					slackTextBlock(simba.T(locale, "home.send_im")),
+				),
+			)
+		}
 		userListItem := slack.NewSectionBlock(userListName, nil, msgAction)
 		blocks.BlockSet = append(blocks.BlockSet, userListItem)
 	}
 
 	slackModalViewRequest := slack.HomeTabViewRequest{
-		Type:       slack.VTHomeTab,
-		CallbackID: callbackId,
-		ExternalID: externalId,
-		Blocks:     blocks,
+		Type:            slack.VTHomeTab,
+		CallbackID:      callbackId,
+		ExternalID:      externalId,
+		Blocks:          blocks,
+		PrivateMetadata: privateMetadata,
 	}
 
 	log.Printf("[DEBUG] slackModalViewRequest=%+v\n", slackModalViewRequest)
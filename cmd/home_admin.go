@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"log"
 	"time"
 
 	"github.com/saisona/simba"
@@ -13,42 +14,85 @@ type homeViewAdminInfo struct {
 	Total       float64
 	TotalByUser map[string]float64
 	Coworkers   []*simba.User
+	// Participation by username of the channel members, people away left out
+	Participation map[string]simba.ParticipationStats
 }
 
-func NewHomeViewAdminInfo(dbClient *gorm.DB) (*homeViewAdminInfo, error) {
+func NewHomeViewAdminInfo(
+	dbClient *gorm.DB,
+	audience simba.Audience,
+	members []simba.ParticipationStats,
+) (*homeViewAdminInfo, error) {
 	hvi := &homeViewAdminInfo{
-		Coworkers:   []*simba.User{},
-		TotalByUser: make(map[string]float64),
+		Coworkers:     []*simba.User{},
+		TotalByUser:   make(map[string]float64),
+		Participation: make(map[string]simba.ParticipationStats),
 	}
-	if err := hvi.fetchWeeklyMoodsByUser(dbClient); err != nil {
+	if err := hvi.fetchWeeklyMoodsByUser(dbClient, audience, members); err != nil {
 		return nil, err
 	}
 	return hvi, nil
 }
 
-func (hvi *homeViewAdminInfo) fetchWeeklyMoodsByUser(dbClient *gorm.DB) error {
-	var coworkers []*simba.User
+func (hvi *homeViewAdminInfo) fetchWeeklyMoodsByUser(
+	dbClient *gorm.DB,
+	audience simba.Audience,
+	members []simba.ParticipationStats,
+) error {
+	var users []*simba.User
 
-	if tx := dbClient.Debug().Find(&coworkers); tx.Error != nil {
+	if tx := dbClient.Debug().Find(&users); tx.Error != nil {
 		return tx.Error
 	}
+	coworkers := []*simba.User{}
+	for _, u := range users {
+		if audience.Includes(u.SlackUserID) {
+			coworkers = append(coworkers, u)
+		}
+	}
+
+	before := time.Now().Add(-simba.ParticipationPeriod)
+	after := time.Now()
+	participationBySlackId := make(map[string]simba.ParticipationStats, len(members))
+	for _, member := range members {
+		participationBySlackId[member.SlackUserID] = member
+	}
 
 	for _, u := range coworkers {
 		var wm []simba.DailyMood
-		before := time.Now().Add(-time.Hour * 24 * 14)
-		after := time.Now()
 		if tx := dbClient.Debug().Where("user_id=?", u.ID).Where("created_at between ? AND ?", before, after).Limit(14).Order("created_at DESC").Find(&wm); tx.Error != nil {
 			return tx.Error
 		}
-		u.Moods = wm
-		hvi.TotalByUser[u.Username] = float64(len(wm))
-		hvi.Total += float64(len(wm))
+		// Days off count as answered but are not moods
+		u.Moods = []simba.DailyMood{}
+		for _, m := range wm {
+			if !m.IsOff() {
+				u.Moods = append(u.Moods, m)
+			}
+		}
+		hvi.TotalByUser[u.Username] = float64(len(u.Moods))
+		hvi.Total += float64(len(u.Moods))
+
+		if participation, ok := participationBySlackId[u.SlackUserID]; ok {
+			hvi.Participation[u.Username] = participation
+		}
 	}
 
 	hvi.Coworkers = coworkers
 	return nil
 }
 
+// peopleWithMood counts the coworkers who shared at least a mood
+func (hvi homeViewAdminInfo) peopleWithMood() int {
+	people := 0
+	for _, u := range hvi.Coworkers {
+		if len(u.Moods) > 0 {
+			people++
+		}
+	}
+	return people
+}
+
 func (hvi homeViewAdminInfo) mapAllCount() map[string]int {
 	var moodCountMap map[string]int = map[string]int{}
 	for _, i := range hvi.Coworkers {
@@ -95,56 +139,183 @@ func (hvi homeViewAdminInfo) mapByUserCount() map[string]map[string]int {
 	return moodCountMap
 }
 
+// audienceParticipation keeps the participation of the people of audience
+func audienceParticipation(audience simba.Audience, members []simba.ParticipationStats) []simba.ParticipationStats {
+	kept := []simba.ParticipationStats{}
+	for _, member := range members {
+		if audience.Includes(member.SlackUserID) {
+			kept = append(kept, member)
+		}
+	}
+	return kept
+}
+
 // @desc Render Home view admin or update depending on slackChannelId is given or not
 // @params user is a DB representation of a Simba user
 // @params [slackChannelId] is optionnal given if already known or not used for update
 // @returns Blocks to be send to update Simba Home view
 func handleAppHomeViewAdmin(
+	slackClient *slack.Client,
 	user *simba.User,
+	role string,
 	config *simba.Config,
 	dbClient *gorm.DB,
+	locale string,
+	tr trendRange,
 ) slack.Blocks {
-	basicText := slackTextBlock("Simba Application (Admin)")
+	basicText := slackTextBlock(simba.T(locale, "home.title.admin"))
 	slackHeaderBlock := slack.NewHeaderBlock(basicText)
 
-	hvai, err := NewHomeViewAdminInfo(dbClient)
+	now := time.Now()
+	members, err := simba.FetchTeamParticipation(
+		dbClient,
+		slackClient,
+		config.CHANNEL_ID,
+		now.Add(-simba.ParticipationPeriod),
+		now,
+	)
+	if err != nil {
+		log.Printf("[ERROR] FetchTeamParticipation failed : %s", err.Error())
+		members = []simba.ParticipationStats{}
+	}
+	audience, err := simba.FetchAudience(dbClient, user.SlackUserID, simba.Can(role, simba.PermViewEveryone))
+	if err != nil {
+		log.Printf("[ERROR] FetchAudience failed : %s", err.Error())
+		audience = simba.Audience{}
+	}
+	members = audienceParticipation(audience, members)
+	hvai, err := NewHomeViewAdminInfo(dbClient, audience, members)
 	if err != nil {
 		panic(err)
 	}
 
-	slackAvgTotalTitleInfo := slack.NewHeaderBlock(slackTextBlock("Week informations"))
+	slackAvgTotalTitleInfo := slack.NewHeaderBlock(slackTextBlock(simba.T(locale, "home.week")))
 
-	blockSet := []slack.Block{slackHeaderBlock, slack.NewDividerBlock(), slackAvgTotalTitleInfo}
-	for u, a := range hvai.avgTotal(hvai.mapAllCount()) {
-		text := fmt.Sprintf("%s %.2f%%", simba.FromMoodToSmiley(u), a)
-		buttonBlock := slack.NewButtonBlockElement("_", "", slackTextBlock(text))
-		actionBlock := slack.NewActionBlock(
-			fmt.Sprintf("total_%s_%d", u, time.Now().Unix()),
-			buttonBlock,
+	blockSet := []slack.Block{slackHeaderBlock, slack.NewDividerBlock()}
+	blockSet = append(blockSet, trendBlocks(slackClient, dbClient, config, audience, tr, locale)...)
+	blockSet = append(blockSet, riskBlocks(dbClient, config, audience, simba.Can(role, simba.PermReachOut), locale)...)
+	blockSet = append(blockSet, slackAvgTotalTitleInfo)
+	if len(members) > 0 {
+		teamText := simba.T(
+			locale,
+			"home.participation.team",
+			simba.ParticipationText(simba.TeamParticipation(members), locale),
 		)
-		blockSet = append(blockSet, actionBlock)
+		blockSet = append(blockSet, slack.NewContextBlock("", slackMkDownBlock(teamText)))
+	}
+	if !simba.IsAnonymous(hvai.peopleWithMood(), config.MIN_GROUP_SIZE) {
+		hiddenText := simba.T(locale, "home.anonymity.team", config.MIN_GROUP_SIZE)
+		blockSet = append(blockSet, slack.NewContextBlock("", slackMkDownBlock(hiddenText)))
+	} else {
+		for u, a := range hvai.avgTotal(hvai.mapAllCount()) {
+			text := fmt.Sprintf("%s %.2f%%", simba.FromMoodToSmiley(u), a)
+			buttonBlock := slack.NewButtonBlockElement("_", "", slackTextBlock(text))
+			actionBlock := slack.NewActionBlock(
+				fmt.Sprintf("total_%s_%d", u, time.Now().Unix()),
+				buttonBlock,
+			)
+			blockSet = append(blockSet, actionBlock)
+		}
 	}
 
+	// The moods of a single person are only shown when groups of one are allowed
+	showPeople := simba.IsAnonymous(1, config.MIN_GROUP_SIZE)
+	if !showPeople && hvai.peopleWithMood() > 0 {
+		hiddenText := simba.T(locale, "home.anonymity.people", config.MIN_GROUP_SIZE)
+		blockSet = append(blockSet, slack.NewContextBlock("", slackMkDownBlock(hiddenText)))
+	}
 	for u, m := range hvai.avgByUser(hvai.mapByUserCount()) {
 		slackAvgByUserSectionTitle := slack.NewHeaderBlock(slackTextBlock(u))
 		blockSet = append(blockSet, slackAvgByUserSectionTitle)
-		elemBlock := []slack.BlockElement{}
-		for k, v := range m {
-			text := fmt.Sprintf("%s %.2f%%", simba.FromMoodToSmiley(k), v)
-			buttonBlock := slack.NewButtonBlockElement(
-				fmt.Sprintf("%s_%d", k, time.Now().Unix()),
-				"",
-				slackTextBlock(text),
-			)
-			elemBlock = append(elemBlock, buttonBlock)
+		if participation, ok := hvai.Participation[u]; ok {
+			participationText := simba.ParticipationText(participation, locale)
+			blockSet = append(blockSet, slack.NewContextBlock("", slackMkDownBlock(participationText)))
+		}
+		if showPeople {
+			elemBlock := []slack.BlockElement{}
+			for k, v := range m {
+				text := fmt.Sprintf("%s %.2f%%", simba.FromMoodToSmiley(k), v)
+				buttonBlock := slack.NewButtonBlockElement(
+					fmt.Sprintf("%s_%d", k, time.Now().Unix()),
+					"",
+					slackTextBlock(text),
+				)
+				elemBlock = append(elemBlock, buttonBlock)
+			}
+			actionBlock := slack.NewActionBlock(
+				fmt.Sprintf("action_block_user_%s_%d", u, time.Now().Unix()+1),
+				elemBlock...)
+			blockSet = append(blockSet, actionBlock)
 		}
-		actionBlock := slack.NewActionBlock(
-			fmt.Sprintf("action_block_user_%s_%d", u, time.Now().Unix()+1),
-			elemBlock...)
-		blockSet = append(blockSet, actionBlock, slack.NewDividerBlock())
+		blockSet = append(blockSet, slack.NewDividerBlock())
+	}
+
+	if simba.Can(role, simba.PermManageHierarchy) {
+		blockSet = append(blockSet, hierarchyBlocks(dbClient, locale)...)
+	}
+	if simba.Can(role, simba.PermManageRoles) {
+		blockSet = append(blockSet, roleBlocks(dbClient, locale)...)
+	}
+	if simba.Can(role, simba.PermManageTokens) {
+		blockSet = append(blockSet, apiTokenBlocks(dbClient, locale)...)
+	}
+	if simba.Can(role, simba.PermManageWebhooks) {
+		blockSet = append(blockSet, webhookBlocks(dbClient, locale)...)
 	}
 
 	return slack.Blocks{
 		BlockSet: blockSet,
 	}
 }
+
+// @desc Render the people currently matching a burnout risk rule, with a button to reach out
+// @returns no block at all when nobody is at risk
+func riskBlocks(
+	dbClient *gorm.DB,
+	config *simba.Config,
+	audience simba.Audience,
+	canReachOut bool,
+	locale string,
+) []slack.Block {
+	if config.RISK == nil {
+		return []slack.Block{}
+	}
+	allRisks, err := simba.FetchBurnoutRisks(dbClient, config.RISK, time.Now())
+	if err != nil {
+		log.Printf("[ERROR] FetchBurnoutRisks failed : %s", err.Error())
+		return []slack.Block{}
+	}
+	risks := []simba.BurnoutRisk{}
+	for _, risk := range allRisks {
+		if audience.Includes(risk.User.SlackUserID) {
+			risks = append(risks, risk)
+		}
+	}
+	if len(risks) == 0 {
+		return []slack.Block{}
+	}
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slackTextBlock(simba.T(locale, "home.risk.header"))),
+		slack.NewContextBlock("", slackMkDownBlock(simba.T(locale, "risk.confidential"))),
+	}
+	for _, risk := range risks {
+		text := fmt.Sprintf(
+			"<@%s>\n%s",
+			risk.User.SlackUserID,
+			simba.RiskReasonsText(risk.Reasons, config.RISK, locale),
+		)
+		var reachOut *slack.Accessory
+		if canReachOut {
+			reachOut = slack.NewAccessory(
+				slack.NewButtonBlockElement(
+					fmt.Sprintf("direct_message_%s", risk.User.SlackUserID),
+					risk.User.SlackUserID,
+					slackTextBlock(simba.T(locale, "risk.reach_out")),
+				),
+			)
+		}
+		blocks = append(blocks, slack.NewSectionBlock(slackMkDownBlock(text), nil, reachOut))
+	}
+	return append(blocks, slack.NewDividerBlock())
+}
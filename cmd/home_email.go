@@ -0,0 +1,100 @@
+package main
+
+import (
+	"github.com/saisona/simba"
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+const (
+	emailOptionDigest = "digest"
+	emailOptionAlerts = "alerts"
+)
+
+// @desc Render the email section of the Home tab, only when an SMTP server is configured
+// @params user is a DB representation of a Simba user (ID is 0 if never registered)
+// @returns Blocks with a checkbox per email, alerts being offered to those who can reach out only
+func emailBlocks(
+	user *simba.User,
+	slackUser *slack.User,
+	role string,
+	config *simba.Config,
+	locale string,
+) []slack.Block {
+	if !config.SMTP.Enabled() {
+		return nil
+	}
+	blocks := []slack.Block{slack.NewDividerBlock()}
+	if slackUser == nil || slackUser.Profile.Email == "" {
+		return append(blocks, slack.NewSectionBlock(slackMkDownBlock(simba.T(locale, "home.email.missing")), nil, nil))
+	}
+
+	digest := slack.NewOptionBlockObject(
+		emailOptionDigest,
+		slackTextBlock(simba.T(locale, "home.email.digest")),
+		slackTextBlock(simba.T(locale, "home.email.digest.hint")),
+	)
+	options := []*slack.OptionBlockObject{digest}
+	initialOptions := []*slack.OptionBlockObject{}
+	if user.EmailDigest {
+		initialOptions = append(initialOptions, digest)
+	}
+	if simba.Can(role, simba.PermReachOut) {
+		alerts := slack.NewOptionBlockObject(
+			emailOptionAlerts,
+			slackTextBlock(simba.T(locale, "home.email.alerts")),
+			slackTextBlock(simba.T(locale, "home.email.alerts.hint")),
+		)
+		options = append(options, alerts)
+		if user.EmailAlerts {
+			initialOptions = append(initialOptions, alerts)
+		}
+	}
+
+	checkboxes := slack.NewCheckboxGroupsBlockElement("email_preferences", options...)
+	checkboxes.InitialOptions = initialOptions
+	return append(
+		blocks,
+		slack.NewSectionBlock(
+			slackMkDownBlock(simba.T(locale, "home.email.header", slackUser.Profile.Email)),
+			nil,
+			nil,
+		),
+		slack.NewActionBlock("email_actions", checkboxes),
+	)
+}
+
+// @desc Save the emails ticked in the email section (email_preferences), then refresh Home
+func handleEmailAction(
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	config *simba.Config,
+	userId string,
+	action *slack.BlockAction,
+	metadata string,
+) error {
+	role, _, _, err := simba.FetchRole(dbClient, slackClient, userId)
+	if err != nil {
+		return err
+	}
+
+	var digest, alerts bool
+	for _, option := range action.SelectedOptions {
+		switch option.Value {
+		case emailOptionDigest:
+			digest = true
+		case emailOptionAlerts:
+			alerts = simba.Can(role, simba.PermReachOut)
+		}
+	}
+	username, err := fetchUsername(slackClient, userId)
+	if err != nil {
+		return err
+	}
+	if _, err := simba.SetEmailPreferences(dbClient, config.CHANNEL_ID, userId, username, digest, alerts); err != nil {
+		return err
+	}
+
+	_, err = slackClient.PublishView(userId, handleAppHomeView(slackClient, dbClient, config, userId, metadata), "")
+	return err
+}
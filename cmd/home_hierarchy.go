@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/saisona/simba"
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+// @desc Render the hierarchy section of the admin Home tab: edit button and reports of every manager
+func hierarchyBlocks(dbClient *gorm.DB, locale string) []slack.Block {
+	editButton := slack.NewButtonBlockElement(
+		"hierarchy_edit",
+		"hierarchy_edit",
+		slackTextBlock(simba.T(locale, "home.hierarchy.edit")),
+	)
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slackTextBlock(simba.T(locale, "home.hierarchy.header"))),
+		slack.NewActionBlock("hierarchy_actions", editButton),
+	}
+
+	managers, reportsByManager, err := simba.FetchHierarchy(dbClient)
+	if err != nil {
+		log.Printf("[ERROR] FetchHierarchy failed : %s", err.Error())
+		return append(blocks, slack.NewDividerBlock())
+	} else if len(managers) == 0 {
+		blocks = append(blocks, slack.NewContextBlock("", slackMkDownBlock(simba.T(locale, "home.hierarchy.none"))))
+		return append(blocks, slack.NewDividerBlock())
+	}
+
+	for _, manager := range managers {
+		reports := []string{}
+		for _, report := range reportsByManager[manager.SlackUserID] {
+			reports = append(reports, fmt.Sprintf("<@%s>", report.SlackUserID))
+		}
+		text := simba.T(locale, "home.hierarchy.manager", manager.SlackUserID, strings.Join(reports, ", "))
+		blocks = append(blocks, slack.NewSectionBlock(slackMkDownBlock(text), nil, nil))
+	}
+	return append(blocks, slack.NewDividerBlock())
+}
+
+// viewAppModalHierarchy asks who reports to whom, metadata is the one of Home
+func viewAppModalHierarchy(locale, homeMetadata string) slack.ModalViewRequest {
+	reportInput := slack.NewInputBlock(
+		"HierarchyReport",
+		slackTextBlock(simba.T(locale, "modal.hierarchy.report")),
+		nil,
+		slack.NewOptionsSelectBlockElement(
+			slack.OptTypeUser,
+			slackTextBlock(simba.T(locale, "modal.hierarchy.report.placeholder")),
+			"hierarchy_report",
+		),
+	)
+	managerInput := slack.NewInputBlock(
+		"HierarchyManager",
+		slackTextBlock(simba.T(locale, "modal.hierarchy.manager")),
+		slackTextBlock(simba.T(locale, "modal.hierarchy.manager.hint")),
+		slack.NewOptionsSelectBlockElement(
+			slack.OptTypeUser,
+			slackTextBlock(simba.T(locale, "modal.hierarchy.manager.placeholder")),
+			"hierarchy_manager",
+		),
+	)
+	managerInput.Optional = true
+
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		Blocks:          slack.Blocks{BlockSet: []slack.Block{reportInput, managerInput}},
+		Title:           slackTextBlock(simba.T(locale, "modal.hierarchy.title")),
+		Close:           slackTextBlock(simba.T(locale, "modal.cancel")),
+		Submit:          slackTextBlock(simba.T(locale, "modal.hierarchy.submit")),
+		CallbackID:      "hierarchy_modal",
+		PrivateMetadata: homeMetadata,
+		ClearOnClose:    true,
+	}
+}
+
+// @desc Open the hierarchy modal (hierarchy_edit), for those allowed to manage the hierarchy
+func handleHierarchyAction(
+	c echo.Context,
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	userId string,
+	action *slack.BlockAction,
+	triggerId, metadata string,
+) error {
+	role, _, slackUser, err := simba.FetchRole(dbClient, slackClient, userId)
+	if err != nil {
+		return err
+	} else if !simba.Can(role, simba.PermManageHierarchy) {
+		return fmt.Errorf("%s is not allowed to edit the hierarchy", userId)
+	} else if action.ActionID != "hierarchy_edit" {
+		return simba.NewErrNoActionFound(action.ActionID, action.Value)
+	}
+
+	locale := simba.NormalizeLocale(slackUser.Locale)
+	viewResponse, err := slackClient.OpenView(triggerId, viewAppModalHierarchy(locale, metadata))
+	if err != nil {
+		c.Logger().Errorf("Failed open hierarchy modal view %s", err.Error())
+		c.Logger().Errorf("MetadataError %v", viewResponse.ResponseMetadata.Messages)
+	}
+	return err
+}
+
+// @desc Save who the selected person reports to, then refresh Home
+// @returns response_action errors when someone is unknown or the hierarchy would loop
+func handleHierarchySubmission(
+	c echo.Context,
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	config *simba.Config,
+	callBackStruct *slack.InteractionCallback,
+) error {
+	userId := callBackStruct.User.ID
+	role, _, slackUser, err := simba.FetchRole(dbClient, slackClient, userId)
+	if err != nil {
+		return err
+	} else if !simba.Can(role, simba.PermManageHierarchy) {
+		return fmt.Errorf("%s is not allowed to edit the hierarchy", userId)
+	}
+	locale := simba.NormalizeLocale(slackUser.Locale)
+
+	values := callBackStruct.View.State.Values
+	reportId := values["HierarchyReport"]["hierarchy_report"].SelectedUser
+	managerId := values["HierarchyManager"]["hierarchy_manager"].SelectedUser
+	errs := map[string]string{}
+	if report, err := simba.FetchAPIUser(dbClient, reportId); err != nil {
+		return err
+	} else if report == nil {
+		errs["HierarchyReport"] = simba.T(locale, "error.hierarchy.unknown")
+	}
+	if managerId != "" {
+		if manager, err := simba.FetchAPIUser(dbClient, managerId); err != nil {
+			return err
+		} else if manager == nil {
+			errs["HierarchyManager"] = simba.T(locale, "error.hierarchy.unknown")
+		}
+	}
+	if len(errs) > 0 {
+		return c.JSON(http.StatusOK, slack.NewErrorsViewSubmissionResponse(errs))
+	}
+
+	if err := simba.SetManager(dbClient, reportId, managerId); errors.Is(err, simba.ErrManagerSelf) {
+		errs["HierarchyManager"] = simba.T(locale, "error.hierarchy.self")
+		return c.JSON(http.StatusOK, slack.NewErrorsViewSubmissionResponse(errs))
+	} else if errors.Is(err, simba.ErrManagerCycle) {
+		errs["HierarchyManager"] = simba.T(locale, "error.hierarchy.cycle")
+		return c.JSON(http.StatusOK, slack.NewErrorsViewSubmissionResponse(errs))
+	} else if err != nil {
+		return err
+	}
+
+	if _, err := slackClient.PublishView(
+		userId,
+		handleAppHomeView(slackClient, dbClient, config, userId, callBackStruct.View.PrivateMetadata),
+		"",
+	); err != nil {
+		c.Logger().Errorf("PublishView after hierarchy change = %s", err.Error())
+	}
+	return c.NoContent(http.StatusOK)
+}
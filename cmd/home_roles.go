@@ -0,0 +1,188 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/saisona/simba"
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+// roleChangesShown is how many role changes the Home tab lists
+const roleChangesShown = 5
+
+func roleName(locale, role string) string {
+	return simba.T(locale, "role."+role)
+}
+
+// @desc Render the roles section of the admin Home tab: assign button, roles assigned and recent changes
+func roleBlocks(dbClient *gorm.DB, locale string) []slack.Block {
+	editButton := slack.NewButtonBlockElement(
+		"roles_edit",
+		"roles_edit",
+		slackTextBlock(simba.T(locale, "home.roles.edit")),
+	)
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slackTextBlock(simba.T(locale, "home.roles.header"))),
+		slack.NewContextBlock("", slackMkDownBlock(simba.T(locale, "home.roles.hint"))),
+		slack.NewActionBlock("roles_actions", editButton),
+	}
+
+	users, err := simba.FetchRoleAssignments(dbClient)
+	if err != nil {
+		log.Printf("[ERROR] FetchRoleAssignments failed : %s", err.Error())
+		return append(blocks, slack.NewDividerBlock())
+	} else if len(users) == 0 {
+		blocks = append(blocks, slack.NewContextBlock("", slackMkDownBlock(simba.T(locale, "home.roles.none"))))
+	}
+	for _, u := range users {
+		text := simba.T(locale, "home.roles.assignment", u.SlackUserID, roleName(locale, u.Role))
+		blocks = append(blocks, slack.NewSectionBlock(slackMkDownBlock(text), nil, nil))
+	}
+
+	changes, err := simba.FetchRoleChanges(dbClient, roleChangesShown)
+	if err != nil {
+		log.Printf("[ERROR] FetchRoleChanges failed : %s", err.Error())
+	} else if len(changes) > 0 {
+		elements := []slack.MixedElement{slackMkDownBlock(simba.T(locale, "home.roles.history"))}
+		for _, change := range changes {
+			elements = append(elements, slackMkDownBlock(simba.T(
+				locale,
+				"home.roles.change",
+				simba.FormatDay(change.CreatedAt, locale),
+				change.ChangedBySlackUserID,
+				change.SlackUserID,
+				roleName(locale, change.Role),
+				roleName(locale, change.PreviousRole),
+			)))
+		}
+		blocks = append(blocks, slack.NewContextBlock("", elements...))
+	}
+	return append(blocks, slack.NewDividerBlock())
+}
+
+// viewAppModalRoles asks who gets which role, metadata is the one of Home
+func viewAppModalRoles(locale, homeMetadata string) slack.ModalViewRequest {
+	userInput := slack.NewInputBlock(
+		"RolesUser",
+		slackTextBlock(simba.T(locale, "modal.roles.user")),
+		nil,
+		slack.NewOptionsSelectBlockElement(
+			slack.OptTypeUser,
+			slackTextBlock(simba.T(locale, "modal.roles.user.placeholder")),
+			"roles_user",
+		),
+	)
+	options := []*slack.OptionBlockObject{}
+	for _, role := range simba.Roles {
+		options = append(options, slack.NewOptionBlockObject(role, slackTextBlock(roleName(locale, role)), nil))
+	}
+	roleInput := slack.NewInputBlock(
+		"RolesRole",
+		slackTextBlock(simba.T(locale, "modal.roles.role")),
+		nil,
+		slack.NewOptionsSelectBlockElement(
+			slack.OptTypeStatic,
+			slackTextBlock(simba.T(locale, "modal.roles.role.placeholder")),
+			"roles_role",
+			options...,
+		),
+	)
+
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		Blocks:          slack.Blocks{BlockSet: []slack.Block{userInput, roleInput}},
+		Title:           slackTextBlock(simba.T(locale, "modal.roles.title")),
+		Close:           slackTextBlock(simba.T(locale, "modal.cancel")),
+		Submit:          slackTextBlock(simba.T(locale, "modal.roles.submit")),
+		CallbackID:      "roles_modal",
+		PrivateMetadata: homeMetadata,
+		ClearOnClose:    true,
+	}
+}
+
+// @desc Open the roles modal (roles_edit), for those allowed to manage roles
+func handleRolesAction(
+	c echo.Context,
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	userId string,
+	action *slack.BlockAction,
+	triggerId, metadata string,
+) error {
+	role, _, slackUser, err := simba.FetchRole(dbClient, slackClient, userId)
+	if err != nil {
+		return err
+	} else if !simba.Can(role, simba.PermManageRoles) {
+		return fmt.Errorf("%s is not allowed to manage roles", userId)
+	} else if action.ActionID != "roles_edit" {
+		return simba.NewErrNoActionFound(action.ActionID, action.Value)
+	}
+
+	locale := simba.NormalizeLocale(slackUser.Locale)
+	viewResponse, err := slackClient.OpenView(triggerId, viewAppModalRoles(locale, metadata))
+	if err != nil {
+		c.Logger().Errorf("Failed open roles modal view %s", err.Error())
+		c.Logger().Errorf("MetadataError %v", viewResponse.ResponseMetadata.Messages)
+	}
+	return err
+}
+
+// @desc Give the selected person the selected role, record who did it, then refresh Home
+// @returns response_action errors when the person is unknown or the role out of reach
+func handleRolesSubmission(
+	c echo.Context,
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	config *simba.Config,
+	callBackStruct *slack.InteractionCallback,
+) error {
+	userId := callBackStruct.User.ID
+	actorRole, _, slackUser, err := simba.FetchRole(dbClient, slackClient, userId)
+	if err != nil {
+		return err
+	} else if !simba.Can(actorRole, simba.PermManageRoles) {
+		return fmt.Errorf("%s is not allowed to manage roles", userId)
+	}
+	locale := simba.NormalizeLocale(slackUser.Locale)
+
+	values := callBackStruct.View.State.Values
+	targetId := values["RolesUser"]["roles_user"].SelectedUser
+	role := values["RolesRole"]["roles_role"].SelectedOption.Value
+
+	currentRole, _, _, err := simba.FetchRole(dbClient, slackClient, targetId)
+	if err != nil {
+		return err
+	} else if !simba.CanAssignRole(actorRole, currentRole, role) {
+		return c.JSON(
+			http.StatusOK,
+			slack.NewErrorsViewSubmissionResponse(
+				map[string]string{"RolesRole": simba.T(locale, "error.roles.forbidden")},
+			),
+		)
+	}
+
+	if _, err := simba.AssignRole(dbClient, targetId, role, currentRole, userId); errors.Is(err, simba.ErrUnknownUser) {
+		return c.JSON(
+			http.StatusOK,
+			slack.NewErrorsViewSubmissionResponse(
+				map[string]string{"RolesUser": simba.T(locale, "error.roles.unknown")},
+			),
+		)
+	} else if err != nil {
+		return err
+	}
+
+	if _, err := slackClient.PublishView(
+		userId,
+		handleAppHomeView(slackClient, dbClient, config, userId, callBackStruct.View.PrivateMetadata),
+		"",
+	); err != nil {
+		c.Logger().Errorf("PublishView after role change = %s", err.Error())
+	}
+	return c.NoContent(http.StatusOK)
+}
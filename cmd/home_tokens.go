@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/saisona/simba"
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+// @desc Render the API tokens section of the admin Home tab: create button and revocable tokens
+func apiTokenBlocks(dbClient *gorm.DB, locale string) []slack.Block {
+	createButton := slack.NewButtonBlockElement(
+		"api_token_create",
+		"api_token_create",
+		slackTextBlock(simba.T(locale, "home.api.create")),
+	)
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slackTextBlock(simba.T(locale, "home.api.header"))),
+		slack.NewActionBlock("api_token_actions", createButton),
+	}
+
+	tokens, err := simba.FetchAPITokens(dbClient)
+	if err != nil {
+		log.Printf("[ERROR] FetchAPITokens failed : %s", err.Error())
+		return append(blocks, slack.NewDividerBlock())
+	} else if len(tokens) == 0 {
+		blocks = append(blocks, slack.NewContextBlock("", slackMkDownBlock(simba.T(locale, "home.api.none"))))
+		return append(blocks, slack.NewDividerBlock())
+	}
+
+	for _, token := range tokens {
+		lastUsed := simba.T(locale, "home.api.never")
+		if token.LastUsedAt != nil {
+			lastUsed = simba.FormatDay(*token.LastUsedAt, locale)
+		}
+		text := simba.T(
+			locale,
+			"home.api.token",
+			token.Name,
+			token.Hint,
+			strings.Join(token.ScopeList(), ", "),
+			token.CreatedBySlackUserID,
+			lastUsed,
+		)
+		revokeButton := slack.NewButtonBlockElement(
+			"api_token_revoke",
+			strconv.FormatUint(uint64(token.ID), 10),
+			slackTextBlock(simba.T(locale, "home.api.revoke")),
+		).WithStyle(slack.StyleDanger).WithConfirm(slack.NewConfirmationBlockObject(
+			slackTextBlock(simba.T(locale, "home.api.revoke.title")),
+			slackMkDownBlock(simba.T(locale, "home.api.revoke.text", token.Name)),
+			slackTextBlock(simba.T(locale, "home.api.revoke")),
+			slackTextBlock(simba.T(locale, "modal.cancel")),
+		))
+		blocks = append(blocks, slack.NewSectionBlock(slackMkDownBlock(text), nil, slack.NewAccessory(revokeButton)))
+	}
+	return append(blocks, slack.NewDividerBlock())
+}
+
+// viewAppModalAPIToken asks for the name and the scopes of a new token, metadata is the one of Home
+func viewAppModalAPIToken(locale, homeMetadata string) slack.ModalViewRequest {
+	nameInput := slack.NewInputBlock(
+		"APITokenName",
+		slackTextBlock(simba.T(locale, "modal.api.name")),
+		slackTextBlock(simba.T(locale, "modal.api.name.hint")),
+		slack.NewPlainTextInputBlockElement(nil, "api_token_name"),
+	)
+
+	scopeOptions := []*slack.OptionBlockObject{}
+	for _, scope := range simba.APIScopes {
+		scopeOptions = append(scopeOptions, slack.NewOptionBlockObject(
+			scope,
+			slackMkDownBlock(fmt.Sprintf("`%s`", scope)),
+			slackTextBlock(simba.T(locale, "modal.api.scope."+scope)),
+		))
+	}
+	scopesInput := slack.NewInputBlock(
+		"APITokenScopes",
+		slackTextBlock(simba.T(locale, "modal.api.scopes")),
+		nil,
+		slack.NewCheckboxGroupsBlockElement("api_token_scopes", scopeOptions...),
+	)
+
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		Blocks:          slack.Blocks{BlockSet: []slack.Block{nameInput, scopesInput}},
+		Title:           slackTextBlock(simba.T(locale, "modal.api.title")),
+		Close:           slackTextBlock(simba.T(locale, "modal.cancel")),
+		Submit:          slackTextBlock(simba.T(locale, "modal.api.submit")),
+		CallbackID:      "api_token_modal",
+		PrivateMetadata: homeMetadata,
+		ClearOnClose:    true,
+	}
+}
+
+// viewAppModalAPITokenCreated shows the new token, the only time it can be read
+func viewAppModalAPITokenCreated(locale, token string, apiToken *simba.APIToken) *slack.ModalViewRequest {
+	return &slack.ModalViewRequest{
+		Type: slack.VTModal,
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			slack.NewSectionBlock(slackMkDownBlock(simba.T(locale, "modal.api.created", apiToken.Name)), nil, nil),
+			slack.NewSectionBlock(slackMkDownBlock(fmt.Sprintf("```%s```", token)), nil, nil),
+		}},
+		Title:        slackTextBlock(simba.T(locale, "modal.api.title")),
+		Close:        slackTextBlock(simba.T(locale, "modal.close")),
+		ClearOnClose: true,
+	}
+}
+
+// @desc Open the token modal (api_token_create) or revoke a token (api_token_revoke), admins only
+func handleAPITokenAction(
+	c echo.Context,
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	config *simba.Config,
+	userId string,
+	action *slack.BlockAction,
+	triggerId, metadata string,
+) error {
+	role, _, slackUser, err := simba.FetchRole(dbClient, slackClient, userId)
+	if err != nil {
+		return err
+	} else if !simba.Can(role, simba.PermManageTokens) {
+		return fmt.Errorf("%s is not allowed to manage API tokens", userId)
+	}
+	locale := simba.NormalizeLocale(slackUser.Locale)
+
+	switch action.ActionID {
+	case "api_token_create":
+		viewResponse, err := slackClient.OpenView(triggerId, viewAppModalAPIToken(locale, metadata))
+		if err != nil {
+			c.Logger().Errorf("Failed open API token modal view %s", err.Error())
+			c.Logger().Errorf("MetadataError %v", viewResponse.ResponseMetadata.Messages)
+		}
+		return err
+	case "api_token_revoke":
+		id, err := strconv.ParseUint(action.Value, 10, 64)
+		if err != nil {
+			return err
+		}
+		if err := simba.RevokeAPIToken(dbClient, uint(id)); err != nil {
+			return err
+		}
+		_, err = slackClient.PublishView(userId, handleAppHomeView(slackClient, dbClient, config, userId, metadata), "")
+		return err
+	default:
+		return simba.NewErrNoActionFound(action.ActionID, action.Value)
+	}
+}
+
+// @desc Create the token of the modal, then show it once in place of the form and refresh Home
+// @returns response_action errors when the name or the scopes are missing
+func handleAPITokenSubmission(
+	c echo.Context,
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	config *simba.Config,
+	callBackStruct *slack.InteractionCallback,
+) error {
+	userId := callBackStruct.User.ID
+	role, _, slackUser, err := simba.FetchRole(dbClient, slackClient, userId)
+	if err != nil {
+		return err
+	} else if !simba.Can(role, simba.PermManageTokens) {
+		return fmt.Errorf("%s is not allowed to manage API tokens", userId)
+	}
+	locale := simba.NormalizeLocale(slackUser.Locale)
+
+	values := callBackStruct.View.State.Values
+	name := strings.TrimSpace(values["APITokenName"]["api_token_name"].Value)
+	scopes := []string{}
+	for _, option := range values["APITokenScopes"]["api_token_scopes"].SelectedOptions {
+		scopes = append(scopes, option.Value)
+	}
+	errs := map[string]string{}
+	if name == "" {
+		errs["APITokenName"] = simba.T(locale, "error.api.name")
+	}
+	if err := simba.ValidateScopes(scopes); err != nil {
+		errs["APITokenScopes"] = simba.T(locale, "error.api.scopes")
+	}
+	if len(errs) > 0 {
+		return c.JSON(http.StatusOK, slack.NewErrorsViewSubmissionResponse(errs))
+	}
+
+	token, apiToken, err := simba.CreateAPIToken(dbClient, name, scopes, userId)
+	if err != nil {
+		return err
+	}
+
+	homeMetadata := callBackStruct.View.PrivateMetadata
+	if _, err := slackClient.PublishView(
+		userId,
+		handleAppHomeView(slackClient, dbClient, config, userId, homeMetadata),
+		"",
+	); err != nil {
+		c.Logger().Errorf("PublishView after API token creation = %s", err.Error())
+	}
+	return c.JSON(
+		http.StatusOK,
+		slack.NewUpdateViewSubmissionResponse(viewAppModalAPITokenCreated(locale, token, apiToken)),
+	)
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/saisona/simba"
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+const trendDateLayout = "2006-01-02"
+
+// trendRange is the period of the trend chart, kept in the private metadata of the Home tab
+type trendRange struct {
+	From time.Time
+	To   time.Time
+}
+
+func defaultTrendRange() trendRange {
+	now := time.Now()
+	return trendRange{From: now.AddDate(0, 0, -7*simba.TrendWindows[0]), To: now}
+}
+
+// parseTrendRange reads trend::<from>::<to> and falls back on the default range
+func parseTrendRange(metadata string) trendRange {
+	parts := strings.Split(metadata, "::")
+	if len(parts) != 3 || parts[0] != "trend" {
+		return defaultTrendRange()
+	}
+	from, fromErr := time.Parse(trendDateLayout, parts[1])
+	to, toErr := time.Parse(trendDateLayout, parts[2])
+	if fromErr != nil || toErr != nil || !from.Before(to) {
+		return defaultTrendRange()
+	}
+	return trendRange{From: from, To: to}
+}
+
+func (tr trendRange) metadata() string {
+	return fmt.Sprintf("trend::%s::%s", tr.From.Format(trendDateLayout), tr.To.Format(trendDateLayout))
+}
+
+var (
+	trendChartsMu sync.Mutex
+	// trendCharts keeps the Slack file id of the charts already uploaded this hour, by range and locale
+	trendCharts     = map[string]string{}
+	trendChartsHour string
+)
+
+// @desc Keep the Slack file id of a chart, forgetting the ones of the previous hours
+// @returns The file id kept for key, the one of an upload finished meanwhile winning
+func storeTrendChart(hour, key, fileId string) string {
+	trendChartsMu.Lock()
+	defer trendChartsMu.Unlock()
+	if hour != trendChartsHour {
+		trendCharts = map[string]string{}
+		trendChartsHour = hour
+	}
+	if uploaded, ok := trendCharts[key]; ok {
+		return uploaded
+	}
+	trendCharts[key] = fileId
+	return fileId
+}
+
+// @desc Render, upload (once an hour per range and audience) and return the Slack file id of the trend chart
+func uploadTrendChart(
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	config *simba.Config,
+	audience simba.Audience,
+	tr trendRange,
+	locale string,
+) (string, error) {
+	hour := time.Now().Format("2006010215")
+	// Managers only see the trend of their reports, so charts are only shared by the same audience
+	audienceKey := "everyone"
+	if !audience.Everyone {
+		audienceKey = strings.Join(audience.SlackUserIDList(), ",")
+	}
+	key := fmt.Sprintf("%s::%s::%s", tr.metadata(), locale, audienceKey)
+	trendChartsMu.Lock()
+	fileId, ok := trendCharts[key]
+	ok = ok && hour == trendChartsHour
+	trendChartsMu.Unlock()
+	if ok {
+		return fileId, nil
+	}
+
+	// The lock is not held while rendering and uploading, so a slow upload blocks no other Home
+	points, err := simba.FetchMoodTrend(
+		dbClient,
+		config.CHANNEL_ID,
+		audience.SlackUserIDList(),
+		config.MIN_GROUP_SIZE,
+		tr.From,
+		tr.To,
+	)
+	if err != nil {
+		return "", err
+	}
+	var chart bytes.Buffer
+	if err := simba.RenderMoodTrend(points, locale, &chart); err != nil {
+		return "", err
+	}
+	file, err := simba.UploadImage(
+		slackClient,
+		&chart,
+		fmt.Sprintf("trend_%s.png", strings.ReplaceAll(tr.metadata(), "::", "_")),
+		simba.T(locale, "trend.title"),
+	)
+	if err != nil {
+		return "", err
+	}
+	return storeTrendChart(hour, key, file.ID), nil
+}
+
+// @desc Render the trend section of the admin Home tab: window select, date range and chart
+func trendBlocks(
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	config *simba.Config,
+	audience simba.Audience,
+	tr trendRange,
+	locale string,
+) []slack.Block {
+	windowOptions := []*slack.OptionBlockObject{}
+	for _, weeks := range simba.TrendWindows {
+		windowOptions = append(windowOptions, slack.NewOptionBlockObject(
+			strconv.Itoa(weeks),
+			slackTextBlock(simba.T(locale, "home.trend.window", weeks)),
+			nil,
+		))
+	}
+	windowSelect := slack.NewOptionsSelectBlockElement(
+		slack.OptTypeStatic,
+		slackTextBlock(simba.T(locale, "home.trend.window.placeholder")),
+		"trend_window",
+		windowOptions...,
+	)
+	fromPicker := slack.NewDatePickerBlockElement("trend_from")
+	fromPicker.InitialDate = tr.From.Format(trendDateLayout)
+	toPicker := slack.NewDatePickerBlockElement("trend_to")
+	toPicker.InitialDate = tr.To.Format(trendDateLayout)
+
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slackTextBlock(simba.T(locale, "home.trend.header"))),
+		slack.NewActionBlock("trend_actions", windowSelect, fromPicker, toPicker),
+	}
+
+	fileId, err := uploadTrendChart(slackClient, dbClient, config, audience, tr, locale)
+	if err != nil {
+		log.Printf("[ERROR] trend chart failed : %s", err.Error())
+		return append(blocks, slack.NewContextBlock(
+			"trend_error",
+			slackMkDownBlock(simba.T(locale, "home.trend.error")),
+		))
+	}
+	chartBlock := slack.NewImageBlock("", simba.T(locale, "trend.title"), "trend_chart", nil)
+	chartBlock.SlackFile = &slack.SlackFileObject{ID: fileId}
+	return append(blocks, chartBlock, slack.NewDividerBlock())
+}
+
+// @desc Change the trend range from the window select or the date pickers, then refresh Home
+func handleTrendAction(
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	config *simba.Config,
+	userId string,
+	action *slack.BlockAction,
+	metadata string,
+) error {
+	tr := parseTrendRange(metadata)
+	switch action.ActionID {
+	case "trend_window":
+		weeks, err := strconv.Atoi(action.SelectedOption.Value)
+		if err != nil {
+			return err
+		}
+		tr.To = time.Now()
+		tr.From = tr.To.AddDate(0, 0, -7*weeks)
+	case "trend_from", "trend_to":
+		date, err := time.Parse(trendDateLayout, action.SelectedDate)
+		if err != nil {
+			return err
+		}
+		if action.ActionID == "trend_from" {
+			tr.From = date
+		} else {
+			tr.To = date
+		}
+		if !tr.From.Before(tr.To) {
+			tr.From, tr.To = tr.To, tr.From
+		}
+	}
+
+	_, err := slackClient.PublishView(
+		userId,
+		handleAppHomeView(slackClient, dbClient, config, userId, tr.metadata()),
+		"",
+	)
+	return err
+}
+
+// homeMetadata returns the private metadata of the Home tab if it has already been published
+func homeMetadata(view *slack.View) string {
+	if view == nil {
+		return ""
+	}
+	return view.PrivateMetadata
+}
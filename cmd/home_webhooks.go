@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/saisona/simba"
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+// webhookDeliveriesShown is how many deliveries of the log the Home tab lists
+const webhookDeliveriesShown = 5
+
+var webhookDeliveryEmojis = map[string]string{
+	simba.WebhookDeliveryPending:   ":hourglass_flowing_sand:",
+	simba.WebhookDeliveryDelivered: ":white_check_mark:",
+	simba.WebhookDeliveryFailed:    ":x:",
+}
+
+// @desc Render the webhooks section of the admin Home tab: add button, removable webhooks and
+// the last deliveries, each of them can be sent again
+func webhookBlocks(dbClient *gorm.DB, locale string) []slack.Block {
+	createButton := slack.NewButtonBlockElement(
+		"webhook_create",
+		"webhook_create",
+		slackTextBlock(simba.T(locale, "home.webhooks.create")),
+	)
+	blocks := []slack.Block{
+		slack.NewHeaderBlock(slackTextBlock(simba.T(locale, "home.webhooks.header"))),
+		slack.NewActionBlock("webhook_actions", createButton),
+	}
+
+	webhooks, err := simba.FetchWebhooks(dbClient)
+	if err != nil {
+		log.Printf("[ERROR] FetchWebhooks failed : %s", err.Error())
+		return append(blocks, slack.NewDividerBlock())
+	} else if len(webhooks) == 0 {
+		blocks = append(blocks, slack.NewContextBlock("", slackMkDownBlock(simba.T(locale, "home.webhooks.none"))))
+	}
+
+	urlsById := map[uint]string{}
+	for _, webhook := range webhooks {
+		urlsById[webhook.ID] = webhook.URL
+		events := strings.Join(webhook.EventList(), ", ")
+		if events == "" {
+			events = simba.T(locale, "home.webhooks.all_events")
+		}
+		text := simba.T(locale, "home.webhooks.webhook", webhook.URL, events, webhook.CreatedBySlackUserID)
+		deleteButton := slack.NewButtonBlockElement(
+			"webhook_delete",
+			strconv.FormatUint(uint64(webhook.ID), 10),
+			slackTextBlock(simba.T(locale, "home.webhooks.delete")),
+		).WithStyle(slack.StyleDanger).WithConfirm(slack.NewConfirmationBlockObject(
+			slackTextBlock(simba.T(locale, "home.webhooks.delete.title")),
+			slackMkDownBlock(simba.T(locale, "home.webhooks.delete.text", webhook.URL)),
+			slackTextBlock(simba.T(locale, "home.webhooks.delete")),
+			slackTextBlock(simba.T(locale, "modal.cancel")),
+		))
+		blocks = append(blocks, slack.NewSectionBlock(slackMkDownBlock(text), nil, slack.NewAccessory(deleteButton)))
+	}
+
+	deliveries, err := simba.FetchWebhookDeliveries(dbClient, webhookDeliveriesShown)
+	if err != nil {
+		log.Printf("[ERROR] FetchWebhookDeliveries failed : %s", err.Error())
+	} else if len(deliveries) > 0 {
+		blocks = append(blocks, slack.NewContextBlock("", slackMkDownBlock(simba.T(locale, "home.webhooks.deliveries"))))
+	}
+	for _, delivery := range deliveries {
+		url, ok := urlsById[delivery.WebhookID]
+		if !ok {
+			url = simba.T(locale, "home.webhooks.removed")
+		}
+		text := simba.T(
+			locale,
+			"home.webhooks.delivery",
+			webhookDeliveryEmojis[delivery.Status],
+			delivery.Event,
+			url,
+			simba.FormatDay(delivery.CreatedAt, locale),
+			delivery.Attempts,
+		)
+		if delivery.Error != "" {
+			text += "\n" + simba.T(locale, "home.webhooks.delivery.error", delivery.Error)
+		}
+		var redeliver *slack.Accessory
+		if ok && delivery.Status != simba.WebhookDeliveryPending {
+			redeliver = slack.NewAccessory(slack.NewButtonBlockElement(
+				"webhook_redeliver",
+				strconv.FormatUint(uint64(delivery.ID), 10),
+				slackTextBlock(simba.T(locale, "home.webhooks.redeliver")),
+			))
+		}
+		blocks = append(blocks, slack.NewSectionBlock(slackMkDownBlock(text), nil, redeliver))
+	}
+	return append(blocks, slack.NewDividerBlock())
+}
+
+// viewAppModalWebhook asks for the URL and the events of a new webhook, metadata is the one of Home
+func viewAppModalWebhook(locale, homeMetadata string) slack.ModalViewRequest {
+	urlInput := slack.NewInputBlock(
+		"WebhookURL",
+		slackTextBlock(simba.T(locale, "modal.webhooks.url")),
+		slackTextBlock(simba.T(locale, "modal.webhooks.url.hint")),
+		slack.NewPlainTextInputBlockElement(nil, "webhook_url"),
+	)
+
+	eventOptions := []*slack.OptionBlockObject{}
+	for _, event := range simba.WebhookEvents {
+		eventOptions = append(eventOptions, slack.NewOptionBlockObject(
+			event,
+			slackMkDownBlock(fmt.Sprintf("`%s`", event)),
+			slackTextBlock(simba.T(locale, "modal.webhooks.event."+event)),
+		))
+	}
+	eventsInput := slack.NewInputBlock(
+		"WebhookEvents",
+		slackTextBlock(simba.T(locale, "modal.webhooks.events")),
+		slackTextBlock(simba.T(locale, "modal.webhooks.events.hint")),
+		slack.NewCheckboxGroupsBlockElement("webhook_events", eventOptions...),
+	)
+	eventsInput.Optional = true
+
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		Blocks:          slack.Blocks{BlockSet: []slack.Block{urlInput, eventsInput}},
+		Title:           slackTextBlock(simba.T(locale, "modal.webhooks.title")),
+		Close:           slackTextBlock(simba.T(locale, "modal.cancel")),
+		Submit:          slackTextBlock(simba.T(locale, "modal.webhooks.submit")),
+		CallbackID:      "webhook_modal",
+		PrivateMetadata: homeMetadata,
+		ClearOnClose:    true,
+	}
+}
+
+// viewAppModalWebhookCreated shows the secret of the new webhook, the only time it can be read
+func viewAppModalWebhookCreated(locale string, webhook *simba.Webhook) *slack.ModalViewRequest {
+	return &slack.ModalViewRequest{
+		Type: slack.VTModal,
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			slack.NewSectionBlock(slackMkDownBlock(simba.T(locale, "modal.webhooks.created", webhook.URL)), nil, nil),
+			slack.NewSectionBlock(slackMkDownBlock(fmt.Sprintf("```%s```", webhook.Secret)), nil, nil),
+		}},
+		Title:        slackTextBlock(simba.T(locale, "modal.webhooks.title")),
+		Close:        slackTextBlock(simba.T(locale, "modal.close")),
+		ClearOnClose: true,
+	}
+}
+
+// @desc Open the webhook modal (webhook_create), remove a webhook (webhook_delete) or send a
+// delivery again (webhook_redeliver), for those allowed to manage webhooks
+func handleWebhookAction(
+	c echo.Context,
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	config *simba.Config,
+	userId string,
+	action *slack.BlockAction,
+	triggerId, metadata string,
+) error {
+	role, _, slackUser, err := simba.FetchRole(dbClient, slackClient, userId)
+	if err != nil {
+		return err
+	} else if !simba.Can(role, simba.PermManageWebhooks) {
+		return fmt.Errorf("%s is not allowed to manage webhooks", userId)
+	}
+	locale := simba.NormalizeLocale(slackUser.Locale)
+
+	switch action.ActionID {
+	case "webhook_create":
+		viewResponse, err := slackClient.OpenView(triggerId, viewAppModalWebhook(locale, metadata))
+		if err != nil {
+			c.Logger().Errorf("Failed open webhook modal view %s", err.Error())
+			c.Logger().Errorf("MetadataError %v", viewResponse.ResponseMetadata.Messages)
+		}
+		return err
+	case "webhook_delete", "webhook_redeliver":
+		id, err := strconv.ParseUint(action.Value, 10, 64)
+		if err != nil {
+			return err
+		}
+		if action.ActionID == "webhook_delete" {
+			err = simba.DeleteWebhook(dbClient, uint(id))
+		} else {
+			_, err = simba.RedeliverWebhook(dbClient, uint(id), time.Now())
+		}
+		if err != nil {
+			return err
+		}
+		_, err = slackClient.PublishView(userId, handleAppHomeView(slackClient, dbClient, config, userId, metadata), "")
+		return err
+	default:
+		return simba.NewErrNoActionFound(action.ActionID, action.Value)
+	}
+}
+
+// @desc Register the webhook of the modal, then show its secret once in place of the form and refresh Home
+// @returns response_action errors when the URL is not an http or https one
+func handleWebhookSubmission(
+	c echo.Context,
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	config *simba.Config,
+	callBackStruct *slack.InteractionCallback,
+) error {
+	userId := callBackStruct.User.ID
+	role, _, slackUser, err := simba.FetchRole(dbClient, slackClient, userId)
+	if err != nil {
+		return err
+	} else if !simba.Can(role, simba.PermManageWebhooks) {
+		return fmt.Errorf("%s is not allowed to manage webhooks", userId)
+	}
+	locale := simba.NormalizeLocale(slackUser.Locale)
+
+	values := callBackStruct.View.State.Values
+	url := strings.TrimSpace(values["WebhookURL"]["webhook_url"].Value)
+	events := []string{}
+	for _, option := range values["WebhookEvents"]["webhook_events"].SelectedOptions {
+		events = append(events, option.Value)
+	}
+	if err := simba.ValidateWebhookURL(url); err != nil {
+		return c.JSON(
+			http.StatusOK,
+			slack.NewErrorsViewSubmissionResponse(
+				map[string]string{"WebhookURL": simba.T(locale, "error.webhooks.url")},
+			),
+		)
+	}
+
+	webhook, err := simba.CreateWebhook(dbClient, url, events, userId)
+	if err != nil {
+		return err
+	}
+
+	homeMetadata := callBackStruct.View.PrivateMetadata
+	if _, err := slackClient.PublishView(
+		userId,
+		handleAppHomeView(slackClient, dbClient, config, userId, homeMetadata),
+		"",
+	); err != nil {
+		c.Logger().Errorf("PublishView after webhook creation = %s", err.Error())
+	}
+	return c.JSON(
+		http.StatusOK,
+		slack.NewUpdateViewSubmissionResponse(viewAppModalWebhookCreated(locale, webhook)),
+	)
+}
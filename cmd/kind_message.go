@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/saisona/simba"
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+// moodFromPersonnalActionId extracts the mood from personnal_<mood>_<timestamp>
+func moodFromPersonnalActionId(actionId string) string {
+	mood := strings.TrimPrefix(actionId, "personnal_")
+	if idx := strings.LastIndex(mood, "_"); idx > 0 {
+		mood = mood[:idx]
+	}
+	return mood
+}
+
+// @desc Open a modal listing the days behind a personal mood percentage of the Home tab
+func handleKindMessageButton(
+	c echo.Context,
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	userId, actionId, triggerId string,
+) error {
+	user, slackUser, err := simba.FechCurrent(dbClient, slackClient, userId)
+	if err != nil {
+		return err
+	}
+	hvi, err := NewHomeViewInfo(dbClient, user.SlackUserID, fmt.Sprint(user.ID))
+	if err != nil {
+		return err
+	}
+
+	mood := moodFromPersonnalActionId(actionId)
+	viewResponse, err := slackClient.OpenView(
+		triggerId,
+		viewAppModalKindMessage(mood, simba.NormalizeLocale(slackUser.Locale), hvi.WeeklyMoods),
+	)
+	if err != nil {
+		c.Logger().Errorf("Failed open kind message modal view %s", err.Error())
+		c.Logger().Errorf("MetadataError %v", viewResponse.ResponseMetadata.Messages)
+		return err
+	}
+	return nil
+}
+
+// @desc Ask the selected peer or manager for a chat on behalf of the user
+func handleKindMessageSubmission(
+	c echo.Context,
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	callBackStruct *slack.InteractionCallback,
+) error {
+	userId := callBackStruct.User.ID
+	locale := simba.FetchUserLocale(slackClient, userId)
+	values := callBackStruct.View.State.Values
+	peerId := values["ChatPeer"]["chat_peer"].SelectedUser
+	if peerId == "" {
+		return nil
+	} else if peerId == userId {
+		return c.JSON(
+			http.StatusOK,
+			slack.NewErrorsViewSubmissionResponse(
+				map[string]string{"ChatPeer": simba.T(locale, "error.chat.self")},
+			),
+		)
+	}
+
+	if isPaused, err := simba.IsSlackUserPaused(dbClient, slackClient, peerId, time.Now()); err != nil {
+		return err
+	} else if isPaused {
+		return c.JSON(
+			http.StatusOK,
+			slack.NewErrorsViewSubmissionResponse(
+				map[string]string{"ChatPeer": simba.T(locale, "error.user.paused", peerId)},
+			),
+		)
+	}
+
+	request := simba.T(simba.FetchUserLocale(slackClient, peerId), "kind.request", userId)
+	if message := strings.TrimSpace(values["ChatMessage"]["chat_message"].Value); message != "" {
+		request = fmt.Sprintf("%s\n> %s", request, message)
+	}
+	if _, err := simba.SendSlackMessageToUser(slackClient, peerId, request); err != nil {
+		simba.SendErrorMessageToUser(slackClient, userId, err)
+		return err
+	}
+
+	_, err := simba.SendSlackMessageToUser(
+		slackClient,
+		userId,
+		simba.T(locale, "kind.requested", peerId),
+	)
+	return err
+}
@@ -18,12 +18,18 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/saisona/simba"
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		os.Exit(runExportCommand(os.Args[2:]))
+	}
+
 	e := echo.New()
 	e.Use(middleware.Logger())
 	e.Use(middleware.RecoverWithConfig(middleware.RecoverConfig{LogLevel: 2}))
+	e.Use(metricsMiddleware)
 
 	var threadTS string
 
@@ -44,14 +50,22 @@ func main() {
 		return c.NoContent(http.StatusNoContent)
 	})
 
+	e.GET("/metrics", handleRouteMetrics())
+
 	e.POST("/events", func(c echo.Context) error {
-		return handleRouteEvents(c, slackClient, dbClient, config, slackSigningSecret)
+		return handleRouteEvents(c, slackClient, dbClient, config, slackSigningSecret, threadTS)
 	})
 
 	e.POST("/interactive", func(c echo.Context) error {
-		return handleRouteInteractive(c, slackClient, config, dbClient, threadTS)
+		return handleRouteInteractive(c, slackClient, config, dbClient, slackSigningSecret, threadTS)
 	})
 
+	e.GET("/export", func(c echo.Context) error {
+		return handleRouteExport(c, dbClient, config)
+	}, requireScope(dbClient, simba.ScopeReadMoods))
+	registerAPIRoutes(e, dbClient, config)
+	registerDashboardRoutes(e, dbClient, slackClient, config)
+
 	defer close(config.SLACK_MESSAGE_CHANNEL)
 	port := fmt.Sprintf(":%s", config.APP_PORT)
 	go func() {
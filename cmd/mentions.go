@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/saisona/simba"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"gorm.io/gorm"
+)
+
+// @desc Parse an @Simba mention as a command and answer in its thread
+// @params threadTS is the timestamp of the current daily mood message
+// @returns error if the command or the reply failed
+func handleAppMention(
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	config *simba.Config,
+	ev *slackevents.AppMentionEvent,
+	threadTS string,
+) error {
+	if ev.BotID != "" {
+		return nil
+	}
+
+	replyTS := ev.ThreadTimeStamp
+	if replyTS == "" {
+		replyTS = ev.TimeStamp
+	}
+
+	var reply string
+	var err error
+	locale := simba.FetchUserLocale(slackClient, ev.User)
+	command := simba.ParseMentionCommand(ev.Text)
+	switch command.Kind {
+	case simba.MentionCommandStats:
+		reply, err = mentionStats(dbClient, config, threadTS, locale)
+	case simba.MentionCommandMissing:
+		reply, err = mentionMissing(slackClient, dbClient, config, threadTS, locale)
+	case simba.MentionCommandFeeling:
+		reply, err = mentionFeeling(
+			slackClient,
+			dbClient,
+			config,
+			ev.User,
+			command.Feeling,
+			threadTS,
+			locale,
+		)
+	case simba.MentionCommandQuotes:
+		reply, err = mentionQuotes(slackClient, dbClient, ev.User, ev.Channel, command, locale)
+	case simba.MentionCommandHoliday:
+		reply, err = mentionHolidays(slackClient, dbClient, ev.User, ev.Channel, command, locale)
+	case simba.MentionCommandHelp:
+		reply = simba.MentionHelpText(locale)
+	default:
+		reply = simba.T(locale, "mention.unknown", command.Raw, simba.MentionHelpText(locale))
+	}
+
+	if err != nil {
+		log.Printf("[ERROR] mention command %s failed : %s", command.Kind, err.Error())
+		reply = simba.T(locale, "error.mention.failed", err.Error())
+	}
+
+	if _, sendErr := simba.SendSlackThreadReply(slackClient, ev.Channel, reply, replyTS); sendErr != nil {
+		return sendErr
+	}
+	return err
+}
+
+func mentionStats(dbClient *gorm.DB, config *simba.Config, threadTS, locale string) (string, error) {
+	if threadTS == "" {
+		return simba.T(locale, "error.checkin.not_asked"), nil
+	}
+	users, err := simba.FetchAllDailyMoodsByThreadTS(dbClient, threadTS)
+	if err != nil {
+		return "", err
+	}
+	return simba.DailySummaryText(users, config.MIN_GROUP_SIZE, locale), nil
+}
+
+func mentionMissing(
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	config *simba.Config,
+	threadTS, locale string,
+) (string, error) {
+	if threadTS == "" {
+		return simba.T(locale, "error.checkin.not_asked"), nil
+	}
+	_, members, err := simba.FetchUsersFromChannel(slackClient, config.CHANNEL_ID)
+	if err != nil {
+		return "", err
+	}
+	users, err := simba.FetchAllDailyMoodsByThreadTS(dbClient, threadTS)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	paused, err := simba.FetchPausedSlackUserIds(dbClient, now)
+	if err != nil {
+		return "", err
+	}
+
+	answered := map[string]bool{}
+	for _, u := range users {
+		if len(u.Moods) > 0 {
+			answered[u.SlackUserID] = true
+		}
+	}
+
+	missing := []string{}
+	for _, member := range members {
+		if member.IsBot || member.Deleted || answered[member.ID] {
+			continue
+		} else if paused[member.ID] || simba.IsOutOfOfficeStatus(member.Profile, now) {
+			// People away are not waited for
+			continue
+		}
+		missing = append(missing, fmt.Sprintf("<@%s>", member.ID))
+	}
+	if len(missing) == 0 {
+		return simba.T(locale, "mention.missing.none"), nil
+	}
+	sort.Strings(missing)
+	return simba.T(locale, "mention.missing.list", strings.Join(missing, ", ")), nil
+}
+
+func mentionFeeling(
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	config *simba.Config,
+	userId, feeling, threadTS, locale string,
+) (string, error) {
+	if threadTS == "" {
+		return simba.T(locale, "error.checkin.not_asked"), nil
+	}
+	username, err := fetchUsername(slackClient, userId)
+	if err != nil {
+		return "", err
+	}
+
+	mood := simba.FromFeelingToMood(feeling)
+	_, err = simba.SaveDailyMood(
+		dbClient,
+		slackClient,
+		config.CHANNEL_ID,
+		userId,
+		username,
+		mood,
+		feeling,
+		"",
+		threadTS,
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if err := refreshDailyMoodMessage(slackClient, dbClient, config, threadTS); err != nil {
+		return "", err
+	}
+
+	return simba.T(
+		locale,
+		"mention.feeling.done",
+		simba.T(locale, "feeling."+feeling),
+		simba.FromFeelingToSmiley(feeling),
+		simba.FromMoodToSmiley(mood),
+	), nil
+}
+
+func mentionQuotes(
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	userId, channelId string,
+	command *simba.MentionCommand,
+	locale string,
+) (string, error) {
+	role, _, _, err := simba.FetchRole(dbClient, slackClient, userId)
+	if err != nil {
+		return "", err
+	} else if !simba.Can(role, simba.PermManageSettings) {
+		return simba.T(locale, "error.not_allowed"), nil
+	}
+
+	setting, err := simba.SetChannelQuoteProvider(
+		dbClient,
+		channelId,
+		command.QuoteProvider,
+		command.QuoteSource,
+	)
+	if err != nil {
+		return "", err
+	}
+	provider := setting.QuoteProvider
+	if setting.QuoteSource != "" {
+		provider = fmt.Sprintf("%s (%s)", provider, setting.QuoteSource)
+	}
+	return simba.T(locale, "mention.quotes.done", provider), nil
+}
+
+// upcomingHolidaysDays is how far ahead the holidays command looks when listing holidays
+const upcomingHolidaysDays = 60
+
+func mentionHolidays(
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	userId, channelId string,
+	command *simba.MentionCommand,
+	locale string,
+) (string, error) {
+	if command.CalendarURL == "" {
+		now := time.Now()
+		holidays, err := simba.FetchHolidays(dbClient, channelId, now, now.AddDate(0, 0, upcomingHolidaysDays))
+		if err != nil {
+			return "", err
+		} else if len(holidays) == 0 {
+			return simba.T(locale, "mention.holidays.none", upcomingHolidaysDays), nil
+		}
+		lines := []string{simba.T(locale, "mention.holidays.title")}
+		for _, h := range holidays {
+			lines = append(lines, fmt.Sprintf("• %s %s", simba.FormatDay(h.Date, locale), h.Name))
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+
+	role, _, _, err := simba.FetchRole(dbClient, slackClient, userId)
+	if err != nil {
+		return "", err
+	} else if !simba.Can(role, simba.PermManageSettings) {
+		return simba.T(locale, "error.not_allowed"), nil
+	}
+
+	holidays, err := simba.FetchHolidayCalendar(command.CalendarURL)
+	if err != nil {
+		return "", err
+	}
+	imported, err := simba.ImportHolidays(dbClient, channelId, holidays)
+	if err != nil {
+		return "", err
+	}
+	return simba.T(locale, "mention.holidays.imported", imported), nil
+}
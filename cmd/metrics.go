@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/saisona/simba"
+)
+
+// @desc Count and time every request by route, and the requests being served
+func metricsMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		start := time.Now()
+		simba.SessionStarted(simba.SessionKindHTTP)
+		defer simba.SessionEnded(simba.SessionKindHTTP)
+
+		err := next(c)
+		status := c.Response().Status
+		var httpError *echo.HTTPError
+		if errors.As(err, &httpError) {
+			status = httpError.Code
+		} else if err != nil && !c.Response().Committed {
+			// echo answers 500 to the errors it did not get a code with
+			status = http.StatusInternalServerError
+		}
+		route := c.Path()
+		if route == "" {
+			route = "unmatched"
+		}
+		simba.ObserveHTTPRequest(c.Request().Method, route, status, start)
+		return err
+	}
+}
+
+// @desc Serve the metrics of simba.MetricsRegistry in the Prometheus format
+func handleRouteMetrics() echo.HandlerFunc {
+	return echo.WrapHandler(promhttp.HandlerFor(simba.MetricsRegistry, promhttp.HandlerOpts{}))
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/saisona/simba"
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+// @desc Check managerId can reach out to targetUserId: allowed to send IM and able to see the moods of the target
+// @returns The Slack user of managerId
+func checkOutreachAllowed(
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	managerId, targetUserId string,
+) (*slack.User, error) {
+	role, _, slackManager, err := simba.FetchRole(dbClient, slackClient, managerId)
+	if err != nil {
+		return nil, err
+	} else if !simba.Can(role, simba.PermReachOut) {
+		return nil, fmt.Errorf("%s is not allowed to send IM", managerId)
+	}
+	audience, err := simba.FetchAudience(dbClient, managerId, simba.Can(role, simba.PermViewEveryone))
+	if err != nil {
+		return nil, err
+	} else if !audience.Includes(targetUserId) {
+		return nil, fmt.Errorf("%s is not allowed to reach out to %s", managerId, targetUserId)
+	}
+	return slackManager, nil
+}
+
+// @desc Open the outreach modal prefilled with a template matching the recent moods of the target
+// @params targetUserId is the Slack user id given as value of the direct_message_ button
+func handleOutreachButton(
+	c echo.Context,
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	managerId, targetUserId, triggerId string,
+) error {
+	slackManager, err := checkOutreachAllowed(slackClient, dbClient, managerId, targetUserId)
+	if err != nil {
+		return err
+	}
+
+	targetName, err := fetchUsername(slackClient, targetUserId)
+	if err != nil {
+		return err
+	}
+	moods, err := simba.FetchLastMoodsBySlackUserId(dbClient, targetUserId, 7)
+	if err != nil {
+		return err
+	}
+
+	// The template is written in the language of the person receiving it
+	targetLocale := simba.FetchUserLocale(slackClient, targetUserId)
+	template := simba.PickOutreachTemplate(moods, targetName, targetLocale)
+	viewResponse, err := slackClient.OpenView(
+		triggerId,
+		viewAppModalOutreach(
+			targetUserId,
+			targetName,
+			simba.NormalizeLocale(slackManager.Locale),
+			template,
+		),
+	)
+	if err != nil {
+		c.Logger().Errorf("Failed open outreach modal view %s", err.Error())
+		c.Logger().Errorf("MetadataError %v", viewResponse.ResponseMetadata.Messages)
+		return err
+	}
+	return nil
+}
+
+// @desc Deliver the message written in the outreach modal as DM and record that it happened
+func handleOutreachSubmission(
+	c echo.Context,
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	callBackStruct *slack.InteractionCallback,
+) error {
+	metadata := strings.Split(callBackStruct.View.PrivateMetadata, "::")
+	if len(metadata) != 3 {
+		return fmt.Errorf("wrong outreach metadata %s", callBackStruct.View.PrivateMetadata)
+	}
+	targetUserId, templateName := metadata[1], metadata[2]
+	managerId := callBackStruct.User.ID
+	if _, err := checkOutreachAllowed(slackClient, dbClient, managerId, targetUserId); err != nil {
+		return err
+	}
+	locale := simba.FetchUserLocale(slackClient, managerId)
+
+	message := callBackStruct.View.State.Values["OutreachMessage"]["outreach_message"].Value
+	if strings.TrimSpace(message) == "" {
+		return c.JSON(
+			http.StatusOK,
+			slack.NewErrorsViewSubmissionResponse(
+				map[string]string{"OutreachMessage": simba.T(locale, "error.message.empty")},
+			),
+		)
+	}
+
+	if isPaused, err := simba.IsSlackUserPaused(dbClient, slackClient, targetUserId, time.Now()); err != nil {
+		return err
+	} else if isPaused {
+		return c.JSON(
+			http.StatusOK,
+			slack.NewErrorsViewSubmissionResponse(
+				map[string]string{"OutreachMessage": simba.T(locale, "error.user.paused", targetUserId)},
+			),
+		)
+	}
+
+	if _, err := simba.SendSlackMessageToUser(slackClient, targetUserId, message); err != nil {
+		simba.SendErrorMessageToUser(slackClient, managerId, err)
+		return err
+	}
+	if _, err := simba.RecordOutreach(dbClient, managerId, targetUserId, templateName); err != nil {
+		c.Logger().Errorf("RecordOutreach failed = %s", err.Error())
+	}
+
+	_, err := simba.SendSlackMessageToUser(
+		slackClient,
+		managerId,
+		simba.T(locale, "outreach.sent", targetUserId),
+	)
+	return err
+}
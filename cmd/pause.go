@@ -0,0 +1,39 @@
+package main
+
+import (
+	"time"
+
+	"github.com/saisona/simba"
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+// @desc Pause Simba until the picked date (pause_until) or resume it (pause_resume), then refresh Home
+func handlePauseAction(
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	config *simba.Config,
+	userId string,
+	action *slack.BlockAction,
+	metadata string,
+) error {
+	var until *time.Time
+	if action.ActionID == "pause_until" {
+		pausedUntil, err := time.Parse("2006-01-02", action.SelectedDate)
+		if err != nil {
+			return err
+		}
+		until = &pausedUntil
+	}
+
+	username, err := fetchUsername(slackClient, userId)
+	if err != nil {
+		return err
+	}
+	if _, err := simba.SetUserPause(dbClient, config.CHANNEL_ID, userId, username, until); err != nil {
+		return err
+	}
+
+	_, err = slackClient.PublishView(userId, handleAppHomeView(slackClient, dbClient, config, userId, metadata), "")
+	return err
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"github.com/saisona/simba"
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"gorm.io/gorm"
+)
+
+// isDailyMoodItem tells whether the reacted item is the current daily mood message
+func isDailyMoodItem(config *simba.Config, item slackevents.Item, threadTS string) bool {
+	return threadTS != "" && item.Type == "message" && item.Channel == config.CHANNEL_ID &&
+		item.Timestamp == threadTS
+}
+
+// @desc Record the mood matching an emoji reaction on the daily mood message
+func handleReactionAdded(
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	config *simba.Config,
+	ev *slackevents.ReactionAddedEvent,
+	threadTS string,
+) error {
+	mood, feeling := simba.FromReactionToMood(ev.Reaction)
+	if mood == "" || !isDailyMoodItem(config, ev.Item, threadTS) {
+		return nil
+	}
+
+	username, err := fetchUsername(slackClient, ev.User)
+	if err != nil {
+		return err
+	}
+
+	if feeling != "" {
+		_, err = simba.SaveDailyMood(
+			dbClient,
+			slackClient,
+			config.CHANNEL_ID,
+			ev.User,
+			username,
+			mood,
+			feeling,
+			"",
+			threadTS,
+		)
+	} else {
+		_, err = simba.HandleAddDailyMood(
+			dbClient,
+			slackClient,
+			config.CHANNEL_ID,
+			ev.User,
+			username,
+			mood,
+			threadTS,
+		)
+		if err == nil {
+			simba.RecordedMood(mood)
+		}
+	}
+	if err != nil {
+		simba.SendErrorMessageToUser(slackClient, ev.User, err)
+		return err
+	}
+
+	return refreshDailyMoodMessage(slackClient, dbClient, config, threadTS)
+}
+
+// @desc Retract the mood of a user when the matching emoji reaction is removed
+func handleReactionRemoved(
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	config *simba.Config,
+	ev *slackevents.ReactionRemovedEvent,
+	threadTS string,
+) error {
+	mood, _ := simba.FromReactionToMood(ev.Reaction)
+	if mood == "" || !isDailyMoodItem(config, ev.Item, threadTS) {
+		return nil
+	}
+
+	if isRetracted, err := simba.RetractDailyMood(dbClient, ev.User, mood, threadTS); err != nil {
+		return err
+	} else if !isRetracted {
+		return nil
+	}
+
+	return refreshDailyMoodMessage(slackClient, dbClient, config, threadTS)
+}
+
+// @desc Record that the user is not working today and refresh the daily mood message
+func handleDayOffButton(
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	config *simba.Config,
+	userId, threadTS string,
+) error {
+	username, err := fetchUsername(slackClient, userId)
+	if err != nil {
+		return err
+	}
+	if _, err := simba.SaveDayOff(dbClient, slackClient, config.CHANNEL_ID, userId, username, threadTS); err != nil {
+		return err
+	}
+	return refreshDailyMoodMessage(slackClient, dbClient, config, threadTS)
+}
+
+func refreshDailyMoodMessage(
+	slackClient *slack.Client,
+	dbClient *gorm.DB,
+	config *simba.Config,
+	threadTS string,
+) error {
+	newThreadTS, err := simba.UpdateMessage(slackClient, config, dbClient, threadTS)
+	if err != nil {
+		return err
+	}
+	config.SLACK_MESSAGE_CHANNEL <- newThreadTS
+	return nil
+}
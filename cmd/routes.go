@@ -6,7 +6,9 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/saisona/simba"
@@ -18,7 +20,8 @@ import (
 func secretVerifier(c echo.Context, body []byte, slackSigningSecret string) error {
 	sv, err := slack.NewSecretsVerifier(c.Request().Header, slackSigningSecret)
 	if err != nil {
-		c.NoContent(http.StatusBadRequest)
+		// Missing or stale signature headers, the request is not from Slack
+		c.NoContent(http.StatusUnauthorized)
 		c.Logger().Errorf("#slack.NewSecretsVerifier : %s", err.Error())
 		return err
 	}
@@ -40,6 +43,7 @@ func handleRouteEvents(
 	dbClient *gorm.DB,
 	config *simba.Config,
 	slackSigningSecret string,
+	threadTS string,
 ) error {
 	body, err := io.ReadAll(c.Request().Body)
 	if err != nil {
@@ -73,7 +77,7 @@ func handleRouteEvents(
 		innerEvent := eventsAPIEvent.InnerEvent
 		switch ev := innerEvent.Data.(type) {
 		case *slackevents.AppHomeOpenedEvent:
-			viewResponse, err := slackClient.PublishView(ev.User, handleAppHomeView(slackClient, dbClient, config, ev.User), "")
+			viewResponse, err := slackClient.PublishView(ev.User, handleAppHomeView(slackClient, dbClient, config, ev.User, homeMetadata(ev.View)), "")
 			if err != nil {
 				c.Logger().Errorf("PublishView AppHomeOpenedEvent = %s", err.Error())
 				log.Printf("[ERROR] response => %+v", viewResponse.ResponseMetadata.Messages)
@@ -81,7 +85,17 @@ func handleRouteEvents(
 				return err
 			}
 		case *slackevents.AppMentionEvent:
-			slackClient.PostMessage(ev.Channel, slack.MsgOptionText("Meow :cat:", false))
+			if err := handleAppMention(slackClient, dbClient, config, ev, threadTS); err != nil {
+				c.Logger().Errorf("handleAppMention = %s", err.Error())
+			}
+		case *slackevents.ReactionAddedEvent:
+			if err := handleReactionAdded(slackClient, dbClient, config, ev, threadTS); err != nil {
+				c.Logger().Errorf("handleReactionAdded = %s", err.Error())
+			}
+		case *slackevents.ReactionRemovedEvent:
+			if err := handleReactionRemoved(slackClient, dbClient, config, ev, threadTS); err != nil {
+				c.Logger().Errorf("handleReactionRemoved = %s", err.Error())
+			}
 		}
 	}
 
@@ -93,30 +107,38 @@ func handleRouteInteractive(
 	slackClient *slack.Client,
 	config *simba.Config,
 	dbClient *gorm.DB,
+	slackSigningSecret string,
 	threadTS string,
-) error {
+) (err error) {
+	// The payload is only trusted once signed by Slack, every permission relying on its user
+	body, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		c.NoContent(http.StatusBadRequest)
+		return err
+	} else if err := secretVerifier(c, body, slackSigningSecret); err != nil {
+		return err
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		c.NoContent(http.StatusBadRequest)
+		return err
+	}
+
 	callBackStruct := new(slack.InteractionCallback)
-	err := json.Unmarshal([]byte(c.Request().FormValue("payload")), &callBackStruct)
+	err = json.Unmarshal([]byte(form.Get("payload")), &callBackStruct)
 
 	if err != nil {
 		c.Logger().Errorf("Error from FormValue.payload in callbackStruct = %s", err.Error())
 		return err
-	} else if modalValue := callBackStruct.View.State; modalValue != nil && len(modalValue.Values) > 0 {
-		if modalValue.Values["MoodContext"]["mood_ctxt"].Value != "" {
-			contextString := modalValue.Values["MoodContext"]["mood_ctxt"].Value
-			privateMetadata := callBackStruct.View.PrivateMetadata
-			moodIdSplit := strings.Split(privateMetadata, "::")
-			_, err := simba.UpdateMoodById(dbClient, moodIdSplit[1], nil, &contextString)
-			if err != nil {
-				return err
-			}
-			threadTS, err := simba.UpdateMessage(slackClient, config, dbClient, threadTS)
-			if err != nil {
-				return err
-			}
-			config.SLACK_MESSAGE_CHANNEL <- threadTS
-			return nil
-		}
+	}
+	start := time.Now()
+	defer func() { simba.ObserveInteraction(string(callBackStruct.Type), start, err) }()
+
+	if callBackStruct.Type == slack.InteractionTypeViewSubmission {
+		return handleViewSubmission(c, slackClient, config, dbClient, callBackStruct)
+	} else if callBackStruct.Type == slack.InteractionTypeViewClosed {
+		// Nothing is saved before submission so closing a modal has nothing to rollback
+		return c.NoContent(http.StatusOK)
 	}
 
 	if len(callBackStruct.ActionCallback.BlockActions) > 0 {
@@ -124,69 +146,65 @@ func handleRouteInteractive(
 		user := callBackStruct.User
 		channelId := callBackStruct.Channel.ID
 		userId := user.ID
-		profile, err := slackClient.GetUserProfile(&slack.GetUserProfileParameters{UserID: userId})
-
-		var username string
-		if err != nil {
-			username = "John Snow"
-			c.Logger().Error("[ERROR] #getUserProfile => %s", err.Error())
-			simba.SendErrorMessageToUser(slackClient, userId, err)
-		} else if profile.DisplayName != "" {
-			username = profile.DisplayName
-		} else if profile.RealName != "" {
-			username = profile.RealName
-		}
 
 		for _, action := range blockActions {
 			log.Println("ActionBlock", action.ActionID, action.Value)
 			switch {
-			case strings.Contains(action.ActionID, "mood_feeling_select"):
-				c.Logger().
-					Printf("Clicked on button for mood_feeling_select with value = %s", action.Value)
-
-				if simbaUser, _, err := simba.FechCurrent(dbClient, slackClient, userId); err != nil {
-					simba.SendErrorMessageToUser(slackClient, userId, err)
-					return err
-				} else if dailyMood, err := simba.FetchMoodFromThreadTS(dbClient, threadTS, simbaUser.ID); err != nil {
+			case strings.HasPrefix(action.ActionID, "day_off_"):
+				dayOffThreadTS := callBackStruct.Container.MessageTs
+				if dayOffThreadTS == "" {
+					dayOffThreadTS = threadTS
+				}
+				if err := handleDayOffButton(slackClient, dbClient, config, userId, dayOffThreadTS); err != nil {
+					c.Logger().Error(err)
 					simba.SendErrorMessageToUser(slackClient, userId, err)
 					return err
-				} else if _, err = simba.UpdateMood(dbClient, dailyMood, &action.Value, nil); err != nil {
+				}
+			case strings.Contains(action.ActionID, "mood_user"):
+				moodThreadTS := callBackStruct.Container.MessageTs
+				if moodThreadTS == "" {
+					moodThreadTS = threadTS
+				}
+				viewModal := viewAppModalMood(
+					action.Value,
+					channelId,
+					moodThreadTS,
+					simba.FetchUserLocale(slackClient, userId),
+				)
+				viewResponse, err := slackClient.OpenView(callBackStruct.TriggerID, viewModal)
+				if err != nil {
+					c.Logger().Errorf("Failed open modal view %s", err.Error())
+					c.Logger().Errorf("MetadataError %v", viewResponse.ResponseMetadata.Messages)
 					simba.SendErrorMessageToUser(slackClient, userId, err)
 					return err
 				}
 
-				go simba.UpdateMessage(slackClient, config, dbClient, threadTS)
-
-				return nil
-			case strings.Contains(action.ActionID, "mood_user"):
-				dailyMood, err := simba.HandleAddDailyMood(
-					dbClient,
+			case action.Value == "send_kind_message":
+				err := handleKindMessageButton(
+					c,
 					slackClient,
-					channelId,
+					dbClient,
 					userId,
-					username,
-					action.Value,
-					threadTS,
+					action.ActionID,
+					callBackStruct.TriggerID,
 				)
 				if err != nil {
-					c.Error(err)
 					simba.SendErrorMessageToUser(slackClient, userId, err)
 					return err
 				}
-				viewModal := viewAppModalMood(userId, username, action.Value, dailyMood.ID)
-				viewResponse, err := slackClient.OpenView(callBackStruct.TriggerID, viewModal)
-				if err != nil {
-					c.Logger().Errorf("Failed open modal view %s", err.Error())
-					c.Logger().Errorf("MetadataError %v", viewResponse.ResponseMetadata.Messages)
-				}
-
-				threadTS, err := simba.UpdateMessage(slackClient, config, dbClient, threadTS)
+			case strings.HasPrefix(action.ActionID, "direct_message_"):
+				err := handleOutreachButton(
+					c,
+					slackClient,
+					dbClient,
+					userId,
+					action.Value,
+					callBackStruct.TriggerID,
+				)
 				if err != nil {
 					simba.SendErrorMessageToUser(slackClient, userId, err)
 					return err
 				}
-				config.SLACK_MESSAGE_CHANNEL <- threadTS
-
 			case strings.Contains(action.ActionID, "channel_selected"):
 				c.Logger().Printf("Enter channelSelected => %s", action.SelectedChannel)
 				_, users, err := simba.FetchUsersFromChannel(slackClient, action.SelectedChannel)
@@ -203,6 +221,7 @@ func handleRouteInteractive(
 						userId,
 						action.SelectedChannel,
 						users,
+						callBackStruct.View.PrivateMetadata,
 					),
 					"",
 				)
@@ -211,6 +230,110 @@ func handleRouteInteractive(
 					c.Logger().Error(viewResponse.Err())
 					return err
 				}
+			case strings.HasPrefix(action.ActionID, "trend_"):
+				err := handleTrendAction(
+					slackClient,
+					dbClient,
+					config,
+					userId,
+					action,
+					callBackStruct.View.PrivateMetadata,
+				)
+				if err != nil {
+					c.Logger().Error(err)
+					simba.SendErrorMessageToUser(slackClient, userId, err)
+					return err
+				}
+			case strings.HasPrefix(action.ActionID, "api_token_"):
+				err := handleAPITokenAction(
+					c,
+					slackClient,
+					dbClient,
+					config,
+					userId,
+					action,
+					callBackStruct.TriggerID,
+					callBackStruct.View.PrivateMetadata,
+				)
+				if err != nil {
+					c.Logger().Error(err)
+					simba.SendErrorMessageToUser(slackClient, userId, err)
+					return err
+				}
+			case strings.HasPrefix(action.ActionID, "hierarchy_"):
+				err := handleHierarchyAction(
+					c,
+					slackClient,
+					dbClient,
+					userId,
+					action,
+					callBackStruct.TriggerID,
+					callBackStruct.View.PrivateMetadata,
+				)
+				if err != nil {
+					c.Logger().Error(err)
+					simba.SendErrorMessageToUser(slackClient, userId, err)
+					return err
+				}
+			case strings.HasPrefix(action.ActionID, "webhook_"):
+				err := handleWebhookAction(
+					c,
+					slackClient,
+					dbClient,
+					config,
+					userId,
+					action,
+					callBackStruct.TriggerID,
+					callBackStruct.View.PrivateMetadata,
+				)
+				if err != nil {
+					c.Logger().Error(err)
+					simba.SendErrorMessageToUser(slackClient, userId, err)
+					return err
+				}
+			case strings.HasPrefix(action.ActionID, "roles_"):
+				err := handleRolesAction(
+					c,
+					slackClient,
+					dbClient,
+					userId,
+					action,
+					callBackStruct.TriggerID,
+					callBackStruct.View.PrivateMetadata,
+				)
+				if err != nil {
+					c.Logger().Error(err)
+					simba.SendErrorMessageToUser(slackClient, userId, err)
+					return err
+				}
+			case action.ActionID == "pause_until", action.ActionID == "pause_resume":
+				err := handlePauseAction(
+					slackClient,
+					dbClient,
+					config,
+					userId,
+					action,
+					callBackStruct.View.PrivateMetadata,
+				)
+				if err != nil {
+					c.Logger().Error(err)
+					simba.SendErrorMessageToUser(slackClient, userId, err)
+					return err
+				}
+			case action.ActionID == "email_preferences":
+				err := handleEmailAction(
+					slackClient,
+					dbClient,
+					config,
+					userId,
+					action,
+					callBackStruct.View.PrivateMetadata,
+				)
+				if err != nil {
+					c.Logger().Error(err)
+					simba.SendErrorMessageToUser(slackClient, userId, err)
+					return err
+				}
 			default:
 				err := simba.NewErrNoActionFound(action.ActionID, action.Value)
 				simba.SendErrorMessageToUser(slackClient, userId, err)
@@ -224,3 +347,89 @@ func handleRouteInteractive(
 
 	return nil
 }
+
+func handleViewSubmission(
+	c echo.Context,
+	slackClient *slack.Client,
+	config *simba.Config,
+	dbClient *gorm.DB,
+	callBackStruct *slack.InteractionCallback,
+) error {
+	switch callBackStruct.View.CallbackID {
+	case "mood_modal_sharing":
+		return handleMoodSubmission(c, slackClient, config, dbClient, callBackStruct)
+	case "outreach_modal":
+		return handleOutreachSubmission(c, slackClient, dbClient, callBackStruct)
+	case "kind_message_modal":
+		return handleKindMessageSubmission(c, slackClient, dbClient, callBackStruct)
+	case "api_token_modal":
+		return handleAPITokenSubmission(c, slackClient, dbClient, config, callBackStruct)
+	case "hierarchy_modal":
+		return handleHierarchySubmission(c, slackClient, dbClient, config, callBackStruct)
+	case "roles_modal":
+		return handleRolesSubmission(c, slackClient, dbClient, config, callBackStruct)
+	case "webhook_modal":
+		return handleWebhookSubmission(c, slackClient, dbClient, config, callBackStruct)
+	default:
+		return simba.NewErrNoActionFound(
+			callBackStruct.View.CallbackID,
+			callBackStruct.View.PrivateMetadata,
+		)
+	}
+}
+
+// @desc Validate and save mood, feeling and context of the mood modal at once
+// @returns response_action errors when a value is not valid
+func handleMoodSubmission(
+	c echo.Context,
+	slackClient *slack.Client,
+	config *simba.Config,
+	dbClient *gorm.DB,
+	callBackStruct *slack.InteractionCallback,
+) error {
+	metadata := strings.Split(callBackStruct.View.PrivateMetadata, "::")
+	if len(metadata) != 3 {
+		return fmt.Errorf("wrong daily mood metadata %s", callBackStruct.View.PrivateMetadata)
+	}
+	channelId, threadTS := metadata[1], metadata[2]
+	userId := callBackStruct.User.ID
+
+	values := callBackStruct.View.State.Values
+	mood := values["MoodSelect"]["mood_select"].SelectedOption.Value
+	feeling := values["MoodFeeling"]["mood_feeling"].SelectedOption.Value
+	context := strings.TrimSpace(values["MoodContext"]["mood_ctxt"].Value)
+
+	locale := simba.FetchUserLocale(slackClient, userId)
+	if errs := simba.ValidateMoodSubmission(mood, feeling, context, locale); len(errs) > 0 {
+		return c.JSON(http.StatusOK, slack.NewErrorsViewSubmissionResponse(errs))
+	}
+
+	username, err := fetchUsername(slackClient, userId)
+	if err != nil {
+		return err
+	}
+
+	_, err = simba.SaveDailyMood(
+		dbClient,
+		slackClient,
+		channelId,
+		userId,
+		username,
+		mood,
+		feeling,
+		context,
+		threadTS,
+	)
+	if err != nil {
+		simba.SendErrorMessageToUser(slackClient, userId, err)
+		return err
+	}
+
+	newThreadTS, err := simba.UpdateMessage(slackClient, config, dbClient, threadTS)
+	if err != nil {
+		simba.SendErrorMessageToUser(slackClient, userId, err)
+		return err
+	}
+	config.SLACK_MESSAGE_CHANNEL <- newThreadTS
+	return nil
+}
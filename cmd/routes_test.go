@@ -0,0 +1,65 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+const testSigningSecret = "signing-secret"
+
+func interactiveRequest(body string, headers map[string]string) (echo.Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPost, "/interactive", strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationForm)
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+	rec := httptest.NewRecorder()
+	return echo.New().NewContext(req, rec), rec
+}
+
+func slackSignature(timestamp, body string) string {
+	mac := hmac.New(sha256.New, []byte(testSigningSecret))
+	fmt.Fprintf(mac, "v0:%s:%s", timestamp, body)
+	return "v0=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestHandleRouteInteractiveUnsigned(t *testing.T) {
+	body := "payload=" + url.QueryEscape(`{"type":"block_actions","user":{"id":"UOWNER"}}`)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	c, rec := interactiveRequest(body, nil)
+	assert.Error(t, handleRouteInteractive(c, nil, nil, nil, testSigningSecret, ""))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+
+	c, rec = interactiveRequest(body, map[string]string{
+		"X-Slack-Request-Timestamp": timestamp,
+		"X-Slack-Signature":         "v0=forged",
+	})
+	assert.Error(t, handleRouteInteractive(c, nil, nil, nil, testSigningSecret, ""))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+func TestHandleRouteInteractiveSigned(t *testing.T) {
+	// A signed request gets past the check, the payload being read from the signed body
+	body := "payload=" + url.QueryEscape("not json")
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	c, rec := interactiveRequest(body, map[string]string{
+		"X-Slack-Request-Timestamp": timestamp,
+		"X-Slack-Signature":         slackSignature(timestamp, body),
+	})
+	err := handleRouteInteractive(c, nil, nil, nil, testSigningSecret, "")
+	assert.ErrorContains(t, err, "invalid character")
+	assert.NotEqual(t, http.StatusUnauthorized, rec.Code)
+}
@@ -26,81 +26,129 @@ func slackTextBlock(text string) *slack.TextBlockObject {
 	return textObject
 }
 
-func viewAppModalMood(userId, username, mood string, dailyMoodId uint) slack.ModalViewRequest {
-	blockActionId := "MoodFeeling"
-	var feelingButtonList []slack.BlockElement
+// viewAppModalMood collects mood, feeling and context which are only saved on submit
+func viewAppModalMood(mood, channelId, threadTS, locale string) slack.ModalViewRequest {
+	blockSet := []slack.Block{
+		simba.MoodInputRadio(mood, locale),
+		simba.FeelingInputSelect(locale),
+		simba.ContextInputText(locale),
+	}
 
-	switch mood {
-	case "good_mood":
-		excitedButton := slack.NewButtonBlockElement(
-			"excited_mood_feeling_select",
-			"Excited",
-			slackTextBlock("Excited :star-struck:"),
-		)
-		happyButton := slack.NewButtonBlockElement(
-			"happy_mood_feeling_select",
-			"Happy",
-			slackTextBlock("Happy :smile:"),
-		)
-		chillingButton := slack.NewButtonBlockElement(
-			"chilling_mood_feeling_select",
-			"Chilling",
-			slackTextBlock("Chilling :relaxed:"),
-		)
-		feelingButtonList = []slack.BlockElement{excitedButton, happyButton, chillingButton}
-	case "average_mood":
-		neutral := slack.NewButtonBlockElement(
-			"neutral_mood_feeling_select",
-			"Neutral",
-			slackTextBlock("Neutral :expressionless:"),
-		)
-		frustratedButton := slack.NewButtonBlockElement(
-			"frustrated_mood_feeling_select",
-			"Frustrated",
-			slackTextBlock("Frustrated :face_with_rolling_eyes:"),
-		)
-		tiredButton := slack.NewButtonBlockElement(
-			"tired_mood_feeling_select",
-			"Tired",
-			slackTextBlock("Tired :yawning_face:"),
-		)
-		feelingButtonList = []slack.BlockElement{neutral, frustratedButton, tiredButton}
-	case "bad_mood":
-		sadButton := slack.NewButtonBlockElement(
-			"sad_mood_feeling_select",
-			"Sad",
-			slackTextBlock("Sad :cry:"),
-		)
-		triumphButton := slack.NewButtonBlockElement(
-			"triumph_mood_feeling_select",
-			"Mad",
-			slackTextBlock("Mad :triumph:"),
-		)
-		disappointedButton := slack.NewButtonBlockElement(
-			"disappointed_mood_feeling_select",
-			"Disappointed",
-			slackTextBlock("Disappointed :disappointed:"),
-		)
-		feelingButtonList = []slack.BlockElement{sadButton, triumphButton, disappointedButton}
-	default:
-		log.Printf("[ERROR] entered in default case about mood")
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		Blocks:          slack.Blocks{BlockSet: blockSet},
+		Title:           slackTextBlock(simba.T(locale, "modal.mood.title")),
+		Close:           slackTextBlock(simba.T(locale, "modal.cancel")),
+		Submit:          slackTextBlock(simba.T(locale, "modal.share")),
+		CallbackID:      "mood_modal_sharing",
+		PrivateMetadata: fmt.Sprintf("daily_mood::%s::%s", channelId, threadTS),
+		ClearOnClose:    true,
+		NotifyOnClose:   true,
 	}
+}
 
-	blockAction := slack.NewActionBlock(blockActionId, feelingButtonList...)
-	inputBlock := simba.ContextInputText()
+// viewAppModalOutreach lets a manager adapt a supportive message before sending it as IM
+func viewAppModalOutreach(
+	targetUserId, targetName, locale string,
+	template simba.OutreachTemplate,
+) slack.ModalViewRequest {
+	messageInput := slack.NewPlainTextInputBlockElement(
+		slackTextBlock(simba.T(locale, "outreach.modal.placeholder")),
+		"outreach_message",
+	).WithInitialValue(template.Text)
+	messageInput.Multiline = true
 
-	blockSet := []slack.Block{blockAction, inputBlock}
+	inputBlock := slack.NewInputBlock(
+		"OutreachMessage",
+		slackTextBlock(simba.T(locale, "outreach.modal.label", targetName)),
+		nil,
+		messageInput,
+	)
+	inputBlock.Hint = slackTextBlock(simba.T(locale, "outreach.modal.hint"))
 
-	slackBlocks := slack.Blocks{BlockSet: blockSet}
+	return slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		Blocks:          slack.Blocks{BlockSet: []slack.Block{inputBlock}},
+		Title:           slackTextBlock(simba.T(locale, "outreach.modal.title")),
+		Close:           slackTextBlock(simba.T(locale, "modal.cancel")),
+		Submit:          slackTextBlock(simba.T(locale, "modal.send")),
+		CallbackID:      "outreach_modal",
+		PrivateMetadata: fmt.Sprintf("outreach::%s::%s", targetUserId, template.Name),
+		ClearOnClose:    true,
+	}
+}
+
+// viewAppModalKindMessage shows the days behind a mood percentage and lets the user ask someone for a chat
+func viewAppModalKindMessage(
+	mood, locale string,
+	moods []simba.DailyMood,
+) slack.ModalViewRequest {
+	blockSet := []slack.Block{
+		slack.NewSectionBlock(
+			slackMkDownBlock(simba.T(locale, "kind.modal.days", simba.FromMoodToSmiley(mood))),
+			nil,
+			nil,
+		),
+	}
+
+	for _, m := range moods {
+		if m.Mood != mood {
+			continue
+		}
+		dayText := fmt.Sprintf("*%s*", simba.FormatDay(m.CreatedAt, locale))
+		if m.Feeling != "" {
+			dayText = fmt.Sprintf(
+				"%s %s %s",
+				dayText,
+				simba.FromFeelingToSmiley(m.Feeling),
+				simba.T(locale, "feeling."+m.Feeling),
+			)
+		}
+		blockSet = append(blockSet, slack.NewSectionBlock(slackMkDownBlock(dayText), nil, nil))
+		if m.Context != "" {
+			blockSet = append(
+				blockSet,
+				slack.NewContextBlock(fmt.Sprintf("context_%d", m.ID), slackTextBlock(m.Context)),
+			)
+		}
+	}
+
+	peerSelect := slack.NewOptionsSelectBlockElement(
+		slack.OptTypeUser,
+		slackTextBlock(simba.T(locale, "kind.peer.placeholder")),
+		"chat_peer",
+	)
+	peerInput := slack.NewInputBlock(
+		"ChatPeer",
+		slackTextBlock(simba.T(locale, "kind.peer.label")),
+		nil,
+		peerSelect,
+	)
+	peerInput.Optional = true
+
+	messageInput := slack.NewPlainTextInputBlockElement(
+		slackTextBlock(simba.T(locale, "kind.message.placeholder")),
+		"chat_message",
+	)
+	messageInput.Multiline = true
+	messageBlock := slack.NewInputBlock(
+		"ChatMessage",
+		slackTextBlock(simba.T(locale, "kind.message.label")),
+		nil,
+		messageInput,
+	)
+	messageBlock.Optional = true
+
+	blockSet = append(blockSet, slack.NewDividerBlock(), peerInput, messageBlock)
 
 	return slack.ModalViewRequest{
 		Type:            slack.VTModal,
-		Blocks:          slackBlocks,
-		Title:           slackTextBlock("What's your mood"),
-		Close:           slackTextBlock("Cancel"),
-		Submit:          slackTextBlock("Share"),
-		CallbackID:      "mood_modal_sharing",
-		PrivateMetadata: fmt.Sprintf("daily_mood_id::%d", dailyMoodId),
+		Blocks:          slack.Blocks{BlockSet: blockSet},
+		Title:           slackTextBlock(simba.T(locale, "kind.modal.title")),
+		Close:           slackTextBlock(simba.T(locale, "modal.close")),
+		Submit:          slackTextBlock(simba.T(locale, "kind.modal.submit")),
+		CallbackID:      "kind_message_modal",
+		PrivateMetadata: fmt.Sprintf("kind_message::%s", mood),
 		ClearOnClose:    true,
 	}
 }
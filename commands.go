@@ -0,0 +1,104 @@
+package simba
+
+import (
+	"regexp"
+	"strings"
+)
+
+const (
+	MentionCommandHelp    = "help"
+	MentionCommandStats   = "stats"
+	MentionCommandMissing = "missing"
+	MentionCommandFeeling = "feeling"
+	MentionCommandQuotes  = "quotes"
+	MentionCommandHoliday = "holidays"
+	MentionCommandUnknown = "unknown"
+)
+
+var (
+	slackMentionRegexp = regexp.MustCompile(`<@[A-Z0-9]+(\|[^>]*)?>`)
+	feelingRegexp      = regexp.MustCompile(
+		`^(i'?m|i am|i feel|feeling|je me sens|je suis)\s+(feeling\s+)?(.+)$`,
+	)
+)
+
+// MentionCommand is the parsed representation of an @Simba mention.
+type MentionCommand struct {
+	Kind    string
+	Feeling string
+	// QuoteProvider and QuoteSource are set by the quotes command
+	QuoteProvider string
+	QuoteSource   string
+	// CalendarURL is set by the holidays command when a calendar has to be imported
+	CalendarURL string
+	Raw         string
+}
+
+// ParseMentionCommand turns the text of an app_mention event into a command.
+// Mentions of any user (including Simba) are stripped before parsing.
+func ParseMentionCommand(text string) *MentionCommand {
+	raw := strings.TrimSpace(slackMentionRegexp.ReplaceAllString(text, ""))
+	normalized := strings.ToLower(raw)
+	normalized = strings.ReplaceAll(normalized, "’", "'")
+	normalized = strings.Join(strings.Fields(normalized), " ")
+	normalized = strings.TrimRight(normalized, " ?!.")
+
+	cmd := &MentionCommand{Kind: MentionCommandUnknown, Raw: raw}
+	switch {
+	case normalized == "" || normalized == "help" || normalized == "aide":
+		cmd.Kind = MentionCommandHelp
+	case normalized == "stats" || normalized == "stat":
+		cmd.Kind = MentionCommandStats
+	case strings.HasPrefix(normalized, "who hasn't answered"),
+		strings.HasPrefix(normalized, "who has not answered"),
+		strings.HasPrefix(normalized, "qui n'a pas répondu"),
+		normalized == "missing":
+		cmd.Kind = MentionCommandMissing
+	case strings.HasPrefix(normalized, "quotes "), strings.HasPrefix(normalized, "citations "):
+		// The source is a path or an url so it is taken from raw to keep its case
+		args := strings.Fields(raw)[1:]
+		cmd.Kind = MentionCommandQuotes
+		cmd.QuoteProvider = strings.ToLower(args[0])
+		if len(args) > 1 {
+			cmd.QuoteSource = strings.Trim(args[1], "<>")
+		}
+	case normalized == "holidays", strings.HasPrefix(normalized, "holidays "),
+		normalized == "fériés", strings.HasPrefix(normalized, "fériés "):
+		args := strings.Fields(raw)[1:]
+		cmd.Kind = MentionCommandHoliday
+		if len(args) > 0 {
+			cmd.CalendarURL = strings.Trim(args[0], "<>")
+		}
+	default:
+		if matches := feelingRegexp.FindStringSubmatch(normalized); matches != nil {
+			feeling := ParseFeeling(matches[3])
+			if feeling == "" {
+				feeling = ParseFeeling(strings.Fields(matches[3])[0])
+			}
+			if feeling != "" {
+				cmd.Kind = MentionCommandFeeling
+				cmd.Feeling = feeling
+			}
+		}
+	}
+	return cmd
+}
+
+// MentionHelpText lists the commands understood by ParseMentionCommand.
+func MentionHelpText(locale string) string {
+	feelings := []string{}
+	for _, mood := range Moods {
+		for _, feeling := range MoodFeelings[mood] {
+			feelings = append(feelings, T(locale, "feeling."+feeling))
+		}
+	}
+	return strings.Join([]string{
+		T(locale, "mention.help.title"),
+		T(locale, "mention.help.stats"),
+		T(locale, "mention.help.missing"),
+		T(locale, "mention.help.feeling", strings.Join(feelings, ", ")),
+		T(locale, "mention.help.quotes"),
+		T(locale, "mention.help.holidays"),
+		T(locale, "mention.help.help"),
+	}, "\n")
+}
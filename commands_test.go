@@ -0,0 +1,73 @@
+package simba_test
+
+import (
+	"testing"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMentionCommandHelp(t *testing.T) {
+	assert.Equal(t, simba.MentionCommandHelp, simba.ParseMentionCommand("<@U0SIMBA> help").Kind)
+	assert.Equal(t, simba.MentionCommandHelp, simba.ParseMentionCommand("<@U0SIMBA>").Kind)
+}
+
+func TestParseMentionCommandStats(t *testing.T) {
+	assert.Equal(t, simba.MentionCommandStats, simba.ParseMentionCommand("<@U0SIMBA> stats").Kind)
+	assert.Equal(t, simba.MentionCommandStats, simba.ParseMentionCommand("<@U0SIMBA>  Stats ").Kind)
+}
+
+func TestParseMentionCommandMissing(t *testing.T) {
+	cmd := simba.ParseMentionCommand("<@U0SIMBA> who hasn't answered?")
+	assert.Equal(t, simba.MentionCommandMissing, cmd.Kind)
+	cmd = simba.ParseMentionCommand("<@U0SIMBA> Who hasn’t answered")
+	assert.Equal(t, simba.MentionCommandMissing, cmd.Kind)
+}
+
+func TestParseMentionCommandFeeling(t *testing.T) {
+	cmd := simba.ParseMentionCommand("<@U0SIMBA> I'm feeling tired")
+	assert.Equal(t, simba.MentionCommandFeeling, cmd.Kind)
+	assert.Equal(t, "Tired", cmd.Feeling)
+
+	cmd = simba.ParseMentionCommand("<@U0SIMBA> i feel Happy!")
+	assert.Equal(t, simba.MentionCommandFeeling, cmd.Kind)
+	assert.Equal(t, "Happy", cmd.Feeling)
+}
+
+func TestParseMentionCommandUnknown(t *testing.T) {
+	cmd := simba.ParseMentionCommand("<@U0SIMBA> I'm feeling hungry")
+	assert.Equal(t, simba.MentionCommandUnknown, cmd.Kind)
+	assert.Equal(t, "I'm feeling hungry", cmd.Raw)
+
+	cmd = simba.ParseMentionCommand("<@U0SIMBA> dance")
+	assert.Equal(t, simba.MentionCommandUnknown, cmd.Kind)
+}
+
+func TestFromFeelingToMood(t *testing.T) {
+	assert.Equal(t, "", simba.FromFeelingToMood(""))
+	assert.Equal(t, "good_mood", simba.FromFeelingToMood("Excited"))
+	assert.Equal(t, "average_mood", simba.FromFeelingToMood("Tired"))
+	assert.Equal(t, "bad_mood", simba.FromFeelingToMood("Disappointed"))
+}
+
+func TestParseMentionCommandQuotes(t *testing.T) {
+	cmd := simba.ParseMentionCommand("<@U0SIMBA> quotes url <https://Example.com/Quotes.json>")
+	assert.Equal(t, simba.MentionCommandQuotes, cmd.Kind)
+	assert.Equal(t, simba.QuoteProviderURL, cmd.QuoteProvider)
+	assert.Equal(t, "https://Example.com/Quotes.json", cmd.QuoteSource)
+
+	cmd = simba.ParseMentionCommand("<@U0SIMBA> citations DB")
+	assert.Equal(t, simba.MentionCommandQuotes, cmd.Kind)
+	assert.Equal(t, simba.QuoteProviderDB, cmd.QuoteProvider)
+	assert.Empty(t, cmd.QuoteSource)
+}
+
+func TestParseMentionCommandHolidays(t *testing.T) {
+	cmd := simba.ParseMentionCommand("<@U0SIMBA> holidays")
+	assert.Equal(t, simba.MentionCommandHoliday, cmd.Kind)
+	assert.Empty(t, cmd.CalendarURL)
+
+	cmd = simba.ParseMentionCommand("<@U0SIMBA> fériés <https://example.com/FR.ics>")
+	assert.Equal(t, simba.MentionCommandHoliday, cmd.Kind)
+	assert.Equal(t, "https://example.com/FR.ics", cmd.CalendarURL)
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -46,18 +49,86 @@ func InitConfig(isTesting bool) (*Config, error) {
 		)
 	}
 
+	summaryCronExpression, summaryCronExists := os.LookupEnv("APP_SUMMARY_CRON_EXPRESSION")
+	if !summaryCronExists {
+		summaryCronExpression = "0 0 18 ? * MON-FRI"
+	}
+
+	weeklySummaryCronExpression, weeklySummaryCronExists := os.LookupEnv("APP_WEEKLY_SUMMARY_CRON_EXPRESSION")
+	if !weeklySummaryCronExists {
+		weeklySummaryCronExpression = "0 30 17 ? * FRI"
+	}
+
+	riskCronExpression, riskCronExists := os.LookupEnv("APP_RISK_CRON_EXPRESSION")
+	if !riskCronExists {
+		riskCronExpression = "0 15 18 ? * MON-FRI"
+	}
+
+	locale := NormalizeLocale(os.Getenv("APP_LOCALE"))
+
+	quoteProvider := os.Getenv("APP_QUOTE_PROVIDER")
+	if quoteProvider == "" {
+		quoteProvider = QuoteProviderBundled
+	}
+	quoteNoRepeatDays, err := intFromEnv("APP_QUOTE_NO_REPEAT_DAYS", 30)
+	if err != nil {
+		return nil, err
+	}
+
+	minGroupSize, err := intFromEnv("APP_MIN_GROUP_SIZE", DefaultMinGroupSize)
+	if err != nil {
+		return nil, err
+	}
+
+	giphyApiUrl := os.Getenv("APP_GIPHY_API_URL")
+	if giphyApiUrl == "" {
+		giphyApiUrl = "https://api.giphy.com/v1"
+	}
+	giphyCacheDir := os.Getenv("APP_GIPHY_CACHE_DIR")
+	if giphyCacheDir == "" {
+		giphyCacheDir = filepath.Join(os.TempDir(), "simba-giphy")
+	}
+
 	dbConfig, err := initDbConfig()
 	if err != nil {
 		return nil, fmt.Errorf("initDbConfig failed : %s", err.Error())
 	}
 
+	riskRules, err := initRiskRules()
+	if err != nil {
+		return nil, fmt.Errorf("initRiskRules failed : %s", err.Error())
+	}
+
+	smtpConfig, err := initSmtpConfig()
+	if err != nil {
+		return nil, fmt.Errorf("initSmtpConfig failed : %s", err.Error())
+	}
+
 	slackMessageChannel := make(chan string)
 	return &Config{
 		CHANNEL_ID:            chanId,
 		SLACK_API_TOKEN:       slackApiToken,
 		APP_PORT:              applicationPort,
 		CRON_EXPRESSION:       cronExpression,
+		SUMMARY_CRON:          summaryCronExpression,
+		WEEKLY_SUMMARY_CRON:   weeklySummaryCronExpression,
+		RISK_CRON:             riskCronExpression,
+		RISK:                  riskRules,
+		LOCALE:                locale,
+		QUOTE_PROVIDER:        quoteProvider,
+		QUOTE_SOURCE:          os.Getenv("APP_QUOTE_SOURCE"),
+		QUOTE_NO_REPEAT_DAYS:  quoteNoRepeatDays,
+		GIPHY_TOKEN:           os.Getenv("APP_GIPHY_TOKEN"),
+		EXPORT_SALT:           os.Getenv("APP_EXPORT_SALT"),
+		MIN_GROUP_SIZE:        minGroupSize,
+		SLACK_CLIENT_ID:       os.Getenv("APP_SLACK_CLIENT_ID"),
+		SLACK_CLIENT_SECRET:   os.Getenv("APP_SLACK_CLIENT_SECRET"),
+		DASHBOARD_URL:         strings.TrimSuffix(os.Getenv("APP_DASHBOARD_URL"), "/"),
+		GIPHY_API_URL:         strings.TrimSuffix(giphyApiUrl, "/"),
+		GIPHY_TAG:             os.Getenv("APP_GIPHY_TAG"),
+		GIPHY_CACHE_DIR:       giphyCacheDir,
 		DB:                    dbConfig,
+		SMTP:                  smtpConfig,
 		SLACK_MESSAGE_CHANNEL: slackMessageChannel,
 	}, nil
 }
@@ -88,13 +159,102 @@ func initDbConfig() (*DbConfig, error) {
 	return &DbConfig{Username: user, Password: password, Host: host, Name: name}, nil
 }
 
+func initRiskRules() (*RiskRules, error) {
+	consecutiveBadMoods, err := intFromEnv("APP_RISK_CONSECUTIVE_BAD_MOODS", 3)
+	if err != nil {
+		return nil, err
+	}
+	baselineDrop, err := intFromEnv("APP_RISK_BASELINE_DROP", 30)
+	if err != nil {
+		return nil, err
+	}
+	tiredRatio, err := intFromEnv("APP_RISK_TIRED_RATIO", 60)
+	if err != nil {
+		return nil, err
+	}
+	cooldownDays, err := intFromEnv("APP_RISK_COOLDOWN_DAYS", 7)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RiskRules{
+		ConsecutiveBadMoods: consecutiveBadMoods,
+		BaselineDrop:        baselineDrop,
+		TiredRatio:          tiredRatio,
+		Cooldown:            time.Duration(cooldownDays) * 24 * time.Hour,
+	}, nil
+}
+
+// initSmtpConfig reads the SMTP server sending emails, emails being disabled without APP_SMTP_HOST.
+func initSmtpConfig() (*SmtpConfig, error) {
+	host := os.Getenv("APP_SMTP_HOST")
+	port, err := intFromEnv("APP_SMTP_PORT", 587)
+	if err != nil {
+		return nil, err
+	}
+	from := os.Getenv("APP_SMTP_FROM")
+	if host != "" && from == "" {
+		return nil, fmt.Errorf("APP_SMTP_FROM is not set")
+	}
+
+	return &SmtpConfig{
+		Host:     host,
+		Port:     port,
+		Username: os.Getenv("APP_SMTP_USERNAME"),
+		Password: os.Getenv("APP_SMTP_PASSWORD"),
+		From:     from,
+	}, nil
+}
+
+// intFromEnv reads a number from env, defaultValue being used when it is not set.
+func intFromEnv(name string, defaultValue int) (int, error) {
+	str := os.Getenv(name)
+	if str == "" {
+		return defaultValue, nil
+	}
+	value, err := strconv.Atoi(str)
+	if err != nil {
+		return 0, fmt.Errorf("%s is not a number : %s", name, err.Error())
+	}
+	return value, nil
+}
+
+// splitList reads a comma separated list, blank items left out.
+func splitList(str string) []string {
+	items := []string{}
+	for _, item := range strings.Split(str, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 type Config struct {
 	CHANNEL_ID            string
 	SLACK_API_TOKEN       string
 	APP_PORT              string
 	CRON_EXPRESSION       string
+	SUMMARY_CRON          string
+	WEEKLY_SUMMARY_CRON   string
+	RISK_CRON             string
+	RISK                  *RiskRules
+	LOCALE                string
+	QUOTE_PROVIDER        string
+	QUOTE_SOURCE          string
+	QUOTE_NO_REPEAT_DAYS  int
+	GIPHY_TOKEN           string
+	GIPHY_API_URL         string
+	GIPHY_TAG             string
+	GIPHY_CACHE_DIR       string
+	EXPORT_SALT           string
+	MIN_GROUP_SIZE        int
+	SLACK_CLIENT_ID       string
+	SLACK_CLIENT_SECRET   string
+	DASHBOARD_URL         string
 	SLACK_MESSAGE_CHANNEL chan string
 	DB                    *DbConfig
+	SMTP                  *SmtpConfig
 }
 
 type DbConfig struct {
@@ -103,3 +263,19 @@ type DbConfig struct {
 	Host     string
 	Name     string
 }
+
+// SmtpConfig is the SMTP server sending the emails, STARTTLS being used when the server offers it.
+type SmtpConfig struct {
+	Host string
+	Port int
+	// Username and Password are left empty for servers without authentication
+	Username string
+	Password string
+	// From is the sender, Simba <simba@example.com>
+	From string
+}
+
+// Enabled tells if emails can be sent.
+func (sc *SmtpConfig) Enabled() bool {
+	return sc != nil && sc.Host != ""
+}
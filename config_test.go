@@ -2,8 +2,10 @@ package simba_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestInitConfigChannelIdIsMissing(t *testing.T) {
@@ -114,3 +116,49 @@ func TestInitDbSuccess(t *testing.T) {
 		t.FailNow()
 	}
 }
+
+func TestInitConfigRiskRules(t *testing.T) {
+	t.Setenv("CHANNEL_ID", "toto")
+	t.Setenv("SLACK_API_TOKEN", "xob-xxxxxxx")
+	t.Setenv("APP_PORT", "1337")
+	t.Setenv("DB_USER", "fake_user")
+	t.Setenv("DB_PASSWORD", "fake_password")
+	t.Setenv("DB_HOST", "fake_host")
+	t.Setenv("DB_NAME", "fake_name")
+	t.Setenv("APP_RISK_CONSECUTIVE_BAD_MOODS", "0")
+	t.Setenv("APP_RISK_COOLDOWN_DAYS", "2")
+	config, err := simba.InitConfig(true)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, config.RISK.ConsecutiveBadMoods)
+	assert.Equal(t, 30, config.RISK.BaselineDrop)
+	assert.Equal(t, 48*time.Hour, config.RISK.Cooldown)
+
+	t.Setenv("APP_RISK_TIRED_RATIO", "half")
+	_, err = simba.InitConfig(true)
+	assert.EqualError(t, err, "initRiskRules failed : APP_RISK_TIRED_RATIO is not a number : strconv.Atoi: parsing \"half\": invalid syntax")
+}
+
+func TestInitConfigSmtp(t *testing.T) {
+	t.Setenv("CHANNEL_ID", "toto")
+	t.Setenv("SLACK_API_TOKEN", "xob-xxxxxxx")
+	t.Setenv("APP_PORT", "1337")
+	t.Setenv("DB_USER", "fake_user")
+	t.Setenv("DB_PASSWORD", "fake_password")
+	t.Setenv("DB_HOST", "fake_host")
+	t.Setenv("DB_NAME", "fake_name")
+	config, err := simba.InitConfig(true)
+	assert.Nil(t, err)
+	assert.False(t, config.SMTP.Enabled())
+	assert.Equal(t, 587, config.SMTP.Port)
+
+	t.Setenv("APP_SMTP_HOST", "localhost")
+	_, err = simba.InitConfig(true)
+	assert.EqualError(t, err, "initSmtpConfig failed : APP_SMTP_FROM is not set")
+
+	t.Setenv("APP_SMTP_PORT", "1025")
+	t.Setenv("APP_SMTP_FROM", "Simba <simba@example.com>")
+	config, err = simba.InitConfig(true)
+	assert.Nil(t, err)
+	assert.True(t, config.SMTP.Enabled())
+	assert.Equal(t, 1025, config.SMTP.Port)
+}
@@ -0,0 +1,84 @@
+package simba
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DashboardSessionDuration is how long people stay signed in on the dashboard.
+const DashboardSessionDuration = 12 * time.Hour
+
+// DashboardSession is a signed in browser, only the hash of its cookie is stored.
+type DashboardSession struct {
+	gorm.Model
+	Hash        string `gorm:"uniqueIndex"`
+	SlackUserID string
+	Locale      string
+	ExpiresAt   time.Time
+}
+
+// RandomToken returns size random bytes hex encoded, for cookies and OAuth states.
+func RandomToken(size int) (string, error) {
+	secret := make([]byte, size)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// CreateDashboardSession signs slackUserId in and returns the token of its cookie.
+// Expired sessions are removed on the way.
+func CreateDashboardSession(dbClient *gorm.DB, slackUserId, locale string, now time.Time) (string, error) {
+	if tx := dbClient.Unscoped().Where("expires_at < ?", now).Delete(&DashboardSession{}); tx.Error != nil {
+		return "", tx.Error
+	}
+
+	token, err := RandomToken(32)
+	if err != nil {
+		return "", err
+	}
+	session := &DashboardSession{
+		Hash:        HashAPIToken(token),
+		SlackUserID: slackUserId,
+		Locale:      NormalizeLocale(locale),
+		ExpiresAt:   now.Add(DashboardSessionDuration),
+	}
+	if tx := dbClient.Create(session); tx.Error != nil {
+		return "", tx.Error
+	}
+	return token, refreshDashboardSessionsGauge(dbClient, now)
+}
+
+// FetchDashboardSession returns the session of token, or nil if it is unknown or expired.
+func FetchDashboardSession(dbClient *gorm.DB, token string, now time.Time) (*DashboardSession, error) {
+	if token == "" {
+		return nil, nil
+	}
+	var session DashboardSession
+	if tx := dbClient.Find(&session, "hash = ? AND expires_at > ?", HashAPIToken(token), now); tx.Error != nil {
+		return nil, tx.Error
+	} else if session.ID == 0 {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+// DeleteDashboardSession signs the browser of token out.
+func DeleteDashboardSession(dbClient *gorm.DB, token string, now time.Time) error {
+	if tx := dbClient.Unscoped().Where("hash = ?", HashAPIToken(token)).Delete(&DashboardSession{}); tx.Error != nil {
+		return tx.Error
+	}
+	return refreshDashboardSessionsGauge(dbClient, now)
+}
+
+func refreshDashboardSessionsGauge(dbClient *gorm.DB, now time.Time) error {
+	var count int64
+	if tx := dbClient.Model(&DashboardSession{}).Where("expires_at > ?", now).Count(&count); tx.Error != nil {
+		return tx.Error
+	}
+	SetActiveSessions(SessionKindDashboard, count)
+	return nil
+}
@@ -20,7 +20,20 @@ import (
 
 // create database foreign key for user & credit_cards
 func handleMigration(db *gorm.DB) error {
-	if err := db.AutoMigrate(&User{}); err != nil {
+	if err := db.AutoMigrate(
+		&User{},
+		&Outreach{},
+		&ChannelSetting{},
+		&StoredQuote{},
+		&QuoteHistory{},
+		&Holiday{},
+		&RiskAlert{},
+		&APIToken{},
+		&DashboardSession{},
+		&RoleChange{},
+		&Webhook{},
+		&WebhookDelivery{},
+	); err != nil {
 		return err
 	}
 
@@ -58,6 +71,10 @@ func InitDbClient(dbHost, dbUser, dbPassword, dbName string, migrate bool) *gorm
 		panic(err)
 	}
 
+	if err := RegisterDBMetrics(db); err != nil {
+		panic(err)
+	}
+
 	if err := handleMigration(db); migrate && err != nil {
 		panic(err)
 	}
@@ -74,6 +91,8 @@ func UpdateMood(
 ) (*DailyMood, error) {
 	if sourceMood == nil {
 		return nil, fmt.Errorf("sourceMood is nil")
+	} else if sourceMood.ID == 0 {
+		return sourceMood, fmt.Errorf("sourceMood has not been saved yet")
 	}
 	if feeling != nil {
 		tx := dbClient.Model(sourceMood).Update("feeling", *feeling)
@@ -119,6 +138,18 @@ func UpdateMoodById(
 			return &sourceMood, tx.Error
 		}
 	}
+
+	if feeling != nil {
+		sourceMood.Feeling = *feeling
+	}
+	var user User
+	if tx := dbClient.First(&user, sourceMood.UserID); tx.Error != nil {
+		return &sourceMood, tx.Error
+	}
+	data := NewWebhookMoodData(&user, &sourceMood)
+	if err := EmitWebhookEvent(dbClient, WebhookEventMoodUpdated, data); err != nil {
+		return &sourceMood, fmt.Errorf("emit %s: %s", WebhookEventMoodUpdated, err.Error())
+	}
 	return &sourceMood, nil
 }
 
@@ -150,32 +181,59 @@ func handleUpdateDailyMood(
 		tx := dbClient.Debug().Session(&gorm.Session{FullSaveAssociations: true}).Updates(&user)
 		if tx.Error != nil {
 			return nil, fmt.Errorf("update with dailyMood: %s", tx.Error.Error())
+		} else if tx = dbClient.First(&moodToCreate, "user_id = ? AND thread_ts = ? ", user.ID, threadTS); tx.Error != nil {
+			return nil, fmt.Errorf("fetch real dailyMood failed : %s", tx.Error.Error())
 		}
 		return moodToCreate, nil
 	}
 }
 
+// emitMoodEvent queues the webhook event of a mood once it is complete, feeling and status included.
+func emitMoodEvent(dbClient *gorm.DB, event string, user *User, mood *DailyMood) error {
+	if err := EmitWebhookEvent(dbClient, event, NewWebhookMoodData(user, mood)); err != nil {
+		return fmt.Errorf("emit %s: %s", event, err.Error())
+	}
+	return nil
+}
+
 func HandleAddDailyMood(
 	dbClient *gorm.DB,
 	slackClient *slack.Client,
 	channelId, userId, userName, mood, threadTS string,
 ) (*DailyMood, error) {
+	dailyMood, user, event, err := addDailyMood(dbClient, slackClient, channelId, userId, userName, mood, threadTS)
+	if err != nil {
+		return nil, err
+	} else if err := emitMoodEvent(dbClient, event, user, dailyMood); err != nil {
+		return nil, err
+	}
+	return dailyMood, nil
+}
+
+// addDailyMood saves the mood of a user for a thread, replacing the one already shared, and returns
+// the webhook event it makes without emitting it so callers can complete the mood first.
+func addDailyMood(
+	dbClient *gorm.DB,
+	slackClient *slack.Client,
+	channelId, userId, userName, mood, threadTS string,
+) (*DailyMood, *User, string, error) {
 	var foundUser User = User{SlackUserID: userId, SlackChannelId: channelId, Username: userName}
 
 	tx := dbClient.FirstOrInit(&foundUser, "slack_user_id = ?", foundUser.SlackUserID)
 	if tx.Error != nil {
-		return nil, fmt.Errorf("firstOrInit: %s", tx.Error)
+		return nil, nil, "", fmt.Errorf("firstOrInit: %s", tx.Error)
 	} else if foundUser.ID != 0 {
 		if hasAlreadySetMood, err := HasAlreadySetMood(dbClient, slackClient, userId, threadTS); err != nil {
 			log.Printf("Error hasAlreadySetMood : %s", err.Error())
-			return nil, err
+			return nil, nil, "", err
 		} else if hasAlreadySetMood {
-			return handleUpdateDailyMood(dbClient, &foundUser, mood, threadTS)
+			dailyMood, err := handleUpdateDailyMood(dbClient, &foundUser, mood, threadTS)
+			return dailyMood, &foundUser, WebhookEventMoodUpdated, err
 		}
 	} else {
 		tx = dbClient.Debug().Save(&foundUser)
 		if tx.Error != nil {
-			return nil, tx.Error
+			return nil, nil, "", tx.Error
 		}
 	}
 
@@ -184,12 +242,87 @@ func HandleAddDailyMood(
 	foundUser.Moods = append(foundUser.Moods, *moodToCreate)
 	tx = dbClient.Debug().Session(&gorm.Session{FullSaveAssociations: true}).Updates(&foundUser)
 	if tx.Error != nil {
-		return nil, fmt.Errorf("update with dailyMood: %s", tx.Error.Error())
+		return nil, nil, "", fmt.Errorf("update with dailyMood: %s", tx.Error.Error())
 	} else if tx = dbClient.First(&moodToCreate, "user_id = ? AND thread_ts = ? ", foundUser.ID, threadTS); tx.Error != nil {
-		return nil, fmt.Errorf("fetch real dailyMood failed : %s", tx.Error.Error())
+		return nil, nil, "", fmt.Errorf("fetch real dailyMood failed : %s", tx.Error.Error())
 	}
 
-	return moodToCreate, nil
+	return moodToCreate, &foundUser, WebhookEventMoodRecorded, nil
+}
+
+// SaveDailyMood records mood, feeling and context of a user at once within a transaction.
+func SaveDailyMood(
+	dbClient *gorm.DB,
+	slackClient *slack.Client,
+	channelId, userId, userName, mood, feeling, context, threadTS string,
+) (*DailyMood, error) {
+	var dailyMood *DailyMood
+	err := dbClient.Transaction(func(tx *gorm.DB) error {
+		var user *User
+		var event string
+		var err error
+		dailyMood, user, event, err = addDailyMood(tx, slackClient, channelId, userId, userName, mood, threadTS)
+		if err != nil {
+			return err
+		} else if _, err = UpdateMood(tx, dailyMood, &feeling, &context); err != nil {
+			return err
+		}
+		dailyMood.Feeling = feeling
+		dailyMood.Context = context
+		return emitMoodEvent(tx, event, user, dailyMood)
+	})
+	if err != nil {
+		return nil, err
+	}
+	RecordedMood(mood)
+	return dailyMood, nil
+}
+
+// SaveDayOff records that a user is not working today instead of a mood.
+func SaveDayOff(
+	dbClient *gorm.DB,
+	slackClient *slack.Client,
+	channelId, userId, userName, threadTS string,
+) (*DailyMood, error) {
+	var dailyMood *DailyMood
+	err := dbClient.Transaction(func(tx *gorm.DB) error {
+		var user *User
+		var event string
+		var err error
+		dailyMood, user, event, err = addDailyMood(tx, slackClient, channelId, userId, userName, "", threadTS)
+		if err != nil {
+			return err
+		} else if dailyMood.ID == 0 {
+			return fmt.Errorf("day off of %s has not been saved", userId)
+		} else if err := tx.Model(dailyMood).Update("status", DailyMoodStatusOff).Error; err != nil {
+			return err
+		}
+		dailyMood.Status = DailyMoodStatusOff
+		return emitMoodEvent(tx, event, user, dailyMood)
+	})
+	if err != nil {
+		return nil, err
+	}
+	RecordedMood("")
+	return dailyMood, nil
+}
+
+// RetractDailyMood removes the mood of a user for a thread if it still is the given mood.
+func RetractDailyMood(dbClient *gorm.DB, slackUserId, mood, threadTS string) (bool, error) {
+	var user User
+	if tx := dbClient.Find(&user, "slack_user_id = ?", slackUserId); tx.Error != nil {
+		return false, tx.Error
+	} else if user.ID == 0 {
+		return false, nil
+	}
+
+	dailyMood, err := FetchMoodFromThreadTS(dbClient, threadTS, user.ID)
+	if err != nil {
+		return false, err
+	} else if dailyMood.ID == 0 || dailyMood.Mood != mood {
+		return false, nil
+	}
+	return deleteDailyMood(dbClient, dailyMood.ID)
 }
 
 func HasAlreadySetMood(
@@ -239,13 +372,24 @@ func FetchAllDailyMoodsByThreadTS(dbClient *gorm.DB, threadTS string) ([]*User,
 	return dailyMoodsUser, nil
 }
 
-func IsUserAdmin(dbClient *gorm.DB, userId string) (bool, error) {
-	var user *User
-	fetchUserTx := dbClient.Find(&user, "slack_user_id = ?", userId)
-	if fetchUserTx.Error != nil {
-		return false, fetchUserTx.Error
+// FetchLastMoodsBySlackUserId returns the last moods shared by a Slack user, most recent first.
+func FetchLastMoodsBySlackUserId(dbClient *gorm.DB, slackUserId string, limit int) ([]DailyMood, error) {
+	var user User
+	if tx := dbClient.Find(&user, "slack_user_id = ?", slackUserId); tx.Error != nil {
+		return nil, tx.Error
+	} else if user.ID == 0 {
+		return []DailyMood{}, nil
 	}
-	return user.IsManager, nil
+
+	var moods []DailyMood
+	tx := dbClient.Where("user_id = ? AND status <> ?", user.ID, DailyMoodStatusOff).
+		Limit(limit).
+		Order("created_at DESC").
+		Find(&moods)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return moods, nil
 }
 
 func FechCurrent(
@@ -272,10 +416,25 @@ type User struct {
 	SlackUserID    string
 	SlackChannelId string
 	IsManager      bool
-	Username       string      `gorm:"unique"`
-	Moods          []DailyMood `gorm:"many2many:has_moods"`
+	// ManagerSlackUserID is who the user reports to, empty when nobody
+	ManagerSlackUserID string      `gorm:"index"`
+	Username           string      `gorm:"unique"`
+	Moods              []DailyMood `gorm:"many2many:has_moods"`
+	// PausedUntil is the last day the user is away and should not be asked anything
+	PausedUntil *time.Time
+	// Role is the role assigned in Simba, empty to deduce it from Slack (see RoleOf)
+	Role string
+	// EmailDigest and EmailAlerts are the emails asked for, sent to the address of the Slack profile
+	EmailDigest bool
+	EmailAlerts bool
 }
 
+const (
+	DailyMoodStatusMood = "mood"
+	// DailyMoodStatusOff is the status of people not working that day, they have no mood
+	DailyMoodStatusOff = "off"
+)
+
 type DailyMood struct {
 	gorm.Model
 	CreatedAt time.Time
@@ -284,4 +443,10 @@ type DailyMood struct {
 	Feeling   string
 	ThreadTS  string
 	Context   string
+	Status    string `gorm:"default:mood"`
+}
+
+// IsOff tells if the user said they were not working instead of sharing a mood.
+func (dm DailyMood) IsOff() bool {
+	return dm.Status == DailyMoodStatusOff
 }
@@ -1,6 +1,7 @@
 package simba
 
 import (
+	"errors"
 	"fmt"
 )
 
@@ -29,3 +30,17 @@ func (err *ErrNoActionFound) Error() string {
 func NewErrNoActionFound(actionId, actionValue string) *ErrNoActionFound {
 	return &ErrNoActionFound{ActionID: actionId, ActionValue: actionValue}
 }
+
+// ------------------------------//
+var (
+	ErrUnknownUser  = errors.New("unknown user")
+	ErrManagerSelf  = errors.New("someone cannot be their own manager")
+	ErrManagerCycle = errors.New("the manager already reports to this person")
+	ErrUnknownRole  = errors.New("unknown role")
+	// ErrGroupTooSmall is returned when aggregates would be about fewer people than Config.MIN_GROUP_SIZE
+	ErrGroupTooSmall = errors.New("too few people to stay anonymous")
+	// ErrWrongTeam is returned when someone signs in with another Slack workspace than the one of Simba
+	ErrWrongTeam = errors.New("not a member of the Slack workspace of Simba")
+	// ErrInternalAddress is returned when a url given by a user leads to the internal network
+	ErrInternalAddress = errors.New("loopback, private and link-local addresses are not allowed")
+)
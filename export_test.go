@@ -1,3 +1,9 @@
 package simba
 
-var DrawResults = drawResults
+import "github.com/slack-go/slack"
+
+var WebhookHTTPClient = webhookHTTPClient
+
+var DrawResults = func(userWithDailyMoods []*User) ([]slack.Block, error) {
+	return drawResults(userWithDailyMoods, DefaultLocale)
+}
@@ -0,0 +1,146 @@
+package simba_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// fakeTable is what the fake database answers to the SELECT queries of a table.
+type fakeTable struct {
+	Columns []string
+	Rows    [][]driver.Value
+}
+
+// fakeQuery is a statement received by the fake database.
+type fakeQuery struct {
+	SQL  string
+	Args []driver.Value
+}
+
+// fakeDB is a database/sql driver answering queries from canned tables, enough to run the
+// gorm calls of Simba without Postgres. INSERT ... RETURNING gives increasing ids.
+type fakeDB struct {
+	mu      sync.Mutex
+	tables  map[string]fakeTable
+	queries []fakeQuery
+	lastId  int64
+}
+
+var (
+	fakeFromTable  = regexp.MustCompile(`FROM "(\w+)"`)
+	fakeReturning  = regexp.MustCompile(`RETURNING (.+)$`)
+	fakeInsertRows = regexp.MustCompile(`\),\(`)
+)
+
+// newFakeGormDB opens gorm on a fake database answering tables.
+func newFakeGormDB(t *testing.T, tables map[string]fakeTable) (*gorm.DB, *fakeDB) {
+	fake := &fakeDB{tables: tables}
+	sqlDB := sql.OpenDB(fake)
+	t.Cleanup(func() { sqlDB.Close() })
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, fake
+}
+
+// Queries returns the statements received starting with prefix.
+func (f *fakeDB) Queries(prefix string) []fakeQuery {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	queries := []fakeQuery{}
+	for _, query := range f.queries {
+		if strings.HasPrefix(query.SQL, prefix) {
+			queries = append(queries, query)
+		}
+	}
+	return queries
+}
+
+func (f *fakeDB) record(query string, args []driver.NamedValue) {
+	values := make([]driver.Value, len(args))
+	for idx, arg := range args {
+		values[idx] = arg.Value
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.queries = append(f.queries, fakeQuery{SQL: query, Args: values})
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return nil, driver.ErrSkip }
+
+type fakeConn struct{ db *fakeDB }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return c, nil }
+func (c *fakeConn) Commit() error                       { return nil }
+func (c *fakeConn) Rollback() error                     { return nil }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.db.record(query, args)
+	return driver.RowsAffected(1), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.record(query, args)
+	if returning := fakeReturning.FindStringSubmatch(query); strings.HasPrefix(query, "INSERT") && returning != nil {
+		columns := strings.Split(returning[1], ",")
+		rows := [][]driver.Value{}
+		for range fakeInsertRows.FindAllString(query, -1) {
+			rows = append(rows, nil)
+		}
+		rows = append(rows, nil)
+		c.db.mu.Lock()
+		for idx := range rows {
+			rows[idx] = make([]driver.Value, len(columns))
+			for col, column := range columns {
+				columns[col] = strings.Trim(strings.TrimSpace(column), `"`)
+				if columns[col] == "id" {
+					c.db.lastId++
+					rows[idx][col] = c.db.lastId
+				} else {
+					rows[idx][col] = ""
+				}
+			}
+		}
+		c.db.mu.Unlock()
+		return &fakeRows{columns: columns, rows: rows}, nil
+	}
+	if from := fakeFromTable.FindStringSubmatch(query); from != nil {
+		table := c.db.tables[from[1]]
+		return &fakeRows{columns: table.Columns, rows: table.Rows}, nil
+	}
+	return &fakeRows{}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
@@ -0,0 +1,138 @@
+package simba
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// giphyErrorLength is how much of the body of a failed search is kept in the error, proxies
+// answering whole HTML pages.
+const giphyErrorLength = 200
+
+// giphyIdRegexp matches the ids of Giphy, which end up in the name of the cached files.
+var giphyIdRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// GiphyClient searches GIFs on the Giphy API (or any API exposing the same routes).
+type GiphyClient struct {
+	Token      string
+	BaseURL    string
+	CacheDir   string
+	HTTPClient *http.Client
+}
+
+// NewGiphyClient returns a client configured from config or nil when no Giphy token is set.
+func NewGiphyClient(config *Config) *GiphyClient {
+	if config.GIPHY_TOKEN == "" {
+		return nil
+	}
+	return &GiphyClient{
+		Token:      config.GIPHY_TOKEN,
+		BaseURL:    config.GIPHY_API_URL,
+		CacheDir:   config.GIPHY_CACHE_DIR,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Search calls /gifs/search with query as q.
+func (gc *GiphyClient) Search(query string) (*GiphyResponse, error) {
+	params := url.Values{}
+	params.Set("api_key", gc.Token)
+	params.Set("q", query)
+	params.Set("limit", "25")
+	params.Set("rating", "g")
+
+	resp, err := gc.HTTPClient.Get(fmt.Sprintf("%s/gifs/search?%s", gc.BaseURL, params.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, giphyErrorLength))
+		return nil, fmt.Errorf(
+			"giphy search failed with status %d : %s",
+			resp.StatusCode,
+			strings.TrimSpace(string(body)),
+		)
+	}
+
+	var giphyResponse GiphyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&giphyResponse); err != nil {
+		return nil, err
+	}
+	return &giphyResponse, nil
+}
+
+// RandomGif picks one of the GIFs matching query.
+func (gc *GiphyClient) RandomGif(query string) (*giphyResponseData, error) {
+	giphyResponse, err := gc.Search(query)
+	if err != nil {
+		return nil, err
+	} else if len(giphyResponse.Data) == 0 {
+		return nil, fmt.Errorf("no gif found for %s", query)
+	}
+	return &giphyResponse.Data[rand.Intn(len(giphyResponse.Data))], nil
+}
+
+// CacheGif downloads the downsized version of a GIF in CacheDir and returns its path.
+// Already downloaded GIFs are not downloaded again.
+func (gc *GiphyClient) CacheGif(gif *giphyResponseData) (string, error) {
+	if !giphyIdRegexp.MatchString(gif.Id) {
+		return "", fmt.Errorf("invalid gif id %q", gif.Id)
+	}
+	gifUrl := gif.Images.DownSized.Url
+	if gifUrl == "" {
+		gifUrl = gif.Images.Original.Url
+	}
+	if err := os.MkdirAll(gc.CacheDir, 0o755); err != nil {
+		return "", err
+	}
+
+	filePath := filepath.Join(gc.CacheDir, fmt.Sprintf("%s.gif", gif.Id))
+	if err := DownloadFile(filePath, gifUrl, false); err != nil {
+		return "", err
+	}
+	return filePath, nil
+}
+
+// GiphyTagFromMood returns the search query matching a mood.
+func GiphyTagFromMood(mood string) string {
+	switch mood {
+	case "good_mood":
+		return "happy cat"
+	case "average_mood":
+		return "sleepy cat"
+	case "bad_mood":
+		return "grumpy cat"
+	default:
+		return "cat"
+	}
+}
+
+// SendGif finds a GIF for query, caches it and uploads it to the channel (in thread if threadTS is set).
+func SendGif(slackClient *slack.Client, gc *GiphyClient, channelId, threadTS, query string) error {
+	gif, err := gc.RandomGif(query)
+	if err != nil {
+		return err
+	}
+	filePath, err := gc.CacheGif(gif)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return SendImage(slackClient, channelId, threadTS, file, filepath.Base(filePath), gif.Title, "")
+}
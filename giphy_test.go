@@ -0,0 +1,130 @@
+package simba_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeGiphyServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	handler := http.NewServeMux()
+	var server *httptest.Server
+	handler.HandleFunc("/gifs/search", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("api_key") != "fake_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"meta":{"msg":"Unauthorized","status":401}}`))
+			return
+		}
+		if r.URL.Query().Get("q") == "nothing" {
+			_, _ = w.Write([]byte(`{"meta":{"msg":"OK","status":200},"data":[]}`))
+			return
+		}
+		id := "fake_gif"
+		if r.URL.Query().Get("q") == "evil" {
+			id = "../evil"
+		}
+		_, _ = fmt.Fprintf(
+			w,
+			`{"meta":{"msg":"OK","status":200},"data":[{"id":"%s","title":"%s","images":{"downsized":{"url":"%s/fake_gif.gif"}}}]}`,
+			id,
+			r.URL.Query().Get("q"),
+			server.URL,
+		)
+	})
+	handler.HandleFunc("/fake_gif.gif", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("GIF89a"))
+	})
+	server = httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGiphySearch(t *testing.T) {
+	server := fakeGiphyServer(t)
+	gc := &simba.GiphyClient{Token: "fake_token", BaseURL: server.URL, HTTPClient: server.Client()}
+
+	resp, err := gc.Search("happy cat")
+	assert.NoError(t, err)
+	assert.Len(t, resp.Data, 1)
+	assert.Equal(t, "fake_gif", resp.Data[0].Id)
+	assert.Equal(t, "happy cat", resp.Data[0].Title)
+}
+
+func TestGiphySearchUnauthorized(t *testing.T) {
+	server := fakeGiphyServer(t)
+	gc := &simba.GiphyClient{Token: "wrong_token", BaseURL: server.URL, HTTPClient: server.Client()}
+
+	_, err := gc.Search("happy cat")
+	assert.EqualError(t, err, `giphy search failed with status 401 : {"meta":{"msg":"Unauthorized","status":401}}`)
+}
+
+func TestGiphySearchErrorPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		_, _ = w.Write([]byte("<html><body>Bad Gateway" + strings.Repeat(" ", 300) + "</body></html>"))
+	}))
+	defer server.Close()
+	gc := &simba.GiphyClient{Token: "fake_token", BaseURL: server.URL, HTTPClient: server.Client()}
+
+	_, err := gc.Search("happy cat")
+	assert.EqualError(t, err, "giphy search failed with status 502 : <html><body>Bad Gateway")
+}
+
+func TestGiphyRandomGifNotFound(t *testing.T) {
+	server := fakeGiphyServer(t)
+	gc := &simba.GiphyClient{Token: "fake_token", BaseURL: server.URL, HTTPClient: server.Client()}
+
+	_, err := gc.RandomGif("nothing")
+	assert.EqualError(t, err, "no gif found for nothing")
+}
+
+func TestGiphyCacheGif(t *testing.T) {
+	server := fakeGiphyServer(t)
+	gc := &simba.GiphyClient{
+		Token:      "fake_token",
+		BaseURL:    server.URL,
+		CacheDir:   t.TempDir(),
+		HTTPClient: server.Client(),
+	}
+
+	gif, err := gc.RandomGif("grumpy cat")
+	assert.NoError(t, err)
+	filePath, err := gc.CacheGif(gif)
+	assert.NoError(t, err)
+
+	content, err := os.ReadFile(filePath)
+	assert.NoError(t, err)
+	assert.Equal(t, "GIF89a", string(content))
+}
+
+func TestGiphyCacheGifInvalidId(t *testing.T) {
+	server := fakeGiphyServer(t)
+	cacheDir := t.TempDir()
+	gc := &simba.GiphyClient{
+		Token:      "fake_token",
+		BaseURL:    server.URL,
+		CacheDir:   filepath.Join(cacheDir, "gifs"),
+		HTTPClient: server.Client(),
+	}
+
+	gif, err := gc.RandomGif("evil")
+	assert.NoError(t, err)
+	_, err = gc.CacheGif(gif)
+	assert.Error(t, err)
+	_, err = os.Stat(filepath.Join(cacheDir, "evil.gif"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestGiphyTagFromMood(t *testing.T) {
+	assert.Equal(t, "happy cat", simba.GiphyTagFromMood("good_mood"))
+	assert.Equal(t, "grumpy cat", simba.GiphyTagFromMood("bad_mood"))
+	assert.Equal(t, "cat", simba.GiphyTagFromMood(""))
+}
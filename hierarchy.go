@@ -0,0 +1,169 @@
+package simba
+
+import (
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// ReportsOf returns the reports of managerSlackUserId among users, the reports of their reports included.
+func ReportsOf(users []*User, managerSlackUserId string) []*User {
+	reportsByManager := map[string][]*User{}
+	for _, u := range users {
+		if u.ManagerSlackUserID != "" {
+			reportsByManager[u.ManagerSlackUserID] = append(reportsByManager[u.ManagerSlackUserID], u)
+		}
+	}
+
+	reports := []*User{}
+	// Seen guards against cycles written in the DB by hand
+	seen := map[string]bool{managerSlackUserId: true}
+	queue := []string{managerSlackUserId}
+	for len(queue) > 0 {
+		manager := queue[0]
+		queue = queue[1:]
+		for _, report := range reportsByManager[manager] {
+			if seen[report.SlackUserID] {
+				continue
+			}
+			seen[report.SlackUserID] = true
+			reports = append(reports, report)
+			queue = append(queue, report.SlackUserID)
+		}
+	}
+	return reports
+}
+
+// HasHierarchy tells if at least one of users reports to someone.
+func HasHierarchy(users []*User) bool {
+	for _, u := range users {
+		if u.ManagerSlackUserID != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Audience is who someone can see the moods of.
+type Audience struct {
+	Everyone     bool
+	SlackUserIDs map[string]bool
+}
+
+// Includes tells if the moods of slackUserId can be seen.
+func (a Audience) Includes(slackUserId string) bool {
+	return a.Everyone || a.SlackUserIDs[slackUserId]
+}
+
+// SlackUserIDList returns the people of the audience sorted, nil when it is everyone.
+func (a Audience) SlackUserIDList() []string {
+	if a.Everyone {
+		return nil
+	}
+	ids := []string{}
+	for id := range a.SlackUserIDs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// NewAudience returns who viewerSlackUserId can see among users: everyone for admins and
+// while no hierarchy has been set up, their reports otherwise.
+func NewAudience(users []*User, viewerSlackUserId string, isAdmin bool) Audience {
+	if isAdmin || !HasHierarchy(users) {
+		return Audience{Everyone: true}
+	}
+	audience := Audience{SlackUserIDs: map[string]bool{}}
+	for _, report := range ReportsOf(users, viewerSlackUserId) {
+		audience.SlackUserIDs[report.SlackUserID] = true
+	}
+	return audience
+}
+
+// FetchAudience returns who viewerSlackUserId can see.
+func FetchAudience(dbClient *gorm.DB, viewerSlackUserId string, isAdmin bool) (Audience, error) {
+	var users []*User
+	if tx := dbClient.Find(&users); tx.Error != nil {
+		return Audience{}, tx.Error
+	}
+	return NewAudience(users, viewerSlackUserId, isAdmin), nil
+}
+
+// CheckManager tells why slackUserId cannot report to managerSlackUserId, if they cannot.
+func CheckManager(users []*User, slackUserId, managerSlackUserId string) error {
+	if slackUserId == managerSlackUserId {
+		return ErrManagerSelf
+	}
+	for _, report := range ReportsOf(users, slackUserId) {
+		if report.SlackUserID == managerSlackUserId {
+			return ErrManagerCycle
+		}
+	}
+	return nil
+}
+
+// SetManager makes slackUserId report to managerSlackUserId, who becomes a manager.
+// An empty managerSlackUserId removes the manager of slackUserId.
+func SetManager(dbClient *gorm.DB, slackUserId, managerSlackUserId string) error {
+	var users []*User
+	if tx := dbClient.Find(&users); tx.Error != nil {
+		return tx.Error
+	}
+	var report, manager *User
+	for _, u := range users {
+		switch u.SlackUserID {
+		case slackUserId:
+			report = u
+		case managerSlackUserId:
+			manager = u
+		}
+	}
+	if report == nil {
+		return fmt.Errorf("%w : %s", ErrUnknownUser, slackUserId)
+	}
+	if managerSlackUserId == "" {
+		return dbClient.Model(report).Update("manager_slack_user_id", "").Error
+	} else if manager == nil {
+		return fmt.Errorf("%w : %s", ErrUnknownUser, managerSlackUserId)
+	} else if err := CheckManager(users, slackUserId, managerSlackUserId); err != nil {
+		return err
+	}
+
+	return dbClient.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(report).Update("manager_slack_user_id", managerSlackUserId).Error; err != nil {
+			return err
+		}
+		return tx.Model(manager).Update("is_manager", true).Error
+	})
+}
+
+// FetchHierarchy returns the reports of every manager, managers sorted by username.
+func FetchHierarchy(dbClient *gorm.DB) ([]*User, map[string][]*User, error) {
+	var users []*User
+	if tx := dbClient.Order("username").Find(&users); tx.Error != nil {
+		return nil, nil, tx.Error
+	}
+	usersBySlackId := map[string]*User{}
+	for _, u := range users {
+		usersBySlackId[u.SlackUserID] = u
+	}
+	managers := []*User{}
+	reportsByManager := map[string][]*User{}
+	for _, u := range users {
+		if u.ManagerSlackUserID == "" {
+			continue
+		}
+		if _, seen := reportsByManager[u.ManagerSlackUserID]; !seen {
+			if manager, ok := usersBySlackId[u.ManagerSlackUserID]; ok {
+				managers = append(managers, manager)
+			}
+		}
+		reportsByManager[u.ManagerSlackUserID] = append(reportsByManager[u.ManagerSlackUserID], u)
+	}
+	sort.Slice(managers, func(i, j int) bool {
+		return managers[i].Username < managers[j].Username
+	})
+	return managers, reportsByManager, nil
+}
@@ -0,0 +1,73 @@
+package simba_test
+
+import (
+	"testing"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeHierarchy is alice <- bob <- carol, alice <- dave, erin reporting to nobody
+func fakeHierarchy() []*simba.User {
+	return []*simba.User{
+		{SlackUserID: "alice"},
+		{SlackUserID: "bob", ManagerSlackUserID: "alice"},
+		{SlackUserID: "carol", ManagerSlackUserID: "bob"},
+		{SlackUserID: "dave", ManagerSlackUserID: "alice"},
+		{SlackUserID: "erin"},
+	}
+}
+
+func slackUserIds(users []*simba.User) []string {
+	ids := []string{}
+	for _, u := range users {
+		ids = append(ids, u.SlackUserID)
+	}
+	return ids
+}
+
+func TestReportsOf(t *testing.T) {
+	users := fakeHierarchy()
+	assert.ElementsMatch(t, []string{"bob", "carol", "dave"}, slackUserIds(simba.ReportsOf(users, "alice")))
+	assert.ElementsMatch(t, []string{"carol"}, slackUserIds(simba.ReportsOf(users, "bob")))
+	assert.Empty(t, simba.ReportsOf(users, "erin"))
+}
+
+func TestReportsOfCycle(t *testing.T) {
+	users := []*simba.User{
+		{SlackUserID: "alice", ManagerSlackUserID: "bob"},
+		{SlackUserID: "bob", ManagerSlackUserID: "alice"},
+	}
+	assert.Equal(t, []string{"bob"}, slackUserIds(simba.ReportsOf(users, "alice")))
+}
+
+func TestNewAudience(t *testing.T) {
+	users := fakeHierarchy()
+
+	admin := simba.NewAudience(users, "erin", true)
+	assert.True(t, admin.Everyone)
+	assert.Nil(t, admin.SlackUserIDList())
+
+	manager := simba.NewAudience(users, "bob", false)
+	assert.True(t, manager.Includes("carol"))
+	assert.False(t, manager.Includes("bob"))
+	assert.False(t, manager.Includes("dave"))
+	assert.Equal(t, []string{"carol"}, manager.SlackUserIDList())
+
+	nobody := simba.NewAudience(users, "erin", false)
+	assert.Equal(t, []string{}, nobody.SlackUserIDList())
+}
+
+func TestNewAudienceWithoutHierarchy(t *testing.T) {
+	users := []*simba.User{{SlackUserID: "alice", IsManager: true}, {SlackUserID: "bob"}}
+	assert.False(t, simba.HasHierarchy(users))
+	assert.True(t, simba.NewAudience(users, "alice", false).Includes("bob"))
+}
+
+func TestCheckManager(t *testing.T) {
+	users := fakeHierarchy()
+	assert.Nil(t, simba.CheckManager(users, "erin", "carol"))
+	assert.Nil(t, simba.CheckManager(users, "carol", "dave"))
+	assert.ErrorIs(t, simba.CheckManager(users, "erin", "erin"), simba.ErrManagerSelf)
+	assert.ErrorIs(t, simba.CheckManager(users, "alice", "carol"), simba.ErrManagerCycle)
+}
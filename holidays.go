@@ -0,0 +1,277 @@
+package simba
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	HolidayFormatICS = "ics"
+	HolidayFormatCSV = "csv"
+)
+
+// holidayDateLayouts are the date formats accepted in CSV calendars.
+var holidayDateLayouts = []string{"2006-01-02", "02/01/2006", "20060102"}
+
+// HolidayDate truncates t to the day so holidays can be compared whatever the time.
+func HolidayDate(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// ParseHolidaysCSV reads a calendar made of "date,name" lines (a header line is allowed).
+func ParseHolidaysCSV(reader io.Reader) ([]Holiday, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	holidays := []Holiday{}
+	for idx, record := range records {
+		if len(record) == 0 || strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		date, err := parseHolidayDate(strings.TrimSpace(record[0]))
+		if err != nil {
+			if idx == 0 {
+				// First line is the header
+				continue
+			}
+			return nil, fmt.Errorf("line %d : %s", idx+1, err.Error())
+		}
+		holiday := Holiday{Date: date}
+		if len(record) > 1 {
+			holiday.Name = strings.TrimSpace(record[1])
+		}
+		holidays = append(holidays, holiday)
+	}
+	return holidays, nil
+}
+
+// maxHolidaySpan is the longest event imported, a longer one being a mistake in the calendar.
+const maxHolidaySpan = 366
+
+// ParseHolidaysICS reads the all-day VEVENTs of an iCalendar file, timed events (ie: a meeting
+// in a shared calendar) being skipped. Events lasting several days are expanded to one holiday
+// per day, events lasting more than a year are refused.
+func ParseHolidaysICS(reader io.Reader) ([]Holiday, error) {
+	holidays := []Holiday{}
+	var start, end time.Time
+	var name string
+	inEvent, timed := false, false
+
+	for _, line := range unfoldICSLines(reader) {
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		property := strings.ToUpper(strings.SplitN(key, ";", 2)[0])
+		switch {
+		case property == "BEGIN" && value == "VEVENT":
+			inEvent, timed = true, false
+			start, end, name = time.Time{}, time.Time{}, ""
+		case property == "END" && value == "VEVENT":
+			inEvent = false
+			if start.IsZero() {
+				return nil, fmt.Errorf("event %s has no DTSTART", name)
+			} else if timed {
+				continue
+			}
+			if end.IsZero() || !end.After(start) {
+				// DTEND is exclusive and optional for a one day event
+				end = start.AddDate(0, 0, 1)
+			} else if end.After(start.AddDate(0, 0, maxHolidaySpan)) {
+				return nil, fmt.Errorf("event %s lasts more than %d days", name, maxHolidaySpan)
+			}
+			for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+				holidays = append(holidays, Holiday{Date: day, Name: name})
+			}
+		case !inEvent:
+			continue
+		case property == "DTSTART", property == "DTEND":
+			date, err := parseHolidayDate(value)
+			if err != nil {
+				return nil, err
+			}
+			if property == "DTSTART" {
+				// All-day events start with a date (VALUE=DATE), timed ones with a date-time
+				start, timed = date, strings.Contains(value, "T")
+			} else {
+				end = date
+			}
+		case property == "SUMMARY":
+			name = strings.ReplaceAll(value, `\,`, ",")
+		}
+	}
+	return holidays, nil
+}
+
+// unfoldICSLines joins the lines folded by RFC 5545 (continued by a leading space or tab).
+func unfoldICSLines(reader io.Reader) []string {
+	lines := []string{}
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func parseHolidayDate(value string) (time.Time, error) {
+	// ICS date-times (ie: 20261225T000000Z) only matter for their day
+	value = strings.SplitN(value, "T", 2)[0]
+	for _, layout := range holidayDateLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return HolidayDate(date), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%s is not a valid date", value)
+}
+
+// ParseHolidays reads a calendar in the given format (see HolidayFormatICS and HolidayFormatCSV).
+func ParseHolidays(reader io.Reader, format string) ([]Holiday, error) {
+	switch format {
+	case HolidayFormatICS:
+		return ParseHolidaysICS(reader)
+	case HolidayFormatCSV:
+		return ParseHolidaysCSV(reader)
+	default:
+		return nil, fmt.Errorf("holiday calendar format %s is not supported", format)
+	}
+}
+
+// FetchHolidayCalendar downloads and parses a calendar, guessing its format from the url
+// or the content type. Only public https urls can be fetched.
+func FetchHolidayCalendar(url string) ([]Holiday, error) {
+	if err := ValidatePublicURL(url); err != nil {
+		return nil, err
+	}
+	resp, err := NewPublicHTTPClient(10 * time.Second).Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch calendar %s failed with status %d", url, resp.StatusCode)
+	}
+
+	format := HolidayFormatCSV
+	if strings.HasSuffix(strings.ToLower(strings.SplitN(url, "?", 2)[0]), ".ics") ||
+		strings.Contains(resp.Header.Get("Content-Type"), "text/calendar") {
+		format = HolidayFormatICS
+	}
+	return ParseHolidays(resp.Body, format)
+}
+
+// mergeHolidays keeps a single holiday per day, joining the names of the holidays
+// falling on the same day (ie: overlapping ICS events) in their order.
+func mergeHolidays(holidays []Holiday) []Holiday {
+	merged := []Holiday{}
+	byDate := map[time.Time]int{}
+	for _, holiday := range holidays {
+		holiday.Date = HolidayDate(holiday.Date)
+		idx, found := byDate[holiday.Date]
+		if !found {
+			byDate[holiday.Date] = len(merged)
+			merged = append(merged, holiday)
+			continue
+		}
+		names := strings.Split(merged[idx].Name, " / ")
+		if holiday.Name != "" && !slices.Contains(names, holiday.Name) {
+			if merged[idx].Name == "" {
+				merged[idx].Name = holiday.Name
+			} else {
+				merged[idx].Name += " / " + holiday.Name
+			}
+		}
+	}
+	return merged
+}
+
+// ImportHolidays saves holidays of a channel, renaming the ones already imported for the same day.
+// Holidays falling on the same day are saved once, a single INSERT not being able to update a row twice.
+func ImportHolidays(dbClient *gorm.DB, channelId string, holidays []Holiday) (int, error) {
+	if len(holidays) == 0 {
+		return 0, nil
+	}
+	holidays = mergeHolidays(holidays)
+	for idx := range holidays {
+		holidays[idx].ChannelID = channelId
+	}
+	tx := dbClient.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "channel_id"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "updated_at"}),
+	}).Create(&holidays)
+	if tx.Error != nil {
+		return 0, tx.Error
+	}
+	return len(holidays), nil
+}
+
+// FetchHolidays returns the holidays of a channel between from and to (included).
+func FetchHolidays(dbClient *gorm.DB, channelId string, from, to time.Time) ([]Holiday, error) {
+	var holidays []Holiday
+	tx := dbClient.Where("channel_id = ? AND date BETWEEN ? AND ?", channelId, HolidayDate(from), HolidayDate(to)).
+		Order("date ASC").
+		Find(&holidays)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return holidays, nil
+}
+
+// IsHoliday tells if day is a holiday of the channel.
+func IsHoliday(dbClient *gorm.DB, channelId string, day time.Time) (bool, error) {
+	holidays, err := FetchHolidays(dbClient, channelId, day, day)
+	if err != nil {
+		return false, err
+	}
+	return len(holidays) > 0, nil
+}
+
+// IsWorkingDay tells if day is neither a weekend day nor one of holidays.
+func IsWorkingDay(day time.Time, holidays []Holiday) bool {
+	if day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+		return false
+	}
+	date := HolidayDate(day)
+	for _, h := range holidays {
+		if h.Date.Equal(date) {
+			return false
+		}
+	}
+	return true
+}
+
+// WorkingDaysBetween counts the working days between from and to (included).
+func WorkingDaysBetween(from, to time.Time, holidays []Holiday) int {
+	count := 0
+	for day := HolidayDate(from); !day.After(HolidayDate(to)); day = day.AddDate(0, 0, 1) {
+		if IsWorkingDay(day, holidays) {
+			count++
+		}
+	}
+	return count
+}
+
+type Holiday struct {
+	gorm.Model
+	ChannelID string    `gorm:"uniqueIndex:idx_holiday_channel_date"`
+	Date      time.Time `gorm:"type:date;uniqueIndex:idx_holiday_channel_date"`
+	Name      string
+}
@@ -0,0 +1,151 @@
+package simba_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+)
+
+func utcDay(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestParseHolidaysCSV(t *testing.T) {
+	content := "date,name\n2026-12-25,Christmas\n01/01/2027, New Year\n\n20270505\n"
+	holidays, err := simba.ParseHolidaysCSV(strings.NewReader(content))
+	assert.Nil(t, err)
+	assert.Len(t, holidays, 3)
+	assert.Equal(t, utcDay(2026, time.December, 25), holidays[0].Date)
+	assert.Equal(t, "Christmas", holidays[0].Name)
+	assert.Equal(t, utcDay(2027, time.January, 1), holidays[1].Date)
+	assert.Equal(t, "New Year", holidays[1].Name)
+	assert.Equal(t, utcDay(2027, time.May, 5), holidays[2].Date)
+	assert.Empty(t, holidays[2].Name)
+}
+
+func TestParseHolidaysCSVInvalidDate(t *testing.T) {
+	_, err := simba.ParseHolidaysCSV(strings.NewReader("2026-12-25,Christmas\nsoon,Party\n"))
+	assert.NotNil(t, err)
+}
+
+func TestParseHolidaysICS(t *testing.T) {
+	content := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20261225",
+		"DTEND;VALUE=DATE:20261226",
+		"SUMMARY:Christ",
+		" mas",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20270101",
+		"SUMMARY:New Year",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART:20261020T140000Z",
+		"DTEND:20261020T150000Z",
+		"SUMMARY:Team meeting",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20270802",
+		"DTEND;VALUE=DATE:20270805",
+		"SUMMARY:Company off-site\\, summer",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	holidays, err := simba.ParseHolidaysICS(strings.NewReader(content))
+	assert.Nil(t, err)
+	assert.Len(t, holidays, 5)
+	assert.Equal(t, utcDay(2026, time.December, 25), holidays[0].Date)
+	assert.Equal(t, "Christmas", holidays[0].Name)
+	assert.Equal(t, utcDay(2027, time.January, 1), holidays[1].Date)
+	assert.Equal(t, utcDay(2027, time.August, 4), holidays[4].Date)
+	assert.Equal(t, "Company off-site, summer", holidays[4].Name)
+}
+
+func TestParseHolidaysICSTooLong(t *testing.T) {
+	content := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20261225",
+		"DTEND;VALUE=DATE:99991231",
+		"SUMMARY:Forever",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	_, err := simba.ParseHolidaysICS(strings.NewReader(content))
+	assert.ErrorContains(t, err, "Forever lasts more than")
+}
+
+func TestParseHolidaysUnknownFormat(t *testing.T) {
+	_, err := simba.ParseHolidays(strings.NewReader(""), "xlsx")
+	assert.NotNil(t, err)
+}
+
+func TestFetchHolidayCalendarRefusesInternalUrls(t *testing.T) {
+	_, err := simba.FetchHolidayCalendar("http://example.com/holidays.ics")
+	assert.NotNil(t, err)
+	_, err = simba.FetchHolidayCalendar("https://169.254.169.254/latest/meta-data")
+	assert.ErrorIs(t, err, simba.ErrInternalAddress)
+}
+
+func TestWorkingDaysBetween(t *testing.T) {
+	holidays := []simba.Holiday{{Date: utcDay(2026, time.December, 25), Name: "Christmas"}}
+	// Monday 21 to Sunday 27 December 2026
+	assert.Equal(t, 4, simba.WorkingDaysBetween(utcDay(2026, time.December, 21), utcDay(2026, time.December, 27), holidays))
+	assert.Equal(t, 5, simba.WorkingDaysBetween(utcDay(2026, time.December, 21), utcDay(2026, time.December, 27), nil))
+	assert.False(t, simba.IsWorkingDay(time.Date(2026, time.December, 25, 10, 0, 0, 0, time.UTC), holidays))
+	assert.False(t, simba.IsWorkingDay(utcDay(2026, time.December, 26), nil))
+	assert.True(t, simba.IsWorkingDay(utcDay(2026, time.December, 24), holidays))
+}
+
+func TestImportHolidaysOverlappingEvents(t *testing.T) {
+	content := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20261224",
+		"DTEND;VALUE=DATE:20261227",
+		"SUMMARY:Winter break",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20261225",
+		"SUMMARY:Christmas",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART;VALUE=DATE:20261225",
+		"SUMMARY:Christmas",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	holidays, err := simba.ParseHolidaysICS(strings.NewReader(content))
+	assert.Nil(t, err)
+	assert.Len(t, holidays, 5)
+
+	db, fake := newFakeGormDB(t, nil)
+	count, err := simba.ImportHolidays(db, "C1", holidays)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, count)
+
+	inserts := fake.Queries(`INSERT INTO "holidays"`)
+	if assert.Len(t, inserts, 1) {
+		dates := map[time.Time]bool{}
+		names := []string{}
+		for _, arg := range inserts[0].Args {
+			switch value := arg.(type) {
+			case time.Time:
+				if value.Equal(simba.HolidayDate(value)) {
+					dates[value] = true
+				}
+			case string:
+				if value != "C1" {
+					names = append(names, value)
+				}
+			}
+		}
+		assert.Len(t, dates, 3)
+		assert.Equal(t, []string{"Winter break", "Winter break / Christmas", "Winter break"}, names)
+	}
+}
@@ -0,0 +1,583 @@
+package simba
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// DefaultLocale is used when the locale of a user is unknown or not supported.
+const DefaultLocale = "en"
+
+var catalog = map[string]map[string]string{
+	"en": {
+		"checkin.title":        "Hey folks! What is your mood today:\nQuote of the Day: *%s*",
+		"checkin.quote_author": "From %s",
+		"checkin.day_off":      "Not working today :zzz:",
+		"checkin.users_off":    ":zzz: Not working today: %s",
+
+		"mood.label.good_mood":    "Good Mood :heart:",
+		"mood.label.average_mood": "Meow :yellow_heart:",
+		"mood.label.bad_mood":     "Grr ! :black_heart:",
+		"mood.name.good_mood":     "good mood",
+		"mood.name.average_mood":  "average mood",
+		"mood.name.bad_mood":      "bad mood",
+
+		"feeling.Excited":      "Excited",
+		"feeling.Happy":        "Happy",
+		"feeling.Chilling":     "Chilling",
+		"feeling.Neutral":      "Neutral",
+		"feeling.Frustrated":   "Frustrated",
+		"feeling.Tired":        "Tired",
+		"feeling.Sad":          "Sad",
+		"feeling.Mad":          "Mad",
+		"feeling.Disappointed": "Disappointed",
+
+		"modal.cancel": "Cancel",
+		"modal.close":  "Close",
+		"modal.share":  "Share",
+		"modal.send":   "Send",
+
+		"modal.mood.title":          "What's your mood",
+		"input.mood.label":          "Mood",
+		"input.feeling.label":       "Feeling",
+		"input.feeling.placeholder": "How do you feel",
+		"input.context.label":       "Context",
+		"input.context.hint":        "To add a bit of context",
+
+		"error.mood.missing":      "Please pick a mood",
+		"error.feeling.mismatch":  "%s does not match the selected mood",
+		"error.context.too_long":  "Context must be under %d characters",
+		"error.generic":           "[ERROR] An error occured with your action please contact your admin. Info in the thread",
+		"error.message.empty":     "Message cannot be empty",
+		"error.chat.self":         "Pick someone else than yourself",
+		"error.mention.failed":    "Meow :crying_cat_face: %s",
+		"error.checkin.not_asked": "No daily mood has been asked yet today.",
+		"error.user.paused":       "<@%s> is away at the moment",
+
+		"mention.help.title":        "Here is what I understand:",
+		"mention.help.stats":        "• `@Simba stats` today's team mood",
+		"mention.help.missing":      "• `@Simba who hasn't answered` people who did not share their mood yet",
+		"mention.help.feeling":      "• `@Simba I'm feeling <feeling>` share your mood (%s)",
+		"mention.help.quotes":       "• `@Simba quotes <bundled|url|db> [source]` choose where quotes of the day come from (managers only)",
+		"mention.help.holidays":     "• `@Simba holidays [calendar url]` list upcoming holidays or import an ICS/CSV calendar (managers only)",
+		"mention.help.help":         "• `@Simba help` this message",
+		"mention.unknown":           "Meow :cat: I don't understand \"%s\".\n%s",
+		"mention.stats.none":        "Nobody shared their mood yet today.",
+		"mention.stats.title":       "Today's mood (%d answers):",
+		"mention.stats.off":         ":zzz: %d not working today",
+		"summary.weekly.title":      "This week's mood (%d answers):",
+		"summary.weekly.off":        ":zzz: %d days off this week",
+		"summary.chart.daily":       "Today's moods",
+		"summary.chart.weekly":      "Moods of the week of %s",
+		"summary.chart.off":         "not working",
+		"risk.alert":                ":warning: <@%s> might be going through a hard time:\n%s",
+		"risk.confidential":         ":lock: Confidential, only managers are told. A kind word usually helps more than a meeting.",
+		"risk.reach_out":            "Reach out",
+		"risk.reason.consecutive":   "%d bad moods in a row",
+		"risk.reason.drop":          "their last %d moods are well below their usual ones",
+		"risk.reason.feelings":      "often tired or frustrated over their last %d moods",
+		"home.risk.header":          "People who might need support",
+		"mention.missing.none":      "Everybody has shared their mood today :tada:",
+		"mention.missing.list":      "Still waiting for: %s",
+		"mention.feeling.done":      "Got it, you are feeling %s %s %s",
+		"mention.quotes.done":       "Quotes of the day will now come from %s :books:",
+		"mention.holidays.imported": "%d holidays imported, no daily mood will be asked on those days :palm_tree:",
+		"mention.holidays.none":     "No holiday planned in the next %d days.",
+		"mention.holidays.title":    "Upcoming holidays:",
+		"error.not_allowed":         "Only managers can do that",
+
+		"home.api.header":             "API tokens",
+		"home.api.create":             "New token",
+		"home.api.none":               "No API token yet, dashboards and scripts need one to read Simba data.",
+		"home.api.token":              "*%s* `%s…` %s\nCreated by <@%s>, last used: %s",
+		"home.api.never":              "never",
+		"home.api.revoke":             "Revoke",
+		"home.api.revoke.title":       "Revoke the token?",
+		"home.api.revoke.text":        "Everything using *%s* will stop working right away.",
+		"modal.api.title":             "New API token",
+		"modal.api.name":              "Name",
+		"modal.api.name.hint":         "What or who the token is for, e.g. Grafana",
+		"modal.api.scopes":            "Scopes",
+		"modal.api.scope.read:stats":  "Aggregated stats and daily sessions",
+		"modal.api.scope.read:moods":  "Users and each mood shared",
+		"modal.api.scope.write:moods": "Share moods on behalf of people",
+		"modal.api.submit":            "Create",
+		"modal.api.created":           "Here is the token *%s*. Copy it now, it will never be shown again :lock:",
+		"error.api.name":              "The token needs a name",
+		"error.api.scopes":            "Pick at least one scope",
+
+		"outreach.template.checkin":  "Hey %s! I haven't seen you around the daily mood lately, how are things going?",
+		"outreach.template.support":  "Hey %s, I noticed the last few days seem to have been tough. Do you want to grab a coffee and talk about it?",
+		"outreach.template.workload": "Hey %s, it looks like you've been pretty tired or frustrated lately. Is there anything on your plate I can help with?",
+		"outreach.template.kudos":    "Hey %s! Just wanted to say thank you, it's great to see you in such a good mood lately :tada:",
+		"outreach.template.neutral":  "Hey %s! Just checking in, is there anything you'd like to talk about?",
+		"outreach.modal.title":       "Send IM",
+		"outreach.modal.placeholder": "Write a kind message",
+		"outreach.modal.label":       "Message to %s",
+		"outreach.modal.hint":        "Will be sent as a direct message from Simba",
+		"outreach.sent":              "Your message has been sent to <@%s> :love_letter:",
+
+		"kind.modal.title":         "Your last days",
+		"kind.modal.days":          "*%s days*",
+		"kind.peer.label":          "Ask for a chat",
+		"kind.peer.placeholder":    "Pick a peer or your manager",
+		"kind.message.label":       "Message",
+		"kind.message.placeholder": "Anything you want to say first",
+		"kind.modal.submit":        "Ask for a chat",
+		"kind.request":             "Hey! <@%s> would like to have a chat with you :coffee:",
+		"kind.requested":           "<@%s> has been asked for a chat with you :heart:",
+
+		"home.title.member":              "Simba Application (Not Admin)",
+		"home.title.admin":               "Simba Application (Admin)",
+		"home.week":                      "Week informations",
+		"home.participation":             "Participation %.0f%% (%d/%d working days)",
+		"home.participation.team":        "*Team:* %s",
+		"participation.response":         "median answer %s after the daily post",
+		"home.pause.active":              ":palm_tree: Simba is paused for you until *%s* included",
+		"home.pause.status":              ":palm_tree: Your Slack status says you are away, Simba will leave you alone",
+		"home.pause.inactive":            "Going away? Pick the last day you are off and Simba will leave you alone",
+		"home.pause.placeholder":         "Paused until",
+		"home.pause.resume":              "I'm back",
+		"home.trend.header":              "Team mood trend",
+		"home.trend.window":              "%d weeks",
+		"home.trend.window.placeholder":  "Window",
+		"home.trend.error":               ":warning: The trend chart could not be rendered, check the logs",
+		"trend.title":                    "Team mood trend",
+		"trend.title.range":              "Team mood trend from %s to %s",
+		"trend.score":                    "Mood score (%d weeks rolling average)",
+		"trend.participation":            "Participation %",
+		"home.send_im":                   "Send IM",
+		"dashboard.team.title":           "Team",
+		"dashboard.login":                "Sign in with Slack",
+		"dashboard.logout":               "Sign out",
+		"dashboard.week":                 "Week of",
+		"dashboard.score":                "Mood score",
+		"dashboard.participation":        "Participation",
+		"dashboard.participation.none":   "Nobody was expected to answer lately.",
+		"dashboard.person":               "Person",
+		"dashboard.export":               "Export moods",
+		"dashboard.export.range":         "From %s to %s",
+		"dashboard.export.pseudonymised": "CSV, pseudonymised",
+		"dashboard.person.score":         "Mood score %s/100",
+		"dashboard.days_off":             "Days off",
+		"dashboard.history":              "History",
+		"dashboard.day":                  "Day",
+		"dashboard.newer":                "Newer",
+		"dashboard.older":                "Older",
+		"dashboard.forbidden.title":      "Reserved to managers",
+		"dashboard.forbidden.text":       "The dashboard is only open to managers and Slack admins.",
+		"dashboard.login.failed":         "Sign in failed",
+		"dashboard.login.state":          "The sign in expired or did not start here, please try again.",
+		"dashboard.login.denied":         "Slack did not let you sign in.",
+		"dashboard.login.error":          "Slack could not be reached, please try again later.",
+		"dashboard.login.team":           "Only the members of the Slack workspace of Simba can sign in.",
+		"dashboard.logout.title":         "Signed out",
+		"dashboard.logout.text":          "See you soon!",
+		"dashboard.person.unknown":       "Unknown person",
+		"dashboard.person.unknown.text":  "Nobody with this Slack id shared a mood yet.",
+
+		"home.webhooks.header":                 "Webhooks",
+		"home.webhooks.create":                 "Add a webhook",
+		"home.webhooks.none":                   "No webhook registered yet.",
+		"home.webhooks.webhook":                "`%s`\nEvents: %s, added by <@%s>",
+		"home.webhooks.all_events":             "all",
+		"home.webhooks.delete":                 "Remove",
+		"home.webhooks.delete.title":           "Remove the webhook?",
+		"home.webhooks.delete.text":            "`%s` will not receive any event anymore.",
+		"home.webhooks.deliveries":             "*Last deliveries*",
+		"home.webhooks.delivery":               "%s `%s` to %s on %s, %d attempt(s)",
+		"home.webhooks.delivery.error":         "Last error: %s",
+		"home.webhooks.removed":                "a removed webhook",
+		"home.webhooks.redeliver":              "Redeliver",
+		"modal.webhooks.title":                 "Webhook",
+		"modal.webhooks.url":                   "URL",
+		"modal.webhooks.url.hint":              "Events are sent as JSON POST requests signed with HMAC-SHA256 in the X-Simba-Signature header",
+		"modal.webhooks.events":                "Events",
+		"modal.webhooks.events.hint":           "Leave empty to receive every event",
+		"modal.webhooks.event.mood.recorded":   "Someone shared a mood or a day off",
+		"modal.webhooks.event.mood.updated":    "Someone changed their mood",
+		"modal.webhooks.event.poll.opened":     "The daily mood has been asked",
+		"modal.webhooks.event.poll.closed":     "The daily summary has been posted",
+		"modal.webhooks.event.alert.triggered": "Managers have been warned about a burnout risk",
+		"modal.webhooks.submit":                "Add",
+		"modal.webhooks.created":               "The webhook `%s` has been added. Here is the secret signing its deliveries, it will not be shown again:",
+		"error.webhooks.url":                   "Enter a public https URL (http and local URLs are only accepted outside production)",
+
+		"home.hierarchy.header":               "Hierarchy",
+		"home.hierarchy.edit":                 "Edit",
+		"home.hierarchy.none":                 "Nobody reports to anyone yet, managers see everyone until the hierarchy is set up.",
+		"home.hierarchy.manager":              "<@%s> manages %s",
+		"modal.hierarchy.title":               "Hierarchy",
+		"modal.hierarchy.report":              "Person",
+		"modal.hierarchy.report.placeholder":  "Pick someone",
+		"modal.hierarchy.manager":             "Reports to",
+		"modal.hierarchy.manager.hint":        "Leave empty so they report to nobody",
+		"modal.hierarchy.manager.placeholder": "Pick their manager",
+		"modal.hierarchy.submit":              "Save",
+		"error.hierarchy.unknown":             "Simba does not know this person yet, they need to share a mood first",
+		"error.hierarchy.self":                "Someone cannot be their own manager",
+		"error.hierarchy.cycle":               "This manager already reports to this person, directly or not",
+
+		"role.owner":                   "Owner",
+		"role.admin":                   "Admin",
+		"role.manager":                 "Manager",
+		"role.member":                  "Member",
+		"role.viewer":                  "Viewer",
+		"home.roles.header":            "Roles",
+		"home.roles.hint":              "People without a role assigned here get it from Slack: workspace owners and admins first, then managers of the hierarchy.",
+		"home.roles.edit":              "Assign a role",
+		"home.roles.none":              "No role assigned in Simba yet.",
+		"home.roles.assignment":        "<@%s> is *%s*",
+		"home.roles.history":           "*Recent changes*",
+		"home.roles.change":            "%s: <@%s> made <@%s> %s (was %s)",
+		"modal.roles.title":            "Roles",
+		"modal.roles.user":             "Person",
+		"modal.roles.user.placeholder": "Pick someone",
+		"modal.roles.role":             "Role",
+		"modal.roles.role.placeholder": "Pick a role",
+		"modal.roles.submit":           "Save",
+		"error.roles.unknown":          "Simba does not know this person yet, they need to share a mood first",
+		"error.roles.forbidden":        "Only owners can make or unmake owners and admins",
+
+		"summary.hidden":        "Fewer than %d people shared their mood, percentages are hidden to keep everyone anonymous",
+		"home.anonymity.team":   "Fewer than %d people shared their mood, team percentages are hidden to keep everyone anonymous.",
+		"home.anonymity.people": "Moods are only shown for groups of at least %d people, so they are hidden for each person.",
+
+		"home.email.header":             ":email: Emails sent to *%s*, the address of your Slack profile",
+		"home.email.missing":            ":email: Add an email address to your Slack profile to get Simba's digests by email",
+		"home.email.digest":             "Weekly digest",
+		"home.email.digest.hint":        "The moods of the team every week, as posted in the channel",
+		"home.email.alerts":             "Burnout risk alerts",
+		"home.email.alerts.hint":        "The confidential alerts about your reports, along with the Slack message",
+		"email.footer":                  "You get this email because you asked for it in the Home tab of Simba on Slack, where you can stop it.",
+		"email.dashboard":               "Open the dashboard",
+		"email.weekly_digest.subject":   "The mood of the team, week of %s",
+		"email.weekly_digest.answers":   "%d moods shared this week:",
+		"email.weekly_digest.off":       "%d days off this week",
+		"email.weekly_digest.none":      "Nobody shared their mood this week.",
+		"email.risk_alert.subject":      "%s might be going through a hard time",
+		"email.risk_alert.reach_out":    "A kind word usually helps more than a meeting, you can reach out from Slack.",
+		"email.risk_alert.person":       "See their moods on the dashboard",
+		"email.risk_alert.confidential": "Confidential, only managers are told. Please do not forward this email.",
+	},
+	"fr": {
+		"checkin.title":        "Salut tout le monde ! Quelle est votre humeur aujourd'hui :\nCitation du jour : *%s*",
+		"checkin.quote_author": "De %s",
+		"checkin.day_off":      "Je ne travaille pas :zzz:",
+		"checkin.users_off":    ":zzz: Ne travaillent pas aujourd'hui : %s",
+
+		"mood.label.good_mood":    "Bonne humeur :heart:",
+		"mood.label.average_mood": "Miaou :yellow_heart:",
+		"mood.label.bad_mood":     "Grr ! :black_heart:",
+		"mood.name.good_mood":     "bonne humeur",
+		"mood.name.average_mood":  "humeur moyenne",
+		"mood.name.bad_mood":      "mauvaise humeur",
+
+		"feeling.Excited":      "Enthousiaste",
+		"feeling.Happy":        "Heureux",
+		"feeling.Chilling":     "Détendu",
+		"feeling.Neutral":      "Neutre",
+		"feeling.Frustrated":   "Frustré",
+		"feeling.Tired":        "Fatigué",
+		"feeling.Sad":          "Triste",
+		"feeling.Mad":          "En colère",
+		"feeling.Disappointed": "Déçu",
+
+		"modal.cancel": "Annuler",
+		"modal.close":  "Fermer",
+		"modal.share":  "Partager",
+		"modal.send":   "Envoyer",
+
+		"modal.mood.title":          "Quelle est ton humeur",
+		"input.mood.label":          "Humeur",
+		"input.feeling.label":       "Ressenti",
+		"input.feeling.placeholder": "Comment te sens-tu",
+		"input.context.label":       "Contexte",
+		"input.context.hint":        "Pour ajouter un peu de contexte",
+
+		"error.mood.missing":      "Choisis une humeur",
+		"error.feeling.mismatch":  "%s ne correspond pas à l'humeur choisie",
+		"error.context.too_long":  "Le contexte doit faire moins de %d caractères",
+		"error.generic":           "[ERREUR] Une erreur est survenue lors de ton action, contacte ton admin. Plus d'infos dans le fil",
+		"error.message.empty":     "Le message ne peut pas être vide",
+		"error.chat.self":         "Choisis quelqu'un d'autre que toi",
+		"error.mention.failed":    "Miaou :crying_cat_face: %s",
+		"error.checkin.not_asked": "Aucune humeur n'a encore été demandée aujourd'hui.",
+		"error.user.paused":       "<@%s> est absent en ce moment",
+
+		"mention.help.title":        "Voici ce que je comprends :",
+		"mention.help.stats":        "• `@Simba stats` l'humeur de l'équipe aujourd'hui",
+		"mention.help.missing":      "• `@Simba qui n'a pas répondu` les personnes qui n'ont pas encore partagé leur humeur",
+		"mention.help.feeling":      "• `@Simba je me sens <ressenti>` partage ton humeur (%s)",
+		"mention.help.quotes":       "• `@Simba citations <bundled|url|db> [source]` choisis d'où viennent les citations du jour (managers uniquement)",
+		"mention.help.holidays":     "• `@Simba fériés [url du calendrier]` liste les prochains jours fériés ou importe un calendrier ICS/CSV (managers uniquement)",
+		"mention.help.help":         "• `@Simba aide` ce message",
+		"mention.unknown":           "Miaou :cat: Je ne comprends pas \"%s\".\n%s",
+		"mention.stats.none":        "Personne n'a encore partagé son humeur aujourd'hui.",
+		"mention.stats.title":       "Humeur du jour (%d réponses) :",
+		"mention.stats.off":         ":zzz: %d ne travaillent pas aujourd'hui",
+		"summary.weekly.title":      "Humeur de la semaine (%d réponses) :",
+		"summary.weekly.off":        ":zzz: %d jours non travaillés cette semaine",
+		"summary.chart.daily":       "Humeurs du jour",
+		"summary.chart.weekly":      "Humeurs de la semaine du %s",
+		"summary.chart.off":         "ne travaille pas",
+		"risk.alert":                ":warning: <@%s> traverse peut-être une période difficile :\n%s",
+		"risk.confidential":         ":lock: Confidentiel, seuls les managers sont prévenus. Un mot gentil aide souvent plus qu'une réunion.",
+		"risk.reach_out":            "Prendre des nouvelles",
+		"risk.reason.consecutive":   "%d mauvaises humeurs d'affilée",
+		"risk.reason.drop":          "ses %d dernières humeurs sont bien en dessous de d'habitude",
+		"risk.reason.feelings":      "souvent fatigué·e ou frustré·e sur ses %d dernières humeurs",
+		"home.risk.header":          "Personnes qui pourraient avoir besoin de soutien",
+		"mention.missing.none":      "Tout le monde a partagé son humeur aujourd'hui :tada:",
+		"mention.missing.list":      "En attente de : %s",
+		"mention.feeling.done":      "C'est noté, tu te sens %s %s %s",
+		"mention.quotes.done":       "Les citations du jour viendront maintenant de %s :books:",
+		"mention.holidays.imported": "%d jours fériés importés, aucune humeur ne sera demandée ces jours-là :palm_tree:",
+		"mention.holidays.none":     "Aucun jour férié prévu dans les %d prochains jours.",
+		"mention.holidays.title":    "Prochains jours fériés :",
+		"error.not_allowed":         "Seuls les managers peuvent faire ça",
+
+		"home.api.header":             "Tokens d'API",
+		"home.api.create":             "Nouveau token",
+		"home.api.none":               "Aucun token d'API, les dashboards et les scripts en ont besoin pour lire les données de Simba.",
+		"home.api.token":              "*%s* `%s…` %s\nCréé par <@%s>, dernière utilisation : %s",
+		"home.api.never":              "jamais",
+		"home.api.revoke":             "Révoquer",
+		"home.api.revoke.title":       "Révoquer le token ?",
+		"home.api.revoke.text":        "Tout ce qui utilise *%s* cessera de fonctionner immédiatement.",
+		"modal.api.title":             "Nouveau token d'API",
+		"modal.api.name":              "Nom",
+		"modal.api.name.hint":         "À quoi ou à qui sert le token, par exemple Grafana",
+		"modal.api.scopes":            "Droits",
+		"modal.api.scope.read:stats":  "Statistiques agrégées et sessions quotidiennes",
+		"modal.api.scope.read:moods":  "Utilisateurs et chaque humeur partagée",
+		"modal.api.scope.write:moods": "Partager des humeurs au nom des gens",
+		"modal.api.submit":            "Créer",
+		"modal.api.created":           "Voici le token *%s*. Copie-le maintenant, il ne sera plus jamais affiché :lock:",
+		"error.api.name":              "Le token a besoin d'un nom",
+		"error.api.scopes":            "Choisis au moins un droit",
+
+		"outreach.template.checkin":  "Salut %s ! Je ne t'ai pas vu sur l'humeur du jour ces derniers temps, comment ça va ?",
+		"outreach.template.support":  "Salut %s, j'ai l'impression que les derniers jours ont été difficiles. Tu veux prendre un café pour en parler ?",
+		"outreach.template.workload": "Salut %s, tu sembles assez fatigué ou frustré ces derniers temps. Est-ce que je peux t'aider sur quelque chose ?",
+		"outreach.template.kudos":    "Salut %s ! Je voulais juste te dire merci, ça fait plaisir de te voir d'aussi bonne humeur :tada:",
+		"outreach.template.neutral":  "Salut %s ! Je prends des nouvelles, est-ce qu'il y a quelque chose dont tu voudrais parler ?",
+		"outreach.modal.title":       "Envoyer un message",
+		"outreach.modal.placeholder": "Écris un message bienveillant",
+		"outreach.modal.label":       "Message pour %s",
+		"outreach.modal.hint":        "Sera envoyé en message privé par Simba",
+		"outreach.sent":              "Ton message a été envoyé à <@%s> :love_letter:",
+
+		"kind.modal.title":         "Tes derniers jours",
+		"kind.modal.days":          "*Jours %s*",
+		"kind.peer.label":          "Demander un échange",
+		"kind.peer.placeholder":    "Choisis un collègue ou ton manager",
+		"kind.message.label":       "Message",
+		"kind.message.placeholder": "Quelque chose à dire avant ?",
+		"kind.modal.submit":        "Demander un échange",
+		"kind.request":             "Salut ! <@%s> aimerait discuter avec toi :coffee:",
+		"kind.requested":           "<@%s> a reçu ta demande d'échange :heart:",
+
+		"home.title.member":              "Application Simba",
+		"home.title.admin":               "Application Simba (Admin)",
+		"home.week":                      "Informations de la semaine",
+		"home.participation":             "Participation %.0f%% (%d/%d jours ouvrés)",
+		"home.participation.team":        "*Équipe :* %s",
+		"participation.response":         "réponse médiane %s après le message du jour",
+		"home.pause.active":              ":palm_tree: Simba est en pause pour toi jusqu'au *%s* inclus",
+		"home.pause.status":              ":palm_tree: Ton statut Slack indique que tu es absent, Simba te laisse tranquille",
+		"home.pause.inactive":            "Tu pars ? Choisis ton dernier jour d'absence et Simba te laissera tranquille",
+		"home.pause.placeholder":         "En pause jusqu'au",
+		"home.pause.resume":              "Je suis de retour",
+		"home.trend.header":              "Tendance de l'humeur de l'équipe",
+		"home.trend.window":              "%d semaines",
+		"home.trend.window.placeholder":  "Période",
+		"home.trend.error":               ":warning: Le graphique de tendance n'a pas pu être généré, regarde les logs",
+		"trend.title":                    "Tendance de l'humeur",
+		"trend.title.range":              "Tendance de l'humeur du %s au %s",
+		"trend.score":                    "Score d'humeur (moyenne glissante sur %d semaines)",
+		"trend.participation":            "Participation %",
+		"home.send_im":                   "Envoyer un message",
+		"dashboard.team.title":           "Équipe",
+		"dashboard.login":                "Se connecter avec Slack",
+		"dashboard.logout":               "Se déconnecter",
+		"dashboard.week":                 "Semaine du",
+		"dashboard.score":                "Score d'humeur",
+		"dashboard.participation":        "Participation",
+		"dashboard.participation.none":   "Personne n'était attendu récemment.",
+		"dashboard.person":               "Personne",
+		"dashboard.export":               "Exporter les humeurs",
+		"dashboard.export.range":         "Du %s au %s",
+		"dashboard.export.pseudonymised": "CSV, pseudonymisé",
+		"dashboard.person.score":         "Score d'humeur %s/100",
+		"dashboard.days_off":             "Jours off",
+		"dashboard.history":              "Historique",
+		"dashboard.day":                  "Jour",
+		"dashboard.newer":                "Plus récents",
+		"dashboard.older":                "Plus anciens",
+		"dashboard.forbidden.title":      "Réservé aux managers",
+		"dashboard.forbidden.text":       "Le tableau de bord n'est ouvert qu'aux managers et aux admins Slack.",
+		"dashboard.login.failed":         "Connexion impossible",
+		"dashboard.login.state":          "La connexion a expiré ou n'a pas commencé ici, merci de réessayer.",
+		"dashboard.login.denied":         "Slack n'a pas autorisé la connexion.",
+		"dashboard.login.error":          "Slack est injoignable, merci de réessayer plus tard.",
+		"dashboard.login.team":           "Seuls les membres de l'espace de travail Slack de Simba peuvent se connecter.",
+		"dashboard.logout.title":         "Déconnecté",
+		"dashboard.logout.text":          "À bientôt !",
+		"dashboard.person.unknown":       "Personne inconnue",
+		"dashboard.person.unknown.text":  "Personne avec cet id Slack n'a encore partagé d'humeur.",
+
+		"weekday.Monday":    "Lundi",
+		"weekday.Tuesday":   "Mardi",
+		"weekday.Wednesday": "Mercredi",
+		"weekday.Thursday":  "Jeudi",
+		"weekday.Friday":    "Vendredi",
+		"weekday.Saturday":  "Samedi",
+		"weekday.Sunday":    "Dimanche",
+		"month.January":     "janvier",
+		"month.February":    "février",
+		"month.March":       "mars",
+		"month.April":       "avril",
+		"month.May":         "mai",
+		"month.June":        "juin",
+		"month.July":        "juillet",
+		"month.August":      "août",
+		"month.September":   "septembre",
+		"month.October":     "octobre",
+		"month.November":    "novembre",
+		"month.December":    "décembre",
+
+		"home.webhooks.header":                 "Webhooks",
+		"home.webhooks.create":                 "Ajouter un webhook",
+		"home.webhooks.none":                   "Aucun webhook enregistré pour l'instant.",
+		"home.webhooks.webhook":                "`%s`\nÉvénements : %s, ajouté par <@%s>",
+		"home.webhooks.all_events":             "tous",
+		"home.webhooks.delete":                 "Supprimer",
+		"home.webhooks.delete.title":           "Supprimer le webhook ?",
+		"home.webhooks.delete.text":            "`%s` ne recevra plus aucun événement.",
+		"home.webhooks.deliveries":             "*Derniers envois*",
+		"home.webhooks.delivery":               "%s `%s` vers %s le %s, %d tentative(s)",
+		"home.webhooks.delivery.error":         "Dernière erreur : %s",
+		"home.webhooks.removed":                "un webhook supprimé",
+		"home.webhooks.redeliver":              "Renvoyer",
+		"modal.webhooks.title":                 "Webhook",
+		"modal.webhooks.url":                   "URL",
+		"modal.webhooks.url.hint":              "Les événements sont envoyés en POST JSON signés en HMAC-SHA256 dans l'en-tête X-Simba-Signature",
+		"modal.webhooks.events":                "Événements",
+		"modal.webhooks.events.hint":           "Laisse vide pour recevoir tous les événements",
+		"modal.webhooks.event.mood.recorded":   "Quelqu'un a partagé son humeur ou un jour off",
+		"modal.webhooks.event.mood.updated":    "Quelqu'un a changé son humeur",
+		"modal.webhooks.event.poll.opened":     "L'humeur du jour a été demandée",
+		"modal.webhooks.event.poll.closed":     "Le résumé du jour a été publié",
+		"modal.webhooks.event.alert.triggered": "Des managers ont été prévenus d'un risque d'épuisement",
+		"modal.webhooks.submit":                "Ajouter",
+		"modal.webhooks.created":               "Le webhook `%s` a été ajouté. Voici le secret qui signe ses envois, il ne sera plus affiché :",
+		"error.webhooks.url":                   "Saisis une URL https publique (les URL http et locales ne sont acceptées qu'en dehors de la production)",
+
+		"home.hierarchy.header":               "Hiérarchie",
+		"home.hierarchy.edit":                 "Modifier",
+		"home.hierarchy.none":                 "Personne ne dépend de personne, les managers voient tout le monde tant que la hiérarchie n'est pas définie.",
+		"home.hierarchy.manager":              "<@%s> manage %s",
+		"modal.hierarchy.title":               "Hiérarchie",
+		"modal.hierarchy.report":              "Personne",
+		"modal.hierarchy.report.placeholder":  "Choisis quelqu'un",
+		"modal.hierarchy.manager":             "Rattaché à",
+		"modal.hierarchy.manager.hint":        "Laisse vide pour ne rattacher à personne",
+		"modal.hierarchy.manager.placeholder": "Choisis son manager",
+		"modal.hierarchy.submit":              "Enregistrer",
+		"error.hierarchy.unknown":             "Simba ne connaît pas encore cette personne, elle doit d'abord partager une humeur",
+		"error.hierarchy.self":                "On ne peut pas être son propre manager",
+		"error.hierarchy.cycle":               "Ce manager dépend déjà de cette personne, directement ou non",
+
+		"role.owner":                   "Propriétaire",
+		"role.admin":                   "Administrateur",
+		"role.manager":                 "Manager",
+		"role.member":                  "Membre",
+		"role.viewer":                  "Observateur",
+		"home.roles.header":            "Rôles",
+		"home.roles.hint":              "Sans rôle attribué ici, le rôle vient de Slack : propriétaires et administrateurs de l'espace d'abord, puis managers de la hiérarchie.",
+		"home.roles.edit":              "Attribuer un rôle",
+		"home.roles.none":              "Aucun rôle attribué dans Simba pour l'instant.",
+		"home.roles.assignment":        "<@%s> est *%s*",
+		"home.roles.history":           "*Derniers changements*",
+		"home.roles.change":            "%s : <@%s> a rendu <@%s> %s (avant %s)",
+		"modal.roles.title":            "Rôles",
+		"modal.roles.user":             "Personne",
+		"modal.roles.user.placeholder": "Choisis quelqu'un",
+		"modal.roles.role":             "Rôle",
+		"modal.roles.role.placeholder": "Choisis un rôle",
+		"modal.roles.submit":           "Enregistrer",
+		"error.roles.unknown":          "Simba ne connaît pas encore cette personne, elle doit d'abord partager une humeur",
+		"error.roles.forbidden":        "Seuls les propriétaires peuvent nommer ou retirer propriétaires et administrateurs",
+
+		"summary.hidden":        "Moins de %d personnes ont partagé leur humeur, les pourcentages sont masqués pour préserver l'anonymat",
+		"home.anonymity.team":   "Moins de %d personnes ont partagé leur humeur, les pourcentages de l'équipe sont masqués pour préserver l'anonymat.",
+		"home.anonymity.people": "Les humeurs ne sont montrées que pour des groupes d'au moins %d personnes, elles sont donc masquées pour chaque personne.",
+
+		"home.email.header":             ":email: Emails envoyés à *%s*, l'adresse de ton profil Slack",
+		"home.email.missing":            ":email: Ajoute une adresse email à ton profil Slack pour recevoir les résumés de Simba par email",
+		"home.email.digest":             "Résumé de la semaine",
+		"home.email.digest.hint":        "L'humeur de l'équipe chaque semaine, comme dans le canal",
+		"home.email.alerts":             "Alertes de risque d'épuisement",
+		"home.email.alerts.hint":        "Les alertes confidentielles sur ton équipe, en plus du message Slack",
+		"email.footer":                  "Tu reçois cet email car tu l'as demandé dans l'onglet Accueil de Simba sur Slack, où tu peux l'arrêter.",
+		"email.dashboard":               "Ouvrir le tableau de bord",
+		"email.weekly_digest.subject":   "L'humeur de l'équipe, semaine du %s",
+		"email.weekly_digest.answers":   "%d humeurs partagées cette semaine :",
+		"email.weekly_digest.off":       "%d jours non travaillés cette semaine",
+		"email.weekly_digest.none":      "Personne n'a partagé son humeur cette semaine.",
+		"email.risk_alert.subject":      "%s traverse peut-être une période difficile",
+		"email.risk_alert.reach_out":    "Un mot gentil aide souvent plus qu'une réunion, tu peux prendre des nouvelles depuis Slack.",
+		"email.risk_alert.person":       "Voir ses humeurs sur le tableau de bord",
+		"email.risk_alert.confidential": "Confidentiel, seuls les managers sont prévenus. Merci de ne pas transférer cet email.",
+	},
+}
+
+// NormalizeLocale turns a Slack locale (ie: fr-FR) into a supported catalog locale.
+func NormalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.SplitN(strings.ReplaceAll(locale, "_", "-"), "-", 2)[0])
+	if _, ok := catalog[locale]; ok {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// T returns the message of the catalog for key in the given locale, formatted with args.
+// It falls back on DefaultLocale and then on the key itself.
+func T(locale, key string, args ...interface{}) string {
+	text, ok := catalog[NormalizeLocale(locale)][key]
+	if !ok {
+		if text, ok = catalog[DefaultLocale][key]; !ok {
+			return key
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// FormatDay renders a date as "Monday 02 January" in the given locale.
+func FormatDay(t time.Time, locale string) string {
+	locale = NormalizeLocale(locale)
+	weekday, hasWeekday := catalog[locale]["weekday."+t.Weekday().String()]
+	month, hasMonth := catalog[locale]["month."+t.Month().String()]
+	if !hasWeekday || !hasMonth {
+		return t.Format("Monday 02 January")
+	}
+	return fmt.Sprintf("%s %02d %s", weekday, t.Day(), month)
+}
+
+// FetchUserLocale returns the catalog locale of a Slack user from its profile.
+func FetchUserLocale(slackClient *slack.Client, userId string) string {
+	slackUser, err := FetchUserById(slackClient, userId)
+	if err != nil {
+		log.Printf("Cannot fetch locale of %s : %s", userId, err.Error())
+		return DefaultLocale
+	}
+	return NormalizeLocale(slackUser.Locale)
+}
@@ -0,0 +1,44 @@
+package simba_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeLocale(t *testing.T) {
+	assert.Equal(t, "fr", simba.NormalizeLocale("fr-FR"))
+	assert.Equal(t, "en", simba.NormalizeLocale("en-US"))
+	assert.Equal(t, simba.DefaultLocale, simba.NormalizeLocale("ja-JP"))
+	assert.Equal(t, simba.DefaultLocale, simba.NormalizeLocale(""))
+}
+
+func TestT(t *testing.T) {
+	assert.Equal(t, "Context", simba.T("en-US", "input.context.label"))
+	assert.Equal(t, "Contexte", simba.T("fr-FR", "input.context.label"))
+	assert.Equal(t, "From Simba", simba.T("en", "checkin.quote_author", "Simba"))
+	assert.Equal(t, "De Simba", simba.T("fr", "checkin.quote_author", "Simba"))
+	assert.Equal(t, "Context", simba.T("ja-JP", "input.context.label"))
+	assert.Equal(t, "unknown.key", simba.T("fr", "unknown.key"))
+}
+
+func TestFormatDay(t *testing.T) {
+	day := time.Date(2021, time.November, 15, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, "Monday 15 November", simba.FormatDay(day, "en"))
+	assert.Equal(t, "Lundi 15 novembre", simba.FormatDay(day, "fr-FR"))
+}
+
+func TestParseMentionCommandFrench(t *testing.T) {
+	assert.Equal(t, simba.MentionCommandHelp, simba.ParseMentionCommand("<@U0SIMBA> aide").Kind)
+	assert.Equal(t, simba.MentionCommandMissing, simba.ParseMentionCommand("<@U0SIMBA> qui n'a pas répondu ?").Kind)
+
+	cmd := simba.ParseMentionCommand("<@U0SIMBA> je me sens fatigué")
+	assert.Equal(t, simba.MentionCommandFeeling, cmd.Kind)
+	assert.Equal(t, "Tired", cmd.Feeling)
+
+	cmd = simba.ParseMentionCommand("<@U0SIMBA> je suis en colère")
+	assert.Equal(t, simba.MentionCommandFeeling, cmd.Kind)
+	assert.Equal(t, "Mad", cmd.Feeling)
+}
@@ -0,0 +1,298 @@
+package simba
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+const (
+	EmailWeeklyDigest = "weekly_digest"
+	EmailRiskAlert    = "risk_alert"
+)
+
+// mailTemplates holds an HTML and a text template for each email, the HTML ones sharing mail/layout.html.
+//
+//go:embed mail/*
+var mailTemplates embed.FS
+
+var mailFuncs = map[string]interface{}{
+	"t": T,
+	"percent": func(value float64) string {
+		return fmt.Sprintf("%.0f%%", value)
+	},
+	"participation": func(locale string, stats *ParticipationStats) string {
+		return ParticipationText(*stats, locale)
+	},
+}
+
+// Email is an email ready to be sent, with a text and an HTML version of the same content.
+type Email struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+// emailData is what every email template is given, Data being the content of the email.
+type emailData struct {
+	Locale  string
+	Subject string
+	Data    interface{}
+}
+
+// RenderEmail renders the templates of the email name in locale, To being left to the caller.
+func RenderEmail(name, locale, subject string, data interface{}) (*Email, error) {
+	content := emailData{Locale: locale, Subject: subject, Data: data}
+
+	htmlTemplate, err := htmltemplate.New(name).Funcs(mailFuncs).ParseFS(
+		mailTemplates,
+		"mail/layout.html",
+		fmt.Sprintf("mail/%s.html", name),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var html bytes.Buffer
+	if err := htmlTemplate.ExecuteTemplate(&html, "layout", content); err != nil {
+		return nil, err
+	}
+
+	textTemplate, err := texttemplate.New(name+".txt").Funcs(mailFuncs).ParseFS(
+		mailTemplates,
+		fmt.Sprintf("mail/%s.txt", name),
+	)
+	if err != nil {
+		return nil, err
+	}
+	var text bytes.Buffer
+	if err := textTemplate.Execute(&text, content); err != nil {
+		return nil, err
+	}
+
+	return &Email{Subject: subject, Text: text.String(), HTML: html.String()}, nil
+}
+
+// Message is the MIME message of the email sent by from at date, the text and HTML versions
+// being alternatives of each other.
+func (email *Email) Message(from string, date time.Time) ([]byte, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender %q : %w", from, err)
+	}
+	recipient, err := mail.ParseAddress(email.To)
+	if err != nil {
+		return nil, fmt.Errorf("invalid recipient %q : %w", email.To, err)
+	}
+
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, alternative := range []struct{ contentType, content string }{
+		{"text/plain", email.Text},
+		{"text/html", email.HTML},
+	} {
+		part, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {alternative.contentType + "; charset=utf-8"},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		encoder := quotedprintable.NewWriter(part)
+		if _, err := encoder.Write([]byte(alternative.content)); err != nil {
+			return nil, err
+		} else if err := encoder.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	var message bytes.Buffer
+	for _, header := range [][2]string{
+		{"From", sender.String()},
+		{"To", recipient.String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", email.Subject)},
+		{"Date", date.Format(time.RFC1123Z)},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", parts.Boundary())},
+	} {
+		fmt.Fprintf(&message, "%s: %s\r\n", header[0], header[1])
+	}
+	message.WriteString("\r\n")
+	message.Write(body.Bytes())
+	return message.Bytes(), nil
+}
+
+// SendEmail sends email through the SMTP server of config.
+func SendEmail(config *SmtpConfig, email *Email) error {
+	if !config.Enabled() {
+		return fmt.Errorf("no SMTP server configured")
+	}
+	message, err := email.Message(config.From, time.Now())
+	if err != nil {
+		return err
+	}
+	// Both have been parsed by Message already
+	sender, _ := mail.ParseAddress(config.From)
+	recipient, _ := mail.ParseAddress(email.To)
+
+	var auth smtp.Auth
+	if config.Username != "" {
+		auth = smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	}
+	address := net.JoinHostPort(config.Host, strconv.Itoa(config.Port))
+	return smtp.SendMail(address, auth, sender.Address, []string{recipient.Address}, message)
+}
+
+// SetEmailPreferences records the emails a user wants, registering the user if unknown.
+func SetEmailPreferences(
+	dbClient *gorm.DB,
+	channelId, slackUserId, userName string,
+	digest, alerts bool,
+) (*User, error) {
+	user := User{SlackUserID: slackUserId, SlackChannelId: channelId, Username: userName}
+	if tx := dbClient.FirstOrInit(&user, "slack_user_id = ?", slackUserId); tx.Error != nil {
+		return nil, tx.Error
+	}
+	user.EmailDigest = digest
+	user.EmailAlerts = alerts
+	if tx := dbClient.Save(&user); tx.Error != nil {
+		return nil, tx.Error
+	}
+	return &user, nil
+}
+
+// MoodShare is the percentage of the answers of a digest being mood.
+type MoodShare struct {
+	Mood    string
+	Percent float64
+}
+
+// WeeklyDigest is the content of the weekly digest email.
+type WeeklyDigest struct {
+	WeekStart time.Time
+	Answers   int
+	// Hidden is set when fewer than MinGroupSize people shared a mood, Shares being empty then
+	Hidden       bool
+	MinGroupSize int
+	Shares       []MoodShare
+	DaysOff      int
+	// Participation of the team, nil when unknown
+	Participation *ParticipationStats
+	DashboardURL  string
+}
+
+// NewWeeklyDigest sums up the moods of the week starting at weekStart, hiding the percentages
+// when fewer than minGroupSize people shared a mood.
+func NewWeeklyDigest(moods []DailyMood, weekStart time.Time, minGroupSize int) WeeklyDigest {
+	users := []*User{{Moods: moods}}
+	moodCount, total := CountMoods(users)
+	digest := WeeklyDigest{
+		WeekStart:    weekStart,
+		Answers:      total,
+		Hidden:       total > 0 && !IsAnonymous(CountPeopleWithMood(moods), minGroupSize),
+		MinGroupSize: minGroupSize,
+		Shares:       []MoodShare{},
+		DaysOff:      CountDaysOff(users),
+	}
+	if total == 0 || digest.Hidden {
+		return digest
+	}
+	for _, mood := range Moods {
+		digest.Shares = append(digest.Shares, MoodShare{
+			Mood:    mood,
+			Percent: float64(moodCount[mood]) / float64(total) * 100,
+		})
+	}
+	return digest
+}
+
+// SendWeeklyDigestEmails emails digest to the people who asked for it, in their language.
+// People without an email address in their Slack profile are skipped.
+func SendWeeklyDigestEmails(dbClient *gorm.DB, client *slack.Client, config *Config, digest WeeklyDigest) error {
+	if !config.SMTP.Enabled() {
+		return nil
+	}
+	var users []*User
+	if tx := dbClient.Where("email_digest = ?", true).Find(&users); tx.Error != nil {
+		return tx.Error
+	}
+
+	for _, u := range users {
+		slackUser, err := FetchUserById(client, u.SlackUserID)
+		if err != nil {
+			log.Printf("Cannot fetch %s to email the weekly digest : %s", u.SlackUserID, err.Error())
+			continue
+		} else if slackUser.Profile.Email == "" {
+			log.Printf("%s asked for the weekly digest but has no email address", u.SlackUserID)
+			continue
+		}
+		locale := NormalizeLocale(slackUser.Locale)
+		subject := T(locale, "email.weekly_digest.subject", FormatDay(digest.WeekStart, locale))
+		email, err := RenderEmail(EmailWeeklyDigest, locale, subject, digest)
+		if err != nil {
+			return err
+		}
+		email.To = slackUser.Profile.Email
+		if err := SendEmail(config.SMTP, email); err != nil {
+			log.Printf("Cannot email the weekly digest to %s : %s", u.SlackUserID, err.Error())
+		}
+	}
+	return nil
+}
+
+// RiskAlertEmail is the content of the email warning a manager about someone.
+type RiskAlertEmail struct {
+	Username string
+	Reasons  []string
+	// PersonURL is the page of the person on the dashboard, empty without dashboard
+	PersonURL string
+}
+
+// sendRiskAlertEmail emails the alert about risk to manager if they asked for it, telling if it has been sent.
+func sendRiskAlertEmail(client *slack.Client, config *Config, risk BurnoutRisk, manager *User) (bool, error) {
+	if !config.SMTP.Enabled() || !manager.EmailAlerts {
+		return false, nil
+	}
+	slackManager, err := FetchUserById(client, manager.SlackUserID)
+	if err != nil {
+		return false, err
+	} else if slackManager.Profile.Email == "" {
+		return false, fmt.Errorf("%s has no email address", manager.SlackUserID)
+	}
+
+	locale := NormalizeLocale(slackManager.Locale)
+	data := RiskAlertEmail{
+		Username: risk.User.Username,
+		Reasons:  RiskReasons(risk.Reasons, config.RISK, locale),
+	}
+	if config.DASHBOARD_URL != "" {
+		data.PersonURL = config.DASHBOARD_URL + "/dashboard/people/" + risk.User.SlackUserID
+	}
+	email, err := RenderEmail(EmailRiskAlert, locale, T(locale, "email.risk_alert.subject", risk.User.Username), data)
+	if err != nil {
+		return false, err
+	}
+	email.To = slackManager.Profile.Email
+	if err := SendEmail(config.SMTP, email); err != nil {
+		return false, err
+	}
+	return true, nil
+}
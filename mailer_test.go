@@ -0,0 +1,223 @@
+package simba_test
+
+import (
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+)
+
+// smtpMessage is a message received by the SMTP stand-in.
+type smtpMessage struct {
+	Auth string
+	From string
+	To   []string
+	Data string
+}
+
+// newSMTPStandIn starts a local SMTP server accepting PLAIN authentication and keeping the
+// messages it receives, and returns its port.
+func newSMTPStandIn(t *testing.T) (int, chan smtpMessage) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan smtpMessage, 1)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(conn, messages)
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port, messages
+}
+
+func serveSMTP(conn net.Conn, messages chan smtpMessage) {
+	defer conn.Close()
+	text := textproto.NewConn(conn)
+	text.PrintfLine("220 localhost SMTP stand-in")
+	message := smtpMessage{}
+	for {
+		line, err := text.ReadLine()
+		if err != nil {
+			return
+		}
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			text.PrintfLine("250-localhost")
+			text.PrintfLine("250 AUTH PLAIN")
+		case strings.HasPrefix(command, "AUTH PLAIN "):
+			credentials, _ := base64.StdEncoding.DecodeString(line[len("AUTH PLAIN "):])
+			message.Auth = string(credentials)
+			text.PrintfLine("235 Authenticated")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			message.From = strings.Trim(line[len("MAIL FROM:"):], "<>")
+			text.PrintfLine("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			message.To = append(message.To, strings.Trim(line[len("RCPT TO:"):], "<>"))
+			text.PrintfLine("250 OK")
+		case command == "DATA":
+			text.PrintfLine("354 Go ahead")
+			data, err := text.ReadDotBytes()
+			if err != nil {
+				return
+			}
+			message.Data = string(data)
+			messages <- message
+			text.PrintfLine("250 OK")
+		case command == "QUIT":
+			text.PrintfLine("221 Bye")
+			return
+		default:
+			text.PrintfLine("250 OK")
+		}
+	}
+}
+
+// readEmailParts parses a MIME message and returns its decoded parts by content type.
+func readEmailParts(t *testing.T, data string) (*mail.Message, map[string]string) {
+	message, err := mail.ReadMessage(strings.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	assert.Nil(t, err)
+	assert.Equal(t, "multipart/alternative", mediaType)
+
+	parts := map[string]string{}
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		content, _ := io.ReadAll(part)
+		contentType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[contentType] = string(content)
+	}
+	return message, parts
+}
+
+func fakeWeekMoods(moods ...string) []simba.DailyMood {
+	dailyMoods := []simba.DailyMood{}
+	for idx, mood := range moods {
+		dailyMoods = append(dailyMoods, simba.DailyMood{UserID: uint(idx + 1), Mood: mood})
+	}
+	return dailyMoods
+}
+
+func TestNewWeeklyDigest(t *testing.T) {
+	weekStart := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	moods := append(fakeWeekMoods("good_mood", "good_mood", "bad_mood", "average_mood"), simba.DailyMood{
+		UserID: 1,
+		Status: simba.DailyMoodStatusOff,
+	})
+
+	digest := simba.NewWeeklyDigest(moods, weekStart, 3)
+	assert.False(t, digest.Hidden)
+	assert.Equal(t, 4, digest.Answers)
+	assert.Equal(t, 1, digest.DaysOff)
+	assert.Equal(t, []simba.MoodShare{
+		{Mood: "good_mood", Percent: 50},
+		{Mood: "average_mood", Percent: 25},
+		{Mood: "bad_mood", Percent: 25},
+	}, digest.Shares)
+
+	digest = simba.NewWeeklyDigest(fakeWeekMoods("good_mood", "bad_mood"), weekStart, 3)
+	assert.True(t, digest.Hidden)
+	assert.Empty(t, digest.Shares)
+}
+
+func TestRenderEmailWeeklyDigest(t *testing.T) {
+	weekStart := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	digest := simba.NewWeeklyDigest(fakeWeekMoods("good_mood", "good_mood", "bad_mood", "average_mood"), weekStart, 3)
+	digest.DashboardURL = "https://simba.example.com"
+
+	email, err := simba.RenderEmail(simba.EmailWeeklyDigest, "en", "The mood of the team", digest)
+	assert.Nil(t, err)
+	assert.Equal(t, "The mood of the team", email.Subject)
+	assert.Contains(t, email.Text, "- good mood : 50%")
+	assert.Contains(t, email.Text, "https://simba.example.com/dashboard")
+	assert.Contains(t, email.HTML, `<a href="https://simba.example.com/dashboard">`)
+	assert.Contains(t, email.HTML, "50%")
+
+	hidden := simba.NewWeeklyDigest(fakeWeekMoods("good_mood", "bad_mood"), weekStart, 3)
+	email, err = simba.RenderEmail(simba.EmailWeeklyDigest, "en", "The mood of the team", hidden)
+	assert.Nil(t, err)
+	assert.Contains(t, email.Text, "Fewer than 3 people shared their mood")
+	assert.NotContains(t, email.Text, "%")
+	assert.NotContains(t, email.HTML, "bad mood")
+}
+
+func TestRenderEmailRiskAlertEscapesHTML(t *testing.T) {
+	alert := simba.RiskAlertEmail{Username: "<b>Tom</b>", Reasons: []string{"3 bad moods in a row"}}
+	email, err := simba.RenderEmail(simba.EmailRiskAlert, "en", "Tom might be going through a hard time", alert)
+	assert.Nil(t, err)
+	assert.Contains(t, email.HTML, "<li>3 bad moods in a row</li>")
+	assert.NotContains(t, email.HTML, "<b>Tom</b>")
+	assert.Contains(t, email.Text, "- 3 bad moods in a row")
+	assert.NotContains(t, email.Text, "dashboard")
+}
+
+func TestEmailMessageInvalidAddress(t *testing.T) {
+	email := &simba.Email{To: "not an address", Subject: "Hello"}
+	_, err := email.Message("Simba <simba@example.com>", time.Now())
+	assert.ErrorContains(t, err, "invalid recipient")
+
+	email.To = "lea@example.com"
+	_, err = email.Message("", time.Now())
+	assert.ErrorContains(t, err, "invalid sender")
+}
+
+func TestSendEmailDisabled(t *testing.T) {
+	assert.Error(t, simba.SendEmail(&simba.SmtpConfig{}, &simba.Email{To: "lea@example.com"}))
+	assert.Error(t, simba.SendEmail(nil, &simba.Email{To: "lea@example.com"}))
+}
+
+func TestSendEmail(t *testing.T) {
+	port, messages := newSMTPStandIn(t)
+	config := &simba.SmtpConfig{
+		Host:     "127.0.0.1",
+		Port:     port,
+		Username: "simba",
+		Password: "secret",
+		From:     "Simba <simba@example.com>",
+	}
+	email := &simba.Email{
+		To:      "Léa <lea@example.com>",
+		Subject: "L'humeur de l'équipe",
+		Text:    "Bonne humeur : 50%",
+		HTML:    "<p>Bonne humeur : <b>50%</b></p>",
+	}
+
+	assert.Nil(t, simba.SendEmail(config, email))
+	received := <-messages
+	assert.Equal(t, "\x00simba\x00secret", received.Auth)
+	assert.Equal(t, "simba@example.com", received.From)
+	assert.Equal(t, []string{"lea@example.com"}, received.To)
+
+	message, parts := readEmailParts(t, received.Data)
+	subject, err := new(mime.WordDecoder).DecodeHeader(message.Header.Get("Subject"))
+	assert.Nil(t, err)
+	assert.Equal(t, "L'humeur de l'équipe", subject)
+	assert.Equal(t, `"Simba" <simba@example.com>`, message.Header.Get("From"))
+	assert.Equal(t, "Bonne humeur : 50%", parts["text/plain"])
+	assert.Equal(t, "<p>Bonne humeur : <b>50%</b></p>", parts["text/html"])
+}
@@ -0,0 +1,241 @@
+package simba
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"gorm.io/gorm"
+)
+
+const (
+	SlackOutcomeOK             = "ok"
+	SlackOutcomeSlackError     = "slack_error"
+	SlackOutcomeRateLimited    = "rate_limited"
+	SlackOutcomeHTTPError      = "http_error"
+	SlackOutcomeTransportError = "transport_error"
+
+	// SessionKindHTTP counts the HTTP requests being served
+	SessionKindHTTP = "http"
+	// SessionKindDashboard counts the people signed in on the dashboard
+	SessionKindDashboard = "dashboard"
+
+	dbTimerKey = "simba:metrics_start"
+)
+
+// MetricsRegistry holds every metric of Simba, it is the one served on /metrics.
+var MetricsRegistry = prometheus.NewRegistry()
+
+var (
+	slackAPICalls = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "simba_slack_api_calls_total",
+		Help: "Slack API calls by method and outcome.",
+	}, []string{"method", "outcome"})
+	slackAPIDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "simba_slack_api_call_duration_seconds",
+		Help:    "Duration of the Slack API calls by method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+	interactionDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "simba_interaction_duration_seconds",
+		Help:    "Time spent handling Slack interactions by type and outcome.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"type", "outcome"})
+	schedulerRuns = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "simba_scheduler_runs_total",
+		Help: "Scheduled job runs by job.",
+	}, []string{"job"})
+	schedulerFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "simba_scheduler_failures_total",
+		Help: "Scheduled job runs which returned an error, by job.",
+	}, []string{"job"})
+	moodsRecorded = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "simba_moods_recorded_total",
+		Help: "Moods recorded by mood, days off being counted as off.",
+	}, []string{"mood"})
+	dbQueryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "simba_db_query_duration_seconds",
+		Help:    "Duration of the database queries by operation.",
+		Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation"})
+	activeSessions = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "simba_active_sessions",
+		Help: "Sessions currently open by kind.",
+	}, []string{"kind"})
+	httpRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "simba_http_requests_total",
+		Help: "HTTP requests by method, route and status code.",
+	}, []string{"method", "route", "code"})
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "simba_http_request_duration_seconds",
+		Help:    "Duration of the HTTP requests by method and route.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+func init() {
+	MetricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		slackAPICalls,
+		slackAPIDuration,
+		interactionDuration,
+		schedulerRuns,
+		schedulerFailures,
+		moodsRecorded,
+		dbQueryDuration,
+		activeSessions,
+		httpRequests,
+		httpRequestDuration,
+	)
+}
+
+// outcome is the label of a handler result.
+func outcome(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
+
+// SlackAPIMethod is the label of a request made by the Slack client, file uploads going
+// to URLs unique per file they are all labelled file_upload.
+func SlackAPIMethod(u *url.URL) string {
+	if method, found := strings.CutPrefix(u.Path, "/api/"); found && method != "" {
+		return method
+	}
+	return "file_upload"
+}
+
+// slackOutcome tells how a Slack call went, Slack answering 200 with ok false on errors.
+func slackOutcome(resp *http.Response, err error) string {
+	if err != nil {
+		return SlackOutcomeTransportError
+	} else if resp.StatusCode == http.StatusTooManyRequests {
+		return SlackOutcomeRateLimited
+	} else if resp.StatusCode >= http.StatusBadRequest {
+		return SlackOutcomeHTTPError
+	} else if !strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		return SlackOutcomeOK
+	}
+
+	body, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if readErr != nil {
+		return SlackOutcomeTransportError
+	}
+	var answer struct {
+		Ok *bool `json:"ok"`
+	}
+	if json.Unmarshal(body, &answer) == nil && answer.Ok != nil && !*answer.Ok {
+		return SlackOutcomeSlackError
+	}
+	return SlackOutcomeOK
+}
+
+// slackMetricsTransport counts and times the calls of the Slack client.
+type slackMetricsTransport struct {
+	next http.RoundTripper
+}
+
+func (t *slackMetricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	method := SlackAPIMethod(req.URL)
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	slackAPIDuration.WithLabelValues(method).Observe(time.Since(start).Seconds())
+	slackAPICalls.WithLabelValues(method, slackOutcome(resp, err)).Inc()
+	return resp, err
+}
+
+// NewSlackHTTPClient is the HTTP client to give to the Slack client so its calls are measured.
+func NewSlackHTTPClient() *http.Client {
+	return &http.Client{Transport: &slackMetricsTransport{next: http.DefaultTransport}}
+}
+
+// ObserveInteraction records how long handling a Slack interaction of interactionType took.
+func ObserveInteraction(interactionType string, start time.Time, err error) {
+	interactionDuration.WithLabelValues(interactionType, outcome(err)).Observe(time.Since(start).Seconds())
+}
+
+// ObserveSchedulerRun counts a run of job, and a failure if err is not nil.
+func ObserveSchedulerRun(job string, err error) {
+	schedulerRuns.WithLabelValues(job).Inc()
+	if err != nil {
+		schedulerFailures.WithLabelValues(job).Inc()
+	}
+}
+
+// RecordedMood counts a mood saved, mood being empty for a day off.
+func RecordedMood(mood string) {
+	if mood == "" {
+		mood = DailyMoodStatusOff
+	}
+	moodsRecorded.WithLabelValues(mood).Inc()
+}
+
+// SessionStarted counts a session of kind as open until SessionEnded is called.
+func SessionStarted(kind string) {
+	activeSessions.WithLabelValues(kind).Inc()
+}
+
+// SessionEnded counts a session of kind as closed.
+func SessionEnded(kind string) {
+	activeSessions.WithLabelValues(kind).Dec()
+}
+
+// SetActiveSessions sets the number of sessions of kind open, for sessions outliving the process.
+func SetActiveSessions(kind string, count int64) {
+	activeSessions.WithLabelValues(kind).Set(float64(count))
+}
+
+// ObserveHTTPRequest records a request served on route, the route being the template
+// (/api/v1/users/:id) so ids do not end up in labels.
+func ObserveHTTPRequest(method, route string, code int, start time.Time) {
+	httpRequests.WithLabelValues(method, route, strconv.Itoa(code)).Inc()
+	httpRequestDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+}
+
+func startDBTimer(db *gorm.DB) {
+	db.InstanceSet(dbTimerKey, time.Now())
+}
+
+func dbTimerObserver(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if start, ok := db.InstanceGet(dbTimerKey); ok {
+			dbQueryDuration.WithLabelValues(operation).Observe(time.Since(start.(time.Time)).Seconds())
+		}
+	}
+}
+
+// RegisterDBMetrics times every query of db by operation through gorm callbacks.
+func RegisterDBMetrics(db *gorm.DB) error {
+	callbacks := db.Callback()
+	timers := []struct {
+		operation     string
+		before, after func(string, func(*gorm.DB)) error
+	}{
+		{"create", callbacks.Create().Before("*").Register, callbacks.Create().After("*").Register},
+		{"query", callbacks.Query().Before("*").Register, callbacks.Query().After("*").Register},
+		{"update", callbacks.Update().Before("*").Register, callbacks.Update().After("*").Register},
+		{"delete", callbacks.Delete().Before("*").Register, callbacks.Delete().After("*").Register},
+		{"row", callbacks.Row().Before("*").Register, callbacks.Row().After("*").Register},
+		{"raw", callbacks.Raw().Before("*").Register, callbacks.Raw().After("*").Register},
+	}
+	for _, timer := range timers {
+		if err := timer.before("simba:metrics_before_"+timer.operation, startDBTimer); err != nil {
+			return err
+		}
+		if err := timer.after("simba:metrics_after_"+timer.operation, dbTimerObserver(timer.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package simba_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// metricValue returns the value of a counter or gauge, or the sample count of a histogram,
+// whose labels contain labels
+func metricValue(t *testing.T, name string, labels map[string]string) float64 {
+	families, err := simba.MetricsRegistry.Gather()
+	assert.Nil(t, err)
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			matching := 0
+			for _, pair := range metric.GetLabel() {
+				if value, found := labels[pair.GetName()]; found && value == pair.GetValue() {
+					matching++
+				}
+			}
+			if matching != len(labels) {
+				continue
+			}
+			switch {
+			case metric.Counter != nil:
+				return metric.Counter.GetValue()
+			case metric.Gauge != nil:
+				return metric.Gauge.GetValue()
+			case metric.Histogram != nil:
+				return float64(metric.Histogram.GetSampleCount())
+			}
+		}
+	}
+	return 0
+}
+
+func TestSlackAPIMethod(t *testing.T) {
+	for path, expected := range map[string]string{
+		"https://slack.com/api/chat.postMessage":        "chat.postMessage",
+		"https://slack.com/api/users.info":              "users.info",
+		"https://files.slack.com/upload/v1/CwABAAAAXgo": "file_upload",
+		"https://slack.com/api/":                        "file_upload",
+	} {
+		u, err := url.Parse(path)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, simba.SlackAPIMethod(u), path)
+	}
+}
+
+func TestSlackHTTPClientOutcomes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/chat.postMessage":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"ok":false,"error":"channel_not_found"}`))
+		case "/api/users.info":
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Write([]byte(`{"ok":true}`))
+		default:
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	cases := []struct {
+		path, method, outcome, body string
+	}{
+		{"/api/chat.postMessage", "chat.postMessage", simba.SlackOutcomeSlackError, `{"ok":false,"error":"channel_not_found"}`},
+		{"/api/users.info", "users.info", simba.SlackOutcomeOK, `{"ok":true}`},
+		{"/api/conversations.members", "conversations.members", simba.SlackOutcomeRateLimited, ""},
+	}
+	client := simba.NewSlackHTTPClient()
+	for _, c := range cases {
+		labels := map[string]string{"method": c.method, "outcome": c.outcome}
+		before := metricValue(t, "simba_slack_api_calls_total", labels)
+
+		resp, err := client.Get(server.URL + c.path)
+		assert.Nil(t, err)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.Nil(t, err)
+		assert.Equal(t, c.body, string(body), "the body must still be readable by the Slack client")
+
+		assert.Equal(t, before+1, metricValue(t, "simba_slack_api_calls_total", labels), c.path)
+	}
+}
+
+func TestObserveSchedulerRun(t *testing.T) {
+	labels := map[string]string{"job": "test_job"}
+	runs := metricValue(t, "simba_scheduler_runs_total", labels)
+	failures := metricValue(t, "simba_scheduler_failures_total", labels)
+
+	simba.ObserveSchedulerRun("test_job", nil)
+	simba.ObserveSchedulerRun("test_job", errors.New("failed"))
+
+	assert.Equal(t, runs+2, metricValue(t, "simba_scheduler_runs_total", labels))
+	assert.Equal(t, failures+1, metricValue(t, "simba_scheduler_failures_total", labels))
+}
+
+func TestRecordedMood(t *testing.T) {
+	off := metricValue(t, "simba_moods_recorded_total", map[string]string{"mood": simba.DailyMoodStatusOff})
+	good := metricValue(t, "simba_moods_recorded_total", map[string]string{"mood": "good_mood"})
+
+	simba.RecordedMood("")
+	simba.RecordedMood("good_mood")
+
+	assert.Equal(t, off+1, metricValue(t, "simba_moods_recorded_total", map[string]string{"mood": simba.DailyMoodStatusOff}))
+	assert.Equal(t, good+1, metricValue(t, "simba_moods_recorded_total", map[string]string{"mood": "good_mood"}))
+}
+
+func TestRegisterDBMetrics(t *testing.T) {
+	db, err := gorm.Open(
+		postgres.New(postgres.Config{DSN: "host=localhost dbname=simba"}),
+		&gorm.Config{DryRun: true, DisableAutomaticPing: true},
+	)
+	assert.Nil(t, err)
+	assert.Nil(t, simba.RegisterDBMetrics(db))
+
+	labels := map[string]string{"operation": "query"}
+	before := metricValue(t, "simba_db_query_duration_seconds", labels)
+	var users []simba.User
+	assert.Nil(t, db.Find(&users).Error)
+	assert.Equal(t, before+1, metricValue(t, "simba_db_query_duration_seconds", labels))
+}
+
+func TestObserveHTTPRequest(t *testing.T) {
+	labels := map[string]string{"method": http.MethodGet, "route": "/api/v1/users/:id", "code": "404"}
+	before := metricValue(t, "simba_http_requests_total", labels)
+
+	simba.SessionStarted(simba.SessionKindHTTP)
+	assert.Equal(t, float64(1), metricValue(t, "simba_active_sessions", map[string]string{"kind": simba.SessionKindHTTP}))
+	simba.ObserveHTTPRequest(http.MethodGet, "/api/v1/users/:id", http.StatusNotFound, time.Now())
+	simba.SessionEnded(simba.SessionKindHTTP)
+
+	assert.Equal(t, before+1, metricValue(t, "simba_http_requests_total", labels))
+	assert.Equal(t, float64(0), metricValue(t, "simba_active_sessions", map[string]string{"kind": simba.SessionKindHTTP}))
+}
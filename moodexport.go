@@ -0,0 +1,276 @@
+package simba
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+
+	exportDateLayout = "2006-01-02"
+)
+
+// ExportFilter narrows the exported moods, empty fields do not filter.
+type ExportFilter struct {
+	From time.Time
+	// To is exclusive, the day after the last exported one
+	To          time.Time
+	ChannelID   string
+	SlackUserID string
+	// Mood is one of Moods, or DailyMoodStatusOff for the days off
+	Mood string
+	// Pseudonymise replaces usernames and Slack ids with a salted hash
+	Pseudonymise bool
+	// Salt of the pseudonyms, the same salt gives the same pseudonyms across exports
+	Salt string
+	// Audience restricts to these Slack ids when not nil
+	Audience []string
+	// MinGroupSize is the fewest people aggregates and pseudonymised exports can be about
+	MinGroupSize int
+}
+
+// NewExportFilter validates the filter given as text, from and to being YYYY-MM-DD days included.
+func NewExportFilter(from, to, channelId, slackUserId, mood string) (*ExportFilter, error) {
+	filter := &ExportFilter{ChannelID: channelId, SlackUserID: slackUserId, Mood: mood}
+	if from != "" {
+		day, err := time.ParseInLocation(exportDateLayout, from, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("wrong from date %q, expected YYYY-MM-DD", from)
+		}
+		filter.From = day
+	}
+	if to != "" {
+		day, err := time.ParseInLocation(exportDateLayout, to, time.Local)
+		if err != nil {
+			return nil, fmt.Errorf("wrong to date %q, expected YYYY-MM-DD", to)
+		}
+		filter.To = day.AddDate(0, 0, 1)
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, fmt.Errorf("from date %s is after to date %s", from, to)
+	}
+
+	if mood != "" && mood != DailyMoodStatusOff {
+		if _, ok := MoodFeelings[mood]; !ok {
+			return nil, fmt.Errorf("unknown mood %q", mood)
+		}
+	}
+	return filter, nil
+}
+
+// ExportRow is a daily mood joined with its user.
+type ExportRow struct {
+	CreatedAt   time.Time `gorm:"column:created_at" json:"date"`
+	Username    string    `gorm:"column:username" json:"username"`
+	SlackUserID string    `gorm:"column:slack_user_id" json:"slack_user_id"`
+	ChannelID   string    `gorm:"column:slack_channel_id" json:"channel_id"`
+	Mood        string    `gorm:"column:mood" json:"mood"`
+	Feeling     string    `gorm:"column:feeling" json:"feeling"`
+	Context     string    `gorm:"column:context" json:"context"`
+	Status      string    `gorm:"column:status" json:"status"`
+	ThreadTS    string    `gorm:"column:thread_ts" json:"thread_ts"`
+}
+
+var exportHeader = []string{
+	"date", "username", "slack_user_id", "channel_id", "mood", "feeling", "context", "status", "thread_ts",
+}
+
+// csvFormulaPrefixes start the cells spreadsheets run as formulas.
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvText neutralises a text written by people (username, feeling, context) with a leading
+// quote when a spreadsheet would run it as a formula (ie: =HYPERLINK(...)).
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func (row ExportRow) csvRecord() []string {
+	return []string{
+		row.CreatedAt.Format(time.RFC3339),
+		csvText(row.Username),
+		row.SlackUserID,
+		row.ChannelID,
+		row.Mood,
+		csvText(row.Feeling),
+		csvText(row.Context),
+		row.Status,
+		row.ThreadTS,
+	}
+}
+
+// Pseudonym hashes value with salt, always giving the same pseudonym for the same pair.
+func Pseudonym(value, salt string) string {
+	sum := sha256.Sum256([]byte(salt + ":" + value))
+	return "user_" + hex.EncodeToString(sum[:])[:12]
+}
+
+// ExportWriter writes rows one by one so exports never have to fit in memory.
+type ExportWriter interface {
+	Write(row ExportRow) error
+	// Close flushes what is left, it does not close the underlying writer
+	Close() error
+}
+
+type csvExportWriter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func (cew *csvExportWriter) Write(row ExportRow) error {
+	if !cew.headerWritten {
+		if err := cew.writer.Write(exportHeader); err != nil {
+			return err
+		}
+		cew.headerWritten = true
+	}
+	return cew.writer.Write(row.csvRecord())
+}
+
+func (cew *csvExportWriter) Close() error {
+	if !cew.headerWritten {
+		if err := cew.writer.Write(exportHeader); err != nil {
+			return err
+		}
+	}
+	cew.writer.Flush()
+	return cew.writer.Error()
+}
+
+type ndjsonExportWriter struct {
+	encoder *json.Encoder
+}
+
+func (nw *ndjsonExportWriter) Write(row ExportRow) error {
+	return nw.encoder.Encode(row)
+}
+
+func (nw *ndjsonExportWriter) Close() error {
+	return nil
+}
+
+// NewExportWriter returns the writer of format (csv or ndjson) into w.
+func NewExportWriter(format string, w io.Writer) (ExportWriter, error) {
+	switch format {
+	case ExportFormatCSV, "":
+		return &csvExportWriter{writer: csv.NewWriter(w)}, nil
+	case ExportFormatNDJSON:
+		return &ndjsonExportWriter{encoder: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("unknown export format %q, expected csv or ndjson", format)
+	}
+}
+
+// ExportContentType is the HTTP content type of format.
+func ExportContentType(format string) string {
+	if format == ExportFormatNDJSON {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// filteredMoods is the query of the daily moods joined with their users matching filter.
+func filteredMoods(dbClient *gorm.DB, filter *ExportFilter) *gorm.DB {
+	tx := dbClient.Table("daily_moods").
+		Joins("JOIN users ON users.id = daily_moods.user_id").
+		Where("daily_moods.deleted_at IS NULL")
+	if !filter.From.IsZero() {
+		tx = tx.Where("daily_moods.created_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		tx = tx.Where("daily_moods.created_at < ?", filter.To)
+	}
+	if filter.ChannelID != "" {
+		tx = tx.Where("users.slack_channel_id = ?", filter.ChannelID)
+	}
+	if filter.SlackUserID != "" {
+		tx = tx.Where("users.slack_user_id = ?", filter.SlackUserID)
+	}
+	if filter.Audience != nil {
+		tx = tx.Where("users.slack_user_id IN ?", filter.Audience)
+	}
+	if filter.Mood == DailyMoodStatusOff {
+		tx = tx.Where("daily_moods.status = ?", DailyMoodStatusOff)
+	} else if filter.Mood != "" {
+		tx = tx.Where("daily_moods.mood = ? AND daily_moods.status <> ?", filter.Mood, DailyMoodStatusOff)
+	}
+	return tx
+}
+
+// CheckGroupSize returns ErrGroupTooSmall when the moods matching filter are about fewer than
+// filter.MinGroupSize people: listed one by one, named or pseudonymised, they would tell the
+// moods of these people as the dashboard does not.
+func CheckGroupSize(dbClient *gorm.DB, filter *ExportFilter) error {
+	if filter.MinGroupSize <= 1 {
+		return nil
+	}
+	var people int64
+	if tx := filteredMoods(dbClient, filter).Distinct("users.slack_user_id").Count(&people); tx.Error != nil {
+		return tx.Error
+	}
+	if !IsAnonymous(int(people), filter.MinGroupSize) {
+		return fmt.Errorf("%w : %d people matching, at least %d needed", ErrGroupTooSmall, people, filter.MinGroupSize)
+	}
+	return nil
+}
+
+const exportColumns = "daily_moods.created_at, users.username, users.slack_user_id, users.slack_channel_id, " +
+	"daily_moods.mood, daily_moods.feeling, daily_moods.context, daily_moods.status, daily_moods.thread_ts"
+
+// ExportMoods streams the moods matching filter into writer, oldest first, and returns how
+// many rows have been written.
+func ExportMoods(dbClient *gorm.DB, filter *ExportFilter, writer ExportWriter) (int, error) {
+	rows, err := filteredMoods(dbClient, filter).Select(exportColumns).Order("daily_moods.created_at").Rows()
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	salt := filter.Salt
+	if filter.Pseudonymise && salt == "" {
+		// Pseudonyms are then only consistent within this export
+		salt = randomSalt()
+	}
+
+	count := 0
+	for rows.Next() {
+		var row ExportRow
+		if err := dbClient.ScanRows(rows, &row); err != nil {
+			return count, err
+		}
+		if filter.Pseudonymise {
+			row.Username = Pseudonym(row.SlackUserID, salt)
+			row.SlackUserID = row.Username
+		}
+		if err := writer.Write(row); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, err
+	}
+	return count, writer.Close()
+}
+
+func randomSalt() string {
+	buffer := make([]byte, 16)
+	if _, err := rand.Read(buffer); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 36)
+	}
+	return hex.EncodeToString(buffer)
+}
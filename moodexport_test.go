@@ -0,0 +1,119 @@
+package simba_test
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+)
+
+var fakeExportRow = simba.ExportRow{
+	CreatedAt:   time.Date(2026, time.October, 12, 10, 30, 0, 0, time.UTC),
+	Username:    "fake_username",
+	SlackUserID: "U0000",
+	ChannelID:   "C0000",
+	Mood:        "average_mood",
+	Feeling:     "Tired",
+	Context:     "Long day, \"again\"",
+	Status:      simba.DailyMoodStatusMood,
+	ThreadTS:    "1792137600.000100",
+}
+
+func TestNewExportFilter(t *testing.T) {
+	filter, err := simba.NewExportFilter("2026-10-01", "2026-10-31", "C0000", "U0000", "off")
+	assert.Nil(t, err)
+	assert.Equal(t, time.Date(2026, time.October, 1, 0, 0, 0, 0, time.Local), filter.From)
+	assert.Equal(t, time.Date(2026, time.November, 1, 0, 0, 0, 0, time.Local), filter.To)
+	assert.Equal(t, "C0000", filter.ChannelID)
+
+	filter, err = simba.NewExportFilter("", "", "", "", "")
+	assert.Nil(t, err)
+	assert.True(t, filter.From.IsZero())
+	assert.True(t, filter.To.IsZero())
+}
+
+func TestNewExportFilterErrors(t *testing.T) {
+	_, err := simba.NewExportFilter("01/10/2026", "", "", "", "")
+	assert.NotNil(t, err)
+	_, err = simba.NewExportFilter("2026-10-31", "2026-10-01", "", "", "")
+	assert.NotNil(t, err)
+	_, err = simba.NewExportFilter("", "", "", "", "happy")
+	assert.EqualError(t, err, "unknown mood \"happy\"")
+}
+
+func TestExportWriterCSV(t *testing.T) {
+	var buffer bytes.Buffer
+	writer, err := simba.NewExportWriter(simba.ExportFormatCSV, &buffer)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Write(fakeExportRow))
+	assert.Nil(t, writer.Close())
+	assert.Equal(
+		t,
+		"date,username,slack_user_id,channel_id,mood,feeling,context,status,thread_ts\n"+
+			"2026-10-12T10:30:00Z,fake_username,U0000,C0000,average_mood,Tired,\"Long day, \"\"again\"\"\",mood,1792137600.000100\n",
+		buffer.String(),
+	)
+
+	// Texts written by people are not run as formulas by spreadsheets
+	buffer.Reset()
+	writer, _ = simba.NewExportWriter(simba.ExportFormatCSV, &buffer)
+	formulas := fakeExportRow
+	formulas.Username = "@admin"
+	formulas.Feeling = "+Tired"
+	formulas.Context = `=HYPERLINK("https://example.com","-1")`
+	assert.Nil(t, writer.Write(formulas))
+	assert.Nil(t, writer.Close())
+	assert.Contains(
+		t,
+		buffer.String(),
+		"2026-10-12T10:30:00Z,'@admin,U0000,C0000,average_mood,'+Tired,\"'=HYPERLINK(\"\"https://example.com\"\",\"\"-1\"\")\",mood,",
+	)
+
+	// An empty export still has its header
+	buffer.Reset()
+	writer, _ = simba.NewExportWriter("", &buffer)
+	assert.Nil(t, writer.Close())
+	assert.Equal(t, "date,username,slack_user_id,channel_id,mood,feeling,context,status,thread_ts\n", buffer.String())
+}
+
+func TestExportWriterNDJSON(t *testing.T) {
+	var buffer bytes.Buffer
+	writer, err := simba.NewExportWriter(simba.ExportFormatNDJSON, &buffer)
+	assert.Nil(t, err)
+	assert.Nil(t, writer.Write(fakeExportRow))
+	assert.Nil(t, writer.Write(fakeExportRow))
+	assert.Nil(t, writer.Close())
+	line := `{"date":"2026-10-12T10:30:00Z","username":"fake_username","slack_user_id":"U0000","channel_id":"C0000",` +
+		`"mood":"average_mood","feeling":"Tired","context":"Long day, \"again\"","status":"mood","thread_ts":"1792137600.000100"}` + "\n"
+	assert.Equal(t, line+line, buffer.String())
+	assert.Equal(t, "application/x-ndjson", simba.ExportContentType(simba.ExportFormatNDJSON))
+
+	_, err = simba.NewExportWriter("xml", &buffer)
+	assert.NotNil(t, err)
+}
+
+func TestPseudonym(t *testing.T) {
+	pseudonym := simba.Pseudonym("U0000", "salt")
+	assert.Regexp(t, "^user_[0-9a-f]{12}$", pseudonym)
+	assert.Equal(t, pseudonym, simba.Pseudonym("U0000", "salt"))
+	assert.NotEqual(t, pseudonym, simba.Pseudonym("U0001", "salt"))
+	assert.NotEqual(t, pseudonym, simba.Pseudonym("U0000", "other_salt"))
+}
+
+func TestCheckGroupSize(t *testing.T) {
+	countPeople := func(people int64) map[string]fakeTable {
+		return map[string]fakeTable{"daily_moods": {Columns: []string{"count"}, Rows: [][]driver.Value{{people}}}}
+	}
+
+	// Named exports of a single person are refused too, not only pseudonymised ones
+	db, _ := newFakeGormDB(t, countPeople(1))
+	err := simba.CheckGroupSize(db, &simba.ExportFilter{SlackUserID: "U1", MinGroupSize: 3})
+	assert.ErrorIs(t, err, simba.ErrGroupTooSmall)
+	assert.Nil(t, simba.CheckGroupSize(db, &simba.ExportFilter{SlackUserID: "U1", MinGroupSize: 1}))
+
+	db, _ = newFakeGormDB(t, countPeople(3))
+	assert.Nil(t, simba.CheckGroupSize(db, &simba.ExportFilter{MinGroupSize: 3}))
+}
@@ -0,0 +1,77 @@
+package simba
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// maxRedirects is how many redirects NewPublicHTTPClient follows, as the default client does.
+const maxRedirects = 10
+
+// IsPublicIP tells if ip is an internet address, loopback, private, link-local, multicast and
+// unspecified ones being internal.
+func IsPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified()
+}
+
+// ValidatePublicURL checks rawUrl is an https url which host is not an internal address.
+// Host names are checked once resolved, when NewPublicHTTPClient connects.
+func ValidatePublicURL(rawUrl string) error {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return err
+	} else if u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("%s is not an https url", rawUrl)
+	}
+	if ip := net.ParseIP(u.Hostname()); ip != nil && !IsPublicIP(ip) {
+		return fmt.Errorf("%w : %s", ErrInternalAddress, rawUrl)
+	}
+	return nil
+}
+
+// publicAddressOnly refuses to connect to an internal address. It is called with the resolved
+// address so a host name pointing to the internal network is refused as well.
+func publicAddressOnly(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+		return fmt.Errorf("%w : %s", ErrInternalAddress, host)
+	}
+	return nil
+}
+
+// newControlledHTTPClient returns a client calling control before connecting to any address.
+func newControlledHTTPClient(timeout time.Duration, control func(string, string, syscall.RawConn) error) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: control}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// A proxy would connect on behalf of Simba, to addresses never checked
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// NewPublicHTTPClient returns the client fetching the urls given by users (quotes, calendars):
+// it only connects to internet addresses and only follows redirects to https urls.
+func NewPublicHTTPClient(timeout time.Duration) *http.Client {
+	client := newControlledHTTPClient(timeout, publicAddressOnly)
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return errors.New("stopped after too many redirects")
+		}
+		return ValidatePublicURL(req.URL.String())
+	}
+	return client
+}
@@ -0,0 +1,45 @@
+package simba_test
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	assert.True(t, simba.IsPublicIP(net.ParseIP("93.184.216.34")))
+	assert.True(t, simba.IsPublicIP(net.ParseIP("2606:2800:220:1:248:1893:25c8:1946")))
+	for _, ip := range []string{"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254", "0.0.0.0", "::1", "fe80::1", "fd00::1"} {
+		assert.False(t, simba.IsPublicIP(net.ParseIP(ip)), ip)
+	}
+}
+
+func TestValidatePublicURL(t *testing.T) {
+	assert.NoError(t, simba.ValidatePublicURL("https://example.com/quotes.json"))
+	assert.Error(t, simba.ValidatePublicURL("http://example.com/quotes.json"))
+	assert.Error(t, simba.ValidatePublicURL("file:///etc/passwd"))
+	assert.Error(t, simba.ValidatePublicURL("https://"))
+	assert.ErrorIs(t, simba.ValidatePublicURL("https://169.254.169.254/latest/meta-data"), simba.ErrInternalAddress)
+	assert.ErrorIs(t, simba.ValidatePublicURL("https://[::1]:8443"), simba.ErrInternalAddress)
+}
+
+func TestPublicHTTPClientRefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := simba.NewPublicHTTPClient(time.Second)
+	_, err := client.Get(server.URL)
+	assert.ErrorIs(t, err, simba.ErrInternalAddress)
+
+	// localhost is only known to be internal once resolved
+	_, err = client.Get("http://localhost:" + strconv.Itoa(server.Listener.Addr().(*net.TCPAddr).Port))
+	assert.ErrorIs(t, err, simba.ErrInternalAddress)
+}
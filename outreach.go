@@ -0,0 +1,63 @@
+package simba
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OutreachTemplate is a pre-written supportive message a manager can adapt.
+type OutreachTemplate struct {
+	Name string
+	Text string
+}
+
+// PickOutreachTemplate chooses a template based on the recent moods of a user.
+func PickOutreachTemplate(moods []DailyMood, username, locale string) OutreachTemplate {
+	name := "checkin"
+	if len(moods) > 0 {
+		moodCount := map[string]int{}
+		feelingCount := map[string]int{}
+		for _, m := range moods {
+			moodCount[m.Mood] += 1
+			feelingCount[m.Feeling] += 1
+		}
+		total := len(moods)
+		switch {
+		case moodCount["bad_mood"]*2 >= total:
+			name = "support"
+		case (feelingCount["Tired"]+feelingCount["Frustrated"])*2 >= total:
+			name = "workload"
+		case moodCount["good_mood"]*2 > total:
+			name = "kudos"
+		default:
+			name = "neutral"
+		}
+	}
+	return OutreachTemplate{Name: name, Text: T(locale, "outreach.template."+name, username)}
+}
+
+// RecordOutreach keeps track that a manager reached out to a user, without its content.
+func RecordOutreach(
+	dbClient *gorm.DB,
+	fromSlackUserId, toSlackUserId, templateName string,
+) (*Outreach, error) {
+	outreach := &Outreach{
+		FromSlackUserID: fromSlackUserId,
+		ToSlackUserID:   toSlackUserId,
+		Template:        templateName,
+		SentAt:          time.Now(),
+	}
+	if tx := dbClient.Create(outreach); tx.Error != nil {
+		return nil, tx.Error
+	}
+	return outreach, nil
+}
+
+type Outreach struct {
+	gorm.Model
+	FromSlackUserID string `gorm:"index"`
+	ToSlackUserID   string `gorm:"index"`
+	Template        string
+	SentAt          time.Time
+}
@@ -0,0 +1,41 @@
+package simba_test
+
+import (
+	"testing"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickOutreachTemplateNoMood(t *testing.T) {
+	template := simba.PickOutreachTemplate([]simba.DailyMood{}, "fake_username", simba.DefaultLocale)
+	assert.Equal(t, "checkin", template.Name)
+	assert.Contains(t, template.Text, "fake_username")
+}
+
+func TestPickOutreachTemplateBadMoods(t *testing.T) {
+	moods := []simba.DailyMood{
+		{Mood: "bad_mood", Feeling: "Sad"},
+		{Mood: "bad_mood", Feeling: "Mad"},
+		{Mood: "good_mood", Feeling: "Happy"},
+	}
+	assert.Equal(t, "support", simba.PickOutreachTemplate(moods, "fake_username", simba.DefaultLocale).Name)
+}
+
+func TestPickOutreachTemplateTired(t *testing.T) {
+	moods := []simba.DailyMood{
+		{Mood: "average_mood", Feeling: "Tired"},
+		{Mood: "average_mood", Feeling: "Frustrated"},
+		{Mood: "good_mood", Feeling: "Happy"},
+	}
+	assert.Equal(t, "workload", simba.PickOutreachTemplate(moods, "fake_username", simba.DefaultLocale).Name)
+}
+
+func TestPickOutreachTemplateGoodMoods(t *testing.T) {
+	moods := []simba.DailyMood{
+		{Mood: "good_mood", Feeling: "Happy"},
+		{Mood: "good_mood", Feeling: "Excited"},
+		{Mood: "average_mood", Feeling: "Neutral"},
+	}
+	assert.Equal(t, "kudos", simba.PickOutreachTemplate(moods, "fake_username", simba.DefaultLocale).Name)
+}
@@ -0,0 +1,198 @@
+package simba
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+// ParticipationPeriod is how far back the Home tabs look for participation.
+const ParticipationPeriod = 14 * 24 * time.Hour
+
+// ParticipationStats is how often someone (or a whole team) shared a mood on the working
+// days they were expected to, and how long after the daily post.
+type ParticipationStats struct {
+	SlackUserID string
+	// EligibleDays are the working days of the period, days off excluded
+	EligibleDays int
+	// AnsweredDays are the eligible days a mood has been shared on
+	AnsweredDays int
+	// ResponseTimes between the daily post and the first answer of each answered day
+	ResponseTimes []time.Duration
+}
+
+// Rate is the percentage of eligible days answered, 0 without eligible day.
+func (ps ParticipationStats) Rate() float64 {
+	if ps.EligibleDays == 0 {
+		return 0
+	}
+	return float64(ps.AnsweredDays) / float64(ps.EligibleDays) * 100
+}
+
+// MedianResponseTime returns 0 when nobody answered.
+func (ps ParticipationStats) MedianResponseTime() time.Duration {
+	if len(ps.ResponseTimes) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration{}, ps.ResponseTimes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2
+	}
+	return sorted[middle]
+}
+
+// ThreadTSTime converts a Slack message timestamp (1700000000.123456) to a time.
+func ThreadTSTime(ts string) (time.Time, error) {
+	seconds, _, _ := strings.Cut(ts, ".")
+	unix, err := strconv.ParseInt(seconds, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("wrong thread timestamp %q : %s", ts, err.Error())
+	}
+	return time.Unix(unix, 0), nil
+}
+
+// ComputeParticipation counts the eligible and answered working days of a person between from
+// and to (both included), moods being the ones shared in the period.
+func ComputeParticipation(
+	slackUserId string,
+	moods []DailyMood,
+	holidays []Holiday,
+	from, to time.Time,
+) ParticipationStats {
+	offDays := map[time.Time]bool{}
+	firstAnswers := map[time.Time]DailyMood{}
+	for _, m := range moods {
+		day := HolidayDate(m.CreatedAt)
+		if !IsWorkingDay(day, holidays) || day.Before(HolidayDate(from)) || day.After(HolidayDate(to)) {
+			continue
+		}
+		if m.IsOff() {
+			offDays[day] = true
+		} else if first, ok := firstAnswers[day]; !ok || m.CreatedAt.Before(first.CreatedAt) {
+			firstAnswers[day] = m
+		}
+	}
+
+	stats := ParticipationStats{
+		SlackUserID:   slackUserId,
+		ResponseTimes: []time.Duration{},
+	}
+	for day := range offDays {
+		// Changing your mind during the day, the mood wins
+		if _, answered := firstAnswers[day]; answered {
+			delete(offDays, day)
+		}
+	}
+	stats.EligibleDays = WorkingDaysBetween(from, to, holidays) - len(offDays)
+	stats.AnsweredDays = len(firstAnswers)
+	for _, m := range firstAnswers {
+		postedAt, err := ThreadTSTime(m.ThreadTS)
+		if err != nil || m.CreatedAt.Before(postedAt) {
+			continue
+		}
+		stats.ResponseTimes = append(stats.ResponseTimes, m.CreatedAt.Sub(postedAt))
+	}
+	return stats
+}
+
+// TeamParticipation adds up the participation of every member.
+func TeamParticipation(members []ParticipationStats) ParticipationStats {
+	team := ParticipationStats{ResponseTimes: []time.Duration{}}
+	for _, member := range members {
+		team.EligibleDays += member.EligibleDays
+		team.AnsweredDays += member.AnsweredDays
+		team.ResponseTimes = append(team.ResponseTimes, member.ResponseTimes...)
+	}
+	return team
+}
+
+// FetchUserParticipation computes the participation of a single person in channelId.
+func FetchUserParticipation(
+	dbClient *gorm.DB,
+	channelId, slackUserId string,
+	from, to time.Time,
+) (ParticipationStats, error) {
+	holidays, err := FetchHolidays(dbClient, channelId, from, to)
+	if err != nil {
+		return ParticipationStats{}, err
+	}
+	moods, err := fetchUserMoodsBetween(dbClient, slackUserId, from, to)
+	if err != nil {
+		return ParticipationStats{}, err
+	}
+	return ComputeParticipation(slackUserId, moods, holidays, from, to), nil
+}
+
+// FetchTeamParticipation computes the participation of every member of channelId, people
+// away and bots left out. Members who never answered count with no answered day.
+func FetchTeamParticipation(
+	dbClient *gorm.DB,
+	slackClient *slack.Client,
+	channelId string,
+	from, to time.Time,
+) ([]ParticipationStats, error) {
+	_, members, err := FetchUsersFromChannel(slackClient, channelId)
+	if err != nil {
+		return nil, err
+	}
+	holidays, err := FetchHolidays(dbClient, channelId, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	stats := []ParticipationStats{}
+	for _, member := range members {
+		if member.IsBot || member.Deleted {
+			continue
+		}
+		var user User
+		if tx := dbClient.Find(&user, "slack_user_id = ?", member.ID); tx.Error != nil {
+			return nil, tx.Error
+		}
+		if IsUserPaused(&user, member, now) {
+			continue
+		}
+		moods, err := fetchUserMoodsBetween(dbClient, member.ID, from, to)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, ComputeParticipation(member.ID, moods, holidays, from, to))
+	}
+	return stats, nil
+}
+
+func fetchUserMoodsBetween(dbClient *gorm.DB, slackUserId string, from, to time.Time) ([]DailyMood, error) {
+	var moods []DailyMood
+	tx := dbClient.Joins("JOIN users ON users.id = daily_moods.user_id").
+		Where("users.slack_user_id = ? AND daily_moods.created_at BETWEEN ? AND ?", slackUserId, from, to).
+		Find(&moods)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return moods, nil
+}
+
+// ParticipationText renders the rate and the median response time in the language of the reader.
+func ParticipationText(stats ParticipationStats, locale string) string {
+	text := T(locale, "home.participation", stats.Rate(), stats.AnsweredDays, stats.EligibleDays)
+	if len(stats.ResponseTimes) > 0 {
+		text += " · " + T(locale, "participation.response", formatResponseTime(stats.MedianResponseTime()))
+	}
+	return text
+}
+
+// formatResponseTime prints 45 min or 2h05.
+func formatResponseTime(d time.Duration) string {
+	if d < time.Hour {
+		return fmt.Sprintf("%d min", int(d.Minutes()))
+	}
+	return fmt.Sprintf("%dh%02d", int(d.Hours()), int(d.Minutes())%60)
+}
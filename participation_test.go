@@ -0,0 +1,70 @@
+package simba_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAnswer is a mood shared answerAfter the daily post of day at 10:00 UTC
+func fakeAnswer(day time.Time, answerAfter time.Duration, status string) simba.DailyMood {
+	postedAt := day.Add(10 * time.Hour)
+	return simba.DailyMood{
+		Mood:      "good_mood",
+		Status:    status,
+		ThreadTS:  fmt.Sprintf("%d.000100", postedAt.Unix()),
+		CreatedAt: postedAt.Add(answerAfter),
+	}
+}
+
+func TestThreadTSTime(t *testing.T) {
+	postedAt, err := simba.ThreadTSTime("1792137600.000100")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1792137600), postedAt.Unix())
+
+	_, err = simba.ThreadTSTime("")
+	assert.NotNil(t, err)
+}
+
+func TestComputeParticipation(t *testing.T) {
+	monday := utcDay(2026, time.October, 12)
+	holidays := []simba.Holiday{{Date: monday.AddDate(0, 0, 2), Name: "Fake holiday"}}
+	moods := []simba.DailyMood{
+		fakeAnswer(monday, 30*time.Minute, simba.DailyMoodStatusMood),
+		fakeAnswer(monday, 3*time.Hour, simba.DailyMoodStatusMood),
+		fakeAnswer(monday.AddDate(0, 0, 1), 2*time.Hour, simba.DailyMoodStatusMood),
+		fakeAnswer(monday.AddDate(0, 0, 3), time.Hour, simba.DailyMoodStatusOff),
+		// Week-ends are not working days
+		fakeAnswer(monday.AddDate(0, 0, 5), time.Hour, simba.DailyMoodStatusMood),
+	}
+
+	stats := simba.ComputeParticipation("fake_user", moods, holidays, monday, monday.AddDate(0, 0, 6))
+	assert.Equal(t, "fake_user", stats.SlackUserID)
+	assert.Equal(t, 3, stats.EligibleDays)
+	assert.Equal(t, 2, stats.AnsweredDays)
+	assert.InDelta(t, 66.67, stats.Rate(), 0.01)
+	assert.Equal(t, 75*time.Minute, stats.MedianResponseTime())
+	assert.Equal(
+		t,
+		"Participation 67% (2/3 working days) · median answer 1h15 after the daily post",
+		simba.ParticipationText(stats, "en"),
+	)
+}
+
+func TestTeamParticipation(t *testing.T) {
+	team := simba.TeamParticipation([]simba.ParticipationStats{
+		{EligibleDays: 5, AnsweredDays: 5, ResponseTimes: []time.Duration{time.Minute, 5 * time.Minute}},
+		{EligibleDays: 5, AnsweredDays: 0, ResponseTimes: []time.Duration{}},
+		{EligibleDays: 0, AnsweredDays: 0, ResponseTimes: []time.Duration{20 * time.Minute}},
+	})
+	assert.Equal(t, 10, team.EligibleDays)
+	assert.Equal(t, float64(50), team.Rate())
+	assert.Equal(t, 5*time.Minute, team.MedianResponseTime())
+	assert.Equal(t, "Participation 50% (5/10 working days) · median answer 5 min after the daily post", simba.ParticipationText(team, "en"))
+
+	assert.Equal(t, float64(0), simba.TeamParticipation(nil).Rate())
+	assert.Equal(t, "Participation 0% (0/0 working days)", simba.ParticipationText(simba.TeamParticipation(nil), "en"))
+}
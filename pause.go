@@ -0,0 +1,121 @@
+package simba
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+// outOfOfficeEmojis are the Slack status emojis meaning someone is away.
+var outOfOfficeEmojis = map[string]bool{
+	":palm_tree:":             true,
+	":desert_island:":         true,
+	":beach_with_umbrella:":   true,
+	":airplane:":              true,
+	":face_with_thermometer:": true,
+}
+
+// outOfOfficeKeywords are searched as whole words in the Slack status text (lower case).
+var outOfOfficeKeywords = []string{
+	"vacation",
+	"vacations",
+	"holiday",
+	"holidays",
+	"out of office",
+	"ooo",
+	"on leave",
+	"sick",
+	"vacances",
+	"congé",
+	"absent",
+	"malade",
+}
+
+// outOfOfficePattern matches one of outOfOfficeKeywords between non-letters, so "sick" is
+// not found in "homesick". \b is not used as it only knows ASCII letters (ie: "congé").
+var outOfOfficePattern = func() *regexp.Regexp {
+	keywords := make([]string, len(outOfOfficeKeywords))
+	for idx, keyword := range outOfOfficeKeywords {
+		keywords[idx] = regexp.QuoteMeta(keyword)
+	}
+	return regexp.MustCompile(`(?:^|[^\p{L}\p{N}])(?:` + strings.Join(keywords, "|") + `)(?:$|[^\p{L}\p{N}])`)
+}()
+
+// IsOutOfOfficeStatus tells if a Slack profile status says the user is away.
+// Expired statuses are ignored.
+func IsOutOfOfficeStatus(profile slack.UserProfile, now time.Time) bool {
+	if profile.StatusExpiration != 0 && time.Unix(int64(profile.StatusExpiration), 0).Before(now) {
+		return false
+	} else if outOfOfficeEmojis[profile.StatusEmoji] {
+		return true
+	}
+	return outOfOfficePattern.MatchString(strings.ToLower(profile.StatusText))
+}
+
+// IsPaused tells if the user paused Simba until a day that is not over yet.
+func (u *User) IsPaused(now time.Time) bool {
+	return u.PausedUntil != nil && !HolidayDate(now).After(HolidayDate(*u.PausedUntil))
+}
+
+// IsUserPaused tells if a user is paused in Simba or away according to its Slack status.
+// Both user and slackUser can be nil when unknown.
+func IsUserPaused(user *User, slackUser *slack.User, now time.Time) bool {
+	if user != nil && user.IsPaused(now) {
+		return true
+	}
+	return slackUser != nil && IsOutOfOfficeStatus(slackUser.Profile, now)
+}
+
+// FetchPausedSlackUserIds returns the Slack ids of the users paused in Simba at now.
+func FetchPausedSlackUserIds(dbClient *gorm.DB, now time.Time) (map[string]bool, error) {
+	var slackUserIds []string
+	tx := dbClient.Model(&User{}).
+		Where("paused_until IS NOT NULL AND paused_until >= ?", HolidayDate(now)).
+		Pluck("slack_user_id", &slackUserIds)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	paused := make(map[string]bool, len(slackUserIds))
+	for _, id := range slackUserIds {
+		paused[id] = true
+	}
+	return paused, nil
+}
+
+// SetUserPause pauses Simba for a user until the given day (included) or resumes it when until is nil.
+func SetUserPause(
+	dbClient *gorm.DB,
+	channelId, slackUserId, userName string,
+	until *time.Time,
+) (*User, error) {
+	user := User{SlackUserID: slackUserId, SlackChannelId: channelId, Username: userName}
+	if tx := dbClient.FirstOrInit(&user, "slack_user_id = ?", slackUserId); tx.Error != nil {
+		return nil, tx.Error
+	}
+	if until != nil {
+		pausedUntil := HolidayDate(*until)
+		until = &pausedUntil
+	}
+	user.PausedUntil = until
+	if tx := dbClient.Save(&user); tx.Error != nil {
+		return nil, tx.Error
+	}
+	return &user, nil
+}
+
+// IsSlackUserPaused fetches a user from DB and Slack to tell if it is paused or away.
+func IsSlackUserPaused(
+	dbClient *gorm.DB,
+	slackClient *slack.Client,
+	slackUserId string,
+	now time.Time,
+) (bool, error) {
+	user, slackUser, err := FechCurrent(dbClient, slackClient, slackUserId)
+	if err != nil {
+		return false, err
+	}
+	return IsUserPaused(user, slackUser, now), nil
+}
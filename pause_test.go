@@ -0,0 +1,48 @@
+package simba_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/saisona/simba"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsOutOfOfficeStatus(t *testing.T) {
+	now := time.Now()
+	assert.True(t, simba.IsOutOfOfficeStatus(slack.UserProfile{StatusEmoji: ":palm_tree:"}, now))
+	assert.True(t, simba.IsOutOfOfficeStatus(slack.UserProfile{StatusText: "On Vacation until monday"}, now))
+	assert.True(t, simba.IsOutOfOfficeStatus(slack.UserProfile{StatusText: "En congé"}, now))
+	assert.False(t, simba.IsOutOfOfficeStatus(slack.UserProfile{StatusEmoji: ":coffee:", StatusText: "Focus"}, now))
+	for _, text := range []string{"OOO", "OOO until Monday", "Sick :(", "on holidays", "Malade", "absent (RTT)"} {
+		assert.True(t, simba.IsOutOfOfficeStatus(slack.UserProfile{StatusText: text}, now), text)
+	}
+	for _, text := range []string{"homesick", "Feeling gooood", "Zoooom call", "Holidayland planning"} {
+		assert.False(t, simba.IsOutOfOfficeStatus(slack.UserProfile{StatusText: text}, now), text)
+	}
+
+	expired := slack.UserProfile{StatusEmoji: ":palm_tree:", StatusExpiration: int(now.Add(-time.Hour).Unix())}
+	assert.False(t, simba.IsOutOfOfficeStatus(expired, now))
+	notExpired := slack.UserProfile{StatusEmoji: ":palm_tree:", StatusExpiration: int(now.Add(time.Hour).Unix())}
+	assert.True(t, simba.IsOutOfOfficeStatus(notExpired, now))
+}
+
+func TestUserIsPaused(t *testing.T) {
+	now := time.Date(2026, time.August, 3, 15, 0, 0, 0, time.UTC)
+	lastDay := time.Date(2026, time.August, 3, 0, 0, 0, 0, time.UTC)
+	yesterday := lastDay.AddDate(0, 0, -1)
+
+	assert.False(t, (&simba.User{}).IsPaused(now))
+	assert.True(t, (&simba.User{PausedUntil: &lastDay}).IsPaused(now))
+	assert.False(t, (&simba.User{PausedUntil: &yesterday}).IsPaused(now))
+}
+
+func TestIsUserPaused(t *testing.T) {
+	now := time.Now()
+	away := &slack.User{Profile: slack.UserProfile{StatusEmoji: ":airplane:"}}
+	assert.True(t, simba.IsUserPaused(&simba.User{}, away, now))
+	assert.True(t, simba.IsUserPaused(nil, away, now))
+	assert.False(t, simba.IsUserPaused(&simba.User{}, &slack.User{}, now))
+	assert.False(t, simba.IsUserPaused(nil, nil, now))
+}
@@ -0,0 +1,67 @@
+package plot
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+)
+
+// Bar is a labelled value of a bar chart.
+type Bar struct {
+	Label string
+	Value float64
+	Color color.Color
+}
+
+// BarChart draws vertical bars starting from 0, the scale fits the highest bar.
+type BarChart struct {
+	Title  string
+	Bars   []Bar
+	Width  int
+	Height int
+}
+
+// Render draws the chart as PNG into w.
+func (bc *BarChart) Render(w io.Writer) error {
+	if len(bc.Bars) == 0 {
+		return fmt.Errorf("bar chart has no bar")
+	}
+	c := newCanvas(bc.Width, bc.Height)
+	area := image.Rect(margin, margin, c.Bounds().Dx()-margin/2, c.Bounds().Dy()-margin)
+	c.centeredText(c.Bounds().Dx()/2, margin/2, bc.Title, Foreground)
+
+	max := 0.0
+	for _, bar := range bc.Bars {
+		if bar.Value < 0 {
+			return fmt.Errorf("bar %s has a negative value %f", bar.Label, bar.Value)
+		}
+		max = math.Max(max, bar.Value)
+	}
+	if max == 0 {
+		max = 1
+	}
+
+	slot := area.Dx() / len(bc.Bars)
+	barWidth := slot * 2 / 3
+	for idx, bar := range bc.Bars {
+		x := area.Min.X + idx*slot + (slot-barWidth)/2
+		top := area.Max.Y - int(bar.Value/max*float64(area.Dy()-lineHeight))
+		c.fillRect(image.Rect(x, top, x+barWidth, area.Max.Y), seriesColor(bar.Color, idx))
+		center := x + barWidth/2
+		c.centeredText(center, top-4, formatValue(bar.Value), Foreground)
+		c.centeredText(center, area.Max.Y+lineHeight+4, bar.Label, Foreground)
+	}
+	c.line(area.Min.X, area.Max.Y, area.Max.X, area.Max.Y, 1, Foreground)
+
+	return c.encode(w)
+}
+
+// formatValue prints integers without decimals.
+func formatValue(value float64) string {
+	if value == math.Trunc(value) {
+		return fmt.Sprintf("%.0f", value)
+	}
+	return fmt.Sprintf("%.1f", value)
+}
@@ -0,0 +1,35 @@
+package plot_test
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+
+	"github.com/saisona/simba/plot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBarChartRender(t *testing.T) {
+	chart := &plot.BarChart{
+		Title:  "Moods",
+		Width:  300,
+		Height: 200,
+		Bars: []plot.Bar{
+			{Label: "Bien", Value: 3},
+			{Label: "Moyen", Value: 0},
+			{Label: "Pas top", Value: 1.5},
+		},
+	}
+	var buffer bytes.Buffer
+	assert.Nil(t, chart.Render(&buffer))
+	img, err := png.Decode(&buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, 300, img.Bounds().Dx())
+	assert.Equal(t, 200, img.Bounds().Dy())
+}
+
+func TestBarChartRenderErrors(t *testing.T) {
+	assert.NotNil(t, (&plot.BarChart{}).Render(&bytes.Buffer{}))
+	chart := &plot.BarChart{Bars: []plot.Bar{{Label: "Negative", Value: -1}}}
+	assert.NotNil(t, chart.Render(&bytes.Buffer{}))
+}
@@ -0,0 +1,149 @@
+// Package plot draws simple charts to PNG with the standard library only,
+// so Simba does not depend on any external chart service.
+package plot
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"strings"
+	"unicode"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	defaultWidth  = 800
+	defaultHeight = 400
+	margin        = 48
+	lineHeight    = 13
+	charWidth     = 7
+)
+
+var (
+	Background = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	Foreground = color.RGBA{0x1d, 0x1c, 0x1d, 0xff}
+	GridColor  = color.RGBA{0xe8, 0xe8, 0xe8, 0xff}
+
+	// Palette is used in order for series without color.
+	Palette = []color.RGBA{
+		{0x2e, 0xb6, 0x7d, 0xff},
+		{0x36, 0xc5, 0xf0, 0xff},
+		{0xec, 0xb2, 0x2e, 0xff},
+		{0xe0, 0x1e, 0x5a, 0xff},
+		{0x4a, 0x15, 0x4b, 0xff},
+	}
+)
+
+// canvas is an RGBA image with the drawing primitives the charts need.
+type canvas struct {
+	*image.RGBA
+}
+
+func newCanvas(width, height int) *canvas {
+	if width <= 0 {
+		width = defaultWidth
+	}
+	if height <= 0 {
+		height = defaultHeight
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{Background}, image.Point{}, draw.Src)
+	return &canvas{img}
+}
+
+func (c *canvas) fillRect(rect image.Rectangle, col color.Color) {
+	draw.Draw(c.RGBA, rect.Intersect(c.Bounds()), &image.Uniform{col}, image.Point{}, draw.Over)
+}
+
+// line draws a line of the given thickness with Bresenham's algorithm.
+func (c *canvas) line(x0, y0, x1, y1, thickness int, col color.Color) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	err := dx + dy
+	half := thickness / 2
+	for {
+		c.fillRect(image.Rect(x0-half, y0-half, x0-half+thickness, y0-half+thickness), col)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * err
+		if e2 >= dy {
+			err += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			err += dx
+			y0 += sy
+		}
+	}
+}
+
+// text writes s with its baseline starting at (x, y).
+func (c *canvas) text(x, y int, s string, col color.Color) {
+	drawer := &font.Drawer{
+		Dst:  c.RGBA,
+		Src:  &image.Uniform{col},
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	drawer.DrawString(asciiText(s))
+}
+
+// asciiText removes accents since the basic font only has ASCII glyphs (é is drawn as e).
+func asciiText(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Mn, r) {
+			return -1
+		}
+		return r
+	}, norm.NFD.String(s))
+}
+
+// centeredText writes s centered horizontally on x.
+func (c *canvas) centeredText(x, y int, s string, col color.Color) {
+	c.text(x-len([]rune(s))*charWidth/2, y, s, col)
+}
+
+func (c *canvas) encode(w io.Writer) error {
+	return png.Encode(w, c.RGBA)
+}
+
+// legend writes the name of each series with its color from (x, y).
+func (c *canvas) legend(x, y int, names []string, colors []color.Color) {
+	for idx, name := range names {
+		c.fillRect(image.Rect(x, y-9, x+10, y+1), colors[idx])
+		c.text(x+14, y, name, Foreground)
+		x += 14 + len([]rune(name))*charWidth + 16
+	}
+}
+
+func seriesColor(col color.Color, idx int) color.Color {
+	if col != nil {
+		return col
+	}
+	return Palette[idx%len(Palette)]
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+func sign(v int) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,93 @@
+package plot
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+)
+
+// Heatmap draws a grid of Values[row][column], the darker the higher.
+// NaN values are left blank.
+type Heatmap struct {
+	Title   string
+	Rows    []string
+	Columns []string
+	Values  [][]float64
+	// Color of the highest value, lower values fade to the background
+	Color  color.Color
+	Width  int
+	Height int
+}
+
+// Render draws the heatmap as PNG into w.
+func (hm *Heatmap) Render(w io.Writer) error {
+	if len(hm.Rows) == 0 || len(hm.Columns) == 0 {
+		return fmt.Errorf("heatmap needs rows and columns")
+	} else if len(hm.Values) != len(hm.Rows) {
+		return fmt.Errorf("heatmap has %d rows of values for %d rows", len(hm.Values), len(hm.Rows))
+	}
+
+	rowLabelWidth := 0
+	for _, row := range hm.Rows {
+		if width := len([]rune(row)) * charWidth; width > rowLabelWidth {
+			rowLabelWidth = width
+		}
+	}
+	c := newCanvas(hm.Width, hm.Height)
+	area := image.Rect(margin/2+rowLabelWidth+8, margin, c.Bounds().Dx()-margin/2, c.Bounds().Dy()-margin)
+	c.centeredText(c.Bounds().Dx()/2, margin/2, hm.Title, Foreground)
+
+	min, max := math.Inf(1), math.Inf(-1)
+	for _, values := range hm.Values {
+		for _, value := range values {
+			if !math.IsNaN(value) {
+				min, max = math.Min(min, value), math.Max(max, value)
+			}
+		}
+	}
+
+	cellWidth := area.Dx() / len(hm.Columns)
+	cellHeight := area.Dy() / len(hm.Rows)
+	for rowIdx, row := range hm.Rows {
+		y := area.Min.Y + rowIdx*cellHeight
+		c.text(area.Min.X-rowLabelWidth-8, y+cellHeight/2+lineHeight/3, row, Foreground)
+		for colIdx := range hm.Columns {
+			if colIdx >= len(hm.Values[rowIdx]) || math.IsNaN(hm.Values[rowIdx][colIdx]) {
+				continue
+			}
+			value := hm.Values[rowIdx][colIdx]
+			ratio := 0.0
+			if max > min {
+				ratio = (value - min) / (max - min)
+			} else if value > 0 {
+				ratio = 1
+			}
+			x := area.Min.X + colIdx*cellWidth
+			cell := image.Rect(x+1, y+1, x+cellWidth-1, y+cellHeight-1)
+			c.fillRect(cell, fade(seriesColor(hm.Color, 0), ratio))
+			textColor := Foreground
+			if ratio > 0.6 {
+				textColor = Background
+			}
+			c.centeredText(x+cellWidth/2, y+cellHeight/2+lineHeight/3, formatValue(value), textColor)
+		}
+	}
+	for colIdx, column := range hm.Columns {
+		c.centeredText(area.Min.X+colIdx*cellWidth+cellWidth/2, area.Max.Y+lineHeight+4, column, Foreground)
+	}
+
+	return c.encode(w)
+}
+
+// fade mixes col with the background, ratio 1 being col and 0 a light tint of it.
+func fade(col color.Color, ratio float64) color.Color {
+	const minRatio = 0.15
+	ratio = minRatio + (1-minRatio)*math.Max(0, math.Min(1, ratio))
+	r, g, b, _ := col.RGBA()
+	mix := func(channel uint32, background uint8) uint8 {
+		return uint8(float64(background)*(1-ratio) + float64(channel>>8)*ratio)
+	}
+	return color.RGBA{mix(r, Background.R), mix(g, Background.G), mix(b, Background.B), 0xff}
+}
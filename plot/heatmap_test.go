@@ -0,0 +1,32 @@
+package plot_test
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"testing"
+
+	"github.com/saisona/simba/plot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeatmapRender(t *testing.T) {
+	heatmap := &plot.Heatmap{
+		Title:   "Week",
+		Rows:    []string{"Good", "Bad"},
+		Columns: []string{"Mon", "Tue", "Wed"},
+		Values:  [][]float64{{3, 2, math.NaN()}, {0, 1}},
+	}
+	var buffer bytes.Buffer
+	assert.Nil(t, heatmap.Render(&buffer))
+	img, err := png.Decode(&buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, 800, img.Bounds().Dx())
+	assert.Equal(t, 400, img.Bounds().Dy())
+}
+
+func TestHeatmapRenderErrors(t *testing.T) {
+	assert.NotNil(t, (&plot.Heatmap{}).Render(&bytes.Buffer{}))
+	heatmap := &plot.Heatmap{Rows: []string{"Good", "Bad"}, Columns: []string{"Mon"}, Values: [][]float64{{1}}}
+	assert.NotNil(t, heatmap.Render(&bytes.Buffer{}))
+}
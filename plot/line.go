@@ -0,0 +1,125 @@
+package plot
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+	"math"
+)
+
+// Series is a named list of values, NaN values are gaps in the line.
+type Series struct {
+	Name   string
+	Values []float64
+	Color  color.Color
+}
+
+// LineChart draws series over the same X labels with a Y axis from Min to Max.
+type LineChart struct {
+	Title  string
+	Labels []string
+	Series []Series
+	Min    float64
+	Max    float64
+	Width  int
+	Height int
+}
+
+// Render draws the chart as PNG into w.
+func (lc *LineChart) Render(w io.Writer) error {
+	if lc.Max <= lc.Min {
+		return fmt.Errorf("line chart max %f must be greater than min %f", lc.Max, lc.Min)
+	}
+	c := newCanvas(lc.Width, lc.Height)
+	area := image.Rect(margin, margin, c.Bounds().Dx()-margin/2, c.Bounds().Dy()-margin)
+
+	c.centeredText(c.Bounds().Dx()/2, margin/2, lc.Title, Foreground)
+	lc.drawYAxis(c, area)
+	lc.drawXAxis(c, area)
+
+	names := make([]string, len(lc.Series))
+	colors := make([]color.Color, len(lc.Series))
+	for idx, s := range lc.Series {
+		names[idx] = s.Name
+		colors[idx] = seriesColor(s.Color, idx)
+		lc.drawSeries(c, area, s, colors[idx])
+	}
+	c.legend(area.Min.X, c.Bounds().Dy()-8, names, colors)
+
+	return c.encode(w)
+}
+
+func (lc *LineChart) drawYAxis(c *canvas, area image.Rectangle) {
+	const ticks = 4
+	for i := 0; i <= ticks; i++ {
+		value := lc.Min + (lc.Max-lc.Min)*float64(i)/ticks
+		y := lc.y(area, value)
+		c.line(area.Min.X, y, area.Max.X, y, 1, GridColor)
+		label := fmt.Sprintf("%.0f", value)
+		c.text(area.Min.X-len(label)*charWidth-6, y+lineHeight/3, label, Foreground)
+	}
+	c.line(area.Min.X, area.Min.Y, area.Min.X, area.Max.Y, 1, Foreground)
+}
+
+func (lc *LineChart) drawXAxis(c *canvas, area image.Rectangle) {
+	c.line(area.Min.X, area.Max.Y, area.Max.X, area.Max.Y, 1, Foreground)
+	if len(lc.Labels) == 0 {
+		return
+	}
+	// Only print as many labels as the width allows
+	maxLabelWidth := 0
+	for _, label := range lc.Labels {
+		if width := len([]rune(label)) * charWidth; width > maxLabelWidth {
+			maxLabelWidth = width
+		}
+	}
+	step := 1
+	if maxLabelWidth > 0 {
+		if fit := area.Dx() / (maxLabelWidth + 8); fit > 0 && len(lc.Labels) > fit {
+			step = int(math.Ceil(float64(len(lc.Labels)) / float64(fit)))
+		}
+	}
+	for idx := 0; idx < len(lc.Labels); idx += step {
+		c.centeredText(lc.x(area, idx), area.Max.Y+lineHeight+4, lc.Labels[idx], Foreground)
+	}
+}
+
+func (lc *LineChart) drawSeries(c *canvas, area image.Rectangle, s Series, col color.Color) {
+	prevX, prevY, hasPrev := 0, 0, false
+	for idx, value := range s.Values {
+		if math.IsNaN(value) {
+			hasPrev = false
+			continue
+		}
+		x, y := lc.x(area, idx), lc.y(area, value)
+		if hasPrev {
+			c.line(prevX, prevY, x, y, 3, col)
+		}
+		c.fillRect(image.Rect(x-3, y-3, x+4, y+4), col)
+		prevX, prevY, hasPrev = x, y, true
+	}
+}
+
+func (lc *LineChart) points() int {
+	points := len(lc.Labels)
+	for _, s := range lc.Series {
+		if len(s.Values) > points {
+			points = len(s.Values)
+		}
+	}
+	return points
+}
+
+func (lc *LineChart) x(area image.Rectangle, idx int) int {
+	points := lc.points()
+	if points <= 1 {
+		return area.Min.X + area.Dx()/2
+	}
+	return area.Min.X + idx*area.Dx()/(points-1)
+}
+
+func (lc *LineChart) y(area image.Rectangle, value float64) int {
+	value = math.Max(lc.Min, math.Min(lc.Max, value))
+	return area.Max.Y - int((value-lc.Min)/(lc.Max-lc.Min)*float64(area.Dy()))
+}
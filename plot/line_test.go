@@ -0,0 +1,37 @@
+package plot_test
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"testing"
+
+	"github.com/saisona/simba/plot"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineChartRender(t *testing.T) {
+	chart := &plot.LineChart{
+		Title:  "Mood",
+		Labels: []string{"01/10", "08/10", "15/10"},
+		Min:    0,
+		Max:    100,
+		Width:  300,
+		Height: 200,
+		Series: []plot.Series{
+			{Name: "Score", Values: []float64{10, math.NaN(), 90}},
+			{Name: "Participation", Values: []float64{50, 60, 70}},
+		},
+	}
+	var buffer bytes.Buffer
+	assert.Nil(t, chart.Render(&buffer))
+	img, err := png.Decode(&buffer)
+	assert.Nil(t, err)
+	assert.Equal(t, 300, img.Bounds().Dx())
+	assert.Equal(t, 200, img.Bounds().Dy())
+}
+
+func TestLineChartRenderWrongScale(t *testing.T) {
+	chart := &plot.LineChart{Min: 10, Max: 10}
+	assert.NotNil(t, chart.Render(&bytes.Buffer{}))
+}
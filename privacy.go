@@ -0,0 +1,36 @@
+package simba
+
+// DefaultMinGroupSize is the minimum group size used when APP_MIN_GROUP_SIZE is not set.
+const DefaultMinGroupSize = 3
+
+// IsAnonymous tells if the aggregated moods of people persons can be shown without revealing
+// any of them, that is if they are at least minGroupSize or nobody at all.
+// A minGroupSize of 1 or less shows everything.
+func IsAnonymous(people, minGroupSize int) bool {
+	return people == 0 || people >= minGroupSize
+}
+
+// CountPeopleWithMood counts the distinct people who shared a mood, days off left aside.
+func CountPeopleWithMood(moods []DailyMood) int {
+	people := map[uint]bool{}
+	for _, m := range moods {
+		if !m.IsOff() {
+			people[m.UserID] = true
+		}
+	}
+	return len(people)
+}
+
+// countUsersWithMood counts the users who shared a mood, days off left aside.
+func countUsersWithMood(userWithDailyMoods []*User) int {
+	people := 0
+	for _, u := range userWithDailyMoods {
+		for _, m := range u.Moods {
+			if !m.IsOff() {
+				people++
+				break
+			}
+		}
+	}
+	return people
+}
@@ -0,0 +1,86 @@
+package simba_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsAnonymous(t *testing.T) {
+	assert.True(t, simba.IsAnonymous(0, 3))
+	assert.False(t, simba.IsAnonymous(2, 3))
+	assert.True(t, simba.IsAnonymous(3, 3))
+	assert.True(t, simba.IsAnonymous(1, 1))
+	assert.True(t, simba.IsAnonymous(1, 0))
+}
+
+func TestCountPeopleWithMood(t *testing.T) {
+	moods := []simba.DailyMood{
+		{UserID: 1, Mood: "good_mood"},
+		{UserID: 1, Mood: "bad_mood"},
+		{UserID: 2, Mood: "good_mood"},
+		{UserID: 3, Status: simba.DailyMoodStatusOff},
+	}
+	assert.Equal(t, 2, simba.CountPeopleWithMood(moods))
+}
+
+func TestDailySummaryTextHidden(t *testing.T) {
+	users := fakeUsersWithMoods("good_mood", "bad_mood")
+	users = append(users, &simba.User{Moods: []simba.DailyMood{{Status: simba.DailyMoodStatusOff}}})
+	assert.Equal(
+		t,
+		"Fewer than 3 people shared their mood, percentages are hidden to keep everyone anonymous\n:zzz: 1 not working today",
+		simba.DailySummaryText(users, 3, "en"),
+	)
+	assert.Contains(t, simba.DailySummaryText(fakeUsersWithMoods("good_mood", "bad_mood", "bad_mood"), 3, "en"), "3 answers")
+}
+
+func TestComputeMoodStatsSuppressed(t *testing.T) {
+	stats := simba.ComputeMoodStats([]simba.DailyMood{
+		{UserID: 1, Mood: "good_mood"},
+		{UserID: 1, Mood: "good_mood"},
+		{UserID: 2, Mood: "bad_mood"},
+		{UserID: 3, Status: simba.DailyMoodStatusOff},
+	}, 3)
+	assert.True(t, stats.Suppressed)
+	assert.Equal(t, 0, stats.Answers)
+	assert.Equal(t, 0, stats.DaysOff)
+	assert.Equal(t, 0, stats.People)
+	assert.Nil(t, stats.Score)
+	assert.Equal(t, map[string]int{"good_mood": 0, "average_mood": 0, "bad_mood": 0}, stats.Moods)
+	assert.Empty(t, stats.Percentages)
+
+	assert.False(t, simba.ComputeMoodStats(nil, 3).Suppressed)
+}
+
+func TestComputeMoodStatsSuppressedFiltered(t *testing.T) {
+	// The bad moods and days off of a single person, as filtered by /stats?user=U&mood=bad_mood
+	stats := simba.ComputeMoodStats([]simba.DailyMood{
+		{UserID: 1, Mood: "bad_mood"},
+		{UserID: 1, Mood: "bad_mood"},
+		{UserID: 1, Mood: "bad_mood"},
+		{UserID: 1, Status: simba.DailyMoodStatusOff},
+	}, 3)
+	assert.True(t, stats.Suppressed)
+	assert.Equal(t, 0, stats.Answers)
+	assert.Equal(t, 0, stats.DaysOff)
+	assert.Equal(t, 0, stats.People)
+	assert.Equal(t, 0, stats.Moods["bad_mood"])
+	assert.Nil(t, stats.Score)
+}
+
+func TestComputeMoodTrendSuppressed(t *testing.T) {
+	from, to := utcDay(2026, time.October, 5), utcDay(2026, time.October, 18)
+	moods := []simba.DailyMood{
+		fakeMoodAt(1, "good_mood", utcDay(2026, time.October, 5)),
+		fakeMoodAt(2, "bad_mood", utcDay(2026, time.October, 6)),
+		fakeMoodAt(3, "bad_mood", utcDay(2026, time.October, 13)),
+	}
+	points := simba.ComputeMoodTrend(moods, nil, 3, 3, from, to)
+	assert.True(t, math.IsNaN(points[0].Score))
+	// The rolling window of the second week holds the moods of 3 people
+	assert.InDelta(t, 100.0/3, points[1].Score, 0.001)
+}
@@ -0,0 +1,273 @@
+package simba
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed quotes.json
+var bundledQuotes []byte
+
+const (
+	QuoteProviderBundled = "bundled"
+	QuoteProviderFile    = "file"
+	QuoteProviderURL     = "url"
+	QuoteProviderDB      = "db"
+)
+
+// quoteCacheTTL is how long fetched quotes are kept before asking the provider again.
+const quoteCacheTTL = 24 * time.Hour
+
+// quotesMaxSize is the most read from a quote url, more than enough for thousands of quotes.
+const quotesMaxSize = 1 << 20
+
+type Quote struct {
+	Author string `json:"author"`
+	Text   string `json:"text"`
+}
+
+// QuoteProvider gives the list of quotes the quote of the day is picked from.
+type QuoteProvider interface {
+	Quotes() ([]Quote, error)
+}
+
+// FileQuoteProvider reads quotes from a local JSON file or from the bundled one if Path is empty.
+type FileQuoteProvider struct {
+	Path string
+}
+
+func (fqp *FileQuoteProvider) Quotes() ([]Quote, error) {
+	content := bundledQuotes
+	if fqp.Path != "" {
+		var err error
+		if content, err = os.ReadFile(fqp.Path); err != nil {
+			return nil, err
+		}
+	}
+	return decodeQuotes(bytes.NewReader(content))
+}
+
+// URLQuoteProvider fetches quotes as JSON from an HTTP endpoint (ie: https://type.fit/api/quotes).
+type URLQuoteProvider struct {
+	URL        string
+	HTTPClient *http.Client
+}
+
+func (uqp *URLQuoteProvider) Quotes() ([]Quote, error) {
+	resp, err := uqp.HTTPClient.Get(uqp.URL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch quotes from %s failed with status %d", uqp.URL, resp.StatusCode)
+	}
+	// A bigger answer is cut and fails to decode
+	return decodeQuotes(io.LimitReader(resp.Body, quotesMaxSize))
+}
+
+// DBQuoteProvider uses the quotes managed in the stored_quotes table.
+type DBQuoteProvider struct {
+	DB *gorm.DB
+}
+
+func (dqp *DBQuoteProvider) Quotes() ([]Quote, error) {
+	var storedQuotes []StoredQuote
+	if tx := dqp.DB.Find(&storedQuotes); tx.Error != nil {
+		return nil, tx.Error
+	}
+	quotes := make([]Quote, len(storedQuotes))
+	for idx, sq := range storedQuotes {
+		quotes[idx] = Quote{Author: sq.Author, Text: sq.Text}
+	}
+	return quotes, nil
+}
+
+// cachedQuoteProvider keeps quotes of another provider in memory for ttl.
+type cachedQuoteProvider struct {
+	provider  QuoteProvider
+	ttl       time.Duration
+	mu        sync.Mutex
+	quotes    []Quote
+	fetchedAt time.Time
+}
+
+// NewCachedQuoteProvider wraps provider so it is called at most once per ttl.
+func NewCachedQuoteProvider(provider QuoteProvider, ttl time.Duration) QuoteProvider {
+	return &cachedQuoteProvider{provider: provider, ttl: ttl}
+}
+
+func (cqp *cachedQuoteProvider) Quotes() ([]Quote, error) {
+	cqp.mu.Lock()
+	defer cqp.mu.Unlock()
+	if len(cqp.quotes) > 0 && time.Since(cqp.fetchedAt) < cqp.ttl {
+		return cqp.quotes, nil
+	}
+	quotes, err := cqp.provider.Quotes()
+	if err != nil {
+		return nil, err
+	}
+	cqp.quotes = quotes
+	cqp.fetchedAt = time.Now()
+	return quotes, nil
+}
+
+// channelQuoteProvider is the provider of a channel along with what it has been made from.
+type channelQuoteProvider struct {
+	kind     string
+	source   string
+	provider QuoteProvider
+}
+
+var (
+	quoteProvidersMu sync.Mutex
+	// quoteProviders keeps a provider per channel, replaced when the channel changes of provider
+	quoteProviders = map[string]channelQuoteProvider{}
+)
+
+// NewQuoteProvider returns the cached provider of the given kind reading from source for channelId.
+func NewQuoteProvider(dbClient *gorm.DB, channelId, kind, source string) (QuoteProvider, error) {
+	quoteProvidersMu.Lock()
+	defer quoteProvidersMu.Unlock()
+
+	if cached, ok := quoteProviders[channelId]; ok && cached.kind == kind && cached.source == source {
+		return cached.provider, nil
+	}
+
+	var provider QuoteProvider
+	switch kind {
+	case QuoteProviderBundled, "":
+		provider = &FileQuoteProvider{}
+	case QuoteProviderFile:
+		provider = &FileQuoteProvider{Path: source}
+	case QuoteProviderURL:
+		if source == "" {
+			return nil, fmt.Errorf("quote provider url needs a source url")
+		} else if err := ValidatePublicURL(source); err != nil {
+			return nil, err
+		}
+		provider = &URLQuoteProvider{URL: source, HTTPClient: NewPublicHTTPClient(5 * time.Second)}
+	case QuoteProviderDB:
+		// Quotes are edited in DB so they are not kept in memory
+		return &DBQuoteProvider{DB: dbClient}, nil
+	default:
+		return nil, fmt.Errorf("quote provider %s does not exist", kind)
+	}
+
+	provider = NewCachedQuoteProvider(provider, quoteCacheTTL)
+	quoteProviders[channelId] = channelQuoteProvider{kind: kind, source: source, provider: provider}
+	return provider, nil
+}
+
+// QuoteProviderForChannel returns the provider chosen by the channel or the configured default one.
+func QuoteProviderForChannel(dbClient *gorm.DB, config *Config, channelId string) (QuoteProvider, error) {
+	kind, source := config.QUOTE_PROVIDER, config.QUOTE_SOURCE
+	setting, err := FetchChannelSetting(dbClient, channelId)
+	if err != nil {
+		return nil, err
+	} else if setting.QuoteProvider != "" {
+		kind, source = setting.QuoteProvider, setting.QuoteSource
+	}
+	return NewQuoteProvider(dbClient, channelId, kind, source)
+}
+
+// PickQuoteOfTheDay picks a random quote not shown in the channel for the last noRepeatDays
+// and records it as shown.
+func PickQuoteOfTheDay(
+	dbClient *gorm.DB,
+	provider QuoteProvider,
+	channelId string,
+	noRepeatDays int,
+) (*Quote, error) {
+	quotes, err := provider.Quotes()
+	if err != nil {
+		return nil, err
+	} else if len(quotes) == 0 {
+		return nil, fmt.Errorf("no quote available")
+	}
+
+	var recentTexts []string
+	since := time.Now().AddDate(0, 0, -noRepeatDays)
+	tx := dbClient.Model(&QuoteHistory{}).
+		Where("channel_id = ? AND created_at >= ?", channelId, since).
+		Pluck("text", &recentTexts)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	alreadyShown := make(map[string]bool, len(recentTexts))
+	for _, text := range recentTexts {
+		alreadyShown[text] = true
+	}
+	candidates := []Quote{}
+	for _, q := range quotes {
+		if !alreadyShown[q.Text] {
+			candidates = append(candidates, q)
+		}
+	}
+	if len(candidates) == 0 {
+		// Every quote has been shown recently, better repeat than no quote
+		candidates = quotes
+	}
+
+	quote := candidates[rand.Intn(len(candidates))]
+	history := &QuoteHistory{ChannelID: channelId, Author: quote.Author, Text: quote.Text}
+	if tx := dbClient.Create(history); tx.Error != nil {
+		return nil, tx.Error
+	}
+	return &quote, nil
+}
+
+// FetchCurrentQuote returns the last quote shown in the channel.
+func FetchCurrentQuote(dbClient *gorm.DB, channelId string) (*Quote, error) {
+	var history QuoteHistory
+	tx := dbClient.Where("channel_id = ?", channelId).Order("created_at DESC").Limit(1).Find(&history)
+	if tx.Error != nil {
+		return nil, tx.Error
+	} else if history.ID == 0 {
+		return nil, fmt.Errorf("no quote has been shown in %s", channelId)
+	}
+	return &Quote{Author: history.Author, Text: history.Text}, nil
+}
+
+func decodeQuotes(reader io.Reader) ([]Quote, error) {
+	var quotes []Quote
+	if err := json.NewDecoder(reader).Decode(&quotes); err != nil {
+		return nil, err
+	}
+	validQuotes := []Quote{}
+	for _, q := range quotes {
+		if strings.TrimSpace(q.Text) == "" {
+			continue
+		}
+		if q.Author == "" {
+			q.Author = "Unknown"
+		}
+		validQuotes = append(validQuotes, q)
+	}
+	return validQuotes, nil
+}
+
+type StoredQuote struct {
+	gorm.Model
+	Author string
+	Text   string
+}
+
+type QuoteHistory struct {
+	gorm.Model
+	ChannelID string `gorm:"index"`
+	Author    string
+	Text      string
+}
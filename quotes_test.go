@@ -0,0 +1,125 @@
+package simba_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingQuoteProvider struct {
+	calls int
+}
+
+func (cqp *countingQuoteProvider) Quotes() ([]simba.Quote, error) {
+	cqp.calls++
+	return []simba.Quote{{Author: "Simba", Text: "Meow"}}, nil
+}
+
+func TestFileQuoteProviderBundled(t *testing.T) {
+	quotes, err := (&simba.FileQuoteProvider{}).Quotes()
+	assert.Nil(t, err)
+	assert.NotEmpty(t, quotes)
+	for _, q := range quotes {
+		assert.NotEmpty(t, q.Text)
+		assert.NotEmpty(t, q.Author)
+	}
+}
+
+func TestFileQuoteProviderPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quotes.json")
+	content := `[{"text":"Meow","author":"Simba"},{"text":"Purr"},{"text":"  ","author":"Nobody"}]`
+	assert.Nil(t, os.WriteFile(path, []byte(content), 0o644))
+
+	quotes, err := (&simba.FileQuoteProvider{Path: path}).Quotes()
+	assert.Nil(t, err)
+	assert.Equal(t, []simba.Quote{{Author: "Simba", Text: "Meow"}, {Author: "Unknown", Text: "Purr"}}, quotes)
+
+	_, err = (&simba.FileQuoteProvider{Path: filepath.Join(t.TempDir(), "missing.json")}).Quotes()
+	assert.NotNil(t, err)
+}
+
+func TestURLQuoteProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/quotes" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"text":"Meow","author":"Simba"}]`))
+	}))
+	defer server.Close()
+
+	provider := &simba.URLQuoteProvider{URL: server.URL + "/quotes", HTTPClient: server.Client()}
+	quotes, err := provider.Quotes()
+	assert.Nil(t, err)
+	assert.Equal(t, []simba.Quote{{Author: "Simba", Text: "Meow"}}, quotes)
+
+	provider.URL = server.URL + "/nothing"
+	_, err = provider.Quotes()
+	assert.NotNil(t, err)
+}
+
+func TestCachedQuoteProvider(t *testing.T) {
+	counting := &countingQuoteProvider{}
+	provider := simba.NewCachedQuoteProvider(counting, time.Hour)
+	for i := 0; i < 3; i++ {
+		quotes, err := provider.Quotes()
+		assert.Nil(t, err)
+		assert.Len(t, quotes, 1)
+	}
+	assert.Equal(t, 1, counting.calls)
+
+	expired := simba.NewCachedQuoteProvider(counting, 0)
+	_, _ = expired.Quotes()
+	_, _ = expired.Quotes()
+	assert.Equal(t, 3, counting.calls)
+}
+
+func TestNewQuoteProvider(t *testing.T) {
+	_, err := simba.NewQuoteProvider(nil, "C1", "unknown", "")
+	assert.NotNil(t, err)
+	_, err = simba.NewQuoteProvider(nil, "C1", simba.QuoteProviderURL, "")
+	assert.NotNil(t, err)
+	_, err = simba.NewQuoteProvider(nil, "C1", simba.QuoteProviderURL, "http://example.com/quotes.json")
+	assert.NotNil(t, err)
+	_, err = simba.NewQuoteProvider(nil, "C1", simba.QuoteProviderURL, "https://127.0.0.1/quotes.json")
+	assert.ErrorIs(t, err, simba.ErrInternalAddress)
+
+	provider, err := simba.NewQuoteProvider(nil, "C1", simba.QuoteProviderBundled, "")
+	assert.Nil(t, err)
+	sameProvider, err := simba.NewQuoteProvider(nil, "C1", simba.QuoteProviderBundled, "")
+	assert.Nil(t, err)
+	assert.Same(t, provider, sameProvider)
+
+	// Changing the provider of the channel replaces the cached one
+	fileProvider, err := simba.NewQuoteProvider(nil, "C1", simba.QuoteProviderFile, "quotes.json")
+	assert.Nil(t, err)
+	assert.NotSame(t, provider, fileProvider)
+	bundledAgain, err := simba.NewQuoteProvider(nil, "C1", simba.QuoteProviderBundled, "")
+	assert.Nil(t, err)
+	assert.NotSame(t, provider, bundledAgain)
+}
+
+func TestURLQuoteProviderTooBig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"author":"Simba","text":"`))
+		w.Write(bytes.Repeat([]byte("Meow "), 1<<20))
+		w.Write([]byte(`"}]`))
+	}))
+	defer server.Close()
+
+	provider := &simba.URLQuoteProvider{URL: server.URL, HTTPClient: server.Client()}
+	_, err := provider.Quotes()
+	assert.NotNil(t, err)
+}
+
+func TestSetChannelQuoteProviderRefusesFiles(t *testing.T) {
+	_, err := simba.SetChannelQuoteProvider(nil, "C1", simba.QuoteProviderFile, "/etc/passwd")
+	assert.NotNil(t, err)
+}
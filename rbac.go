@@ -0,0 +1,165 @@
+package simba
+
+import (
+	"fmt"
+
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+const (
+	RoleOwner   = "owner"
+	RoleAdmin   = "admin"
+	RoleManager = "manager"
+	RoleMember  = "member"
+	// RoleViewer can look at the team but not act on it
+	RoleViewer = "viewer"
+)
+
+// Roles are sorted from the most to the least powerful.
+var Roles = []string{RoleOwner, RoleAdmin, RoleManager, RoleMember, RoleViewer}
+
+const (
+	// PermViewTeam is the admin Home tab and the dashboard
+	PermViewTeam = "team:view"
+	// PermViewEveryone ignores the hierarchy and sees the whole team
+	PermViewEveryone = "team:view_everyone"
+	PermReachOut     = "people:reach_out"
+	PermExport       = "moods:export"
+	// PermManageSettings covers the channel settings changed by mentioning Simba
+	PermManageSettings  = "settings:manage"
+	PermManageHierarchy = "hierarchy:manage"
+	PermManageTokens    = "tokens:manage"
+	PermManageRoles     = "roles:manage"
+	PermManageWebhooks  = "webhooks:manage"
+)
+
+var rolePermissions = map[string][]string{
+	RoleOwner: {
+		PermViewTeam, PermViewEveryone, PermReachOut, PermExport,
+		PermManageSettings, PermManageHierarchy, PermManageTokens, PermManageRoles, PermManageWebhooks,
+	},
+	RoleAdmin: {
+		PermViewTeam, PermViewEveryone, PermReachOut, PermExport,
+		PermManageSettings, PermManageHierarchy, PermManageTokens, PermManageRoles, PermManageWebhooks,
+	},
+	RoleManager: {PermViewTeam, PermReachOut, PermExport, PermManageSettings},
+	RoleMember:  {},
+	RoleViewer:  {PermViewTeam, PermViewEveryone},
+}
+
+// IsRole tells if role is one of Roles.
+func IsRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// Can tells if role grants permission.
+func Can(role, permission string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleOf returns the role of someone: the one assigned in Simba, or else the one
+// deduced from Slack (owners and admins of the workspace) and from the hierarchy.
+// user and slackUser may be nil when unknown.
+func RoleOf(user *User, slackUser *slack.User) string {
+	switch {
+	case user != nil && IsRole(user.Role):
+		return user.Role
+	case slackUser != nil && (slackUser.IsOwner || slackUser.IsPrimaryOwner):
+		return RoleOwner
+	case slackUser != nil && slackUser.IsAdmin:
+		return RoleAdmin
+	case user != nil && user.IsManager:
+		return RoleManager
+	}
+	return RoleMember
+}
+
+// FetchRole returns the role of slackUserId along with who they are in the DB and in Slack.
+func FetchRole(dbClient *gorm.DB, slackClient *slack.Client, slackUserId string) (string, *User, *slack.User, error) {
+	user, slackUser, err := FechCurrent(dbClient, slackClient, slackUserId)
+	if err != nil {
+		return "", nil, nil, err
+	}
+	return RoleOf(user, slackUser), user, slackUser, nil
+}
+
+// CanAssignRole tells if someone having actorRole can move someone from currentRole to role.
+// Only owners can make or unmake owners and admins.
+func CanAssignRole(actorRole, currentRole, role string) bool {
+	if !Can(actorRole, PermManageRoles) || !IsRole(role) {
+		return false
+	}
+	if actorRole == RoleOwner {
+		return true
+	}
+	for _, r := range []string{currentRole, role} {
+		if r == RoleOwner || r == RoleAdmin {
+			return false
+		}
+	}
+	return true
+}
+
+// RoleChange is the audit trail of the roles assigned in Simba.
+type RoleChange struct {
+	gorm.Model
+	SlackUserID          string `gorm:"index"`
+	PreviousRole         string
+	Role                 string
+	ChangedBySlackUserID string
+}
+
+// AssignRole gives role to slackUserId and records who did it, previousRole being the role they had.
+func AssignRole(dbClient *gorm.DB, slackUserId, role, previousRole, bySlackUserId string) (*RoleChange, error) {
+	if !IsRole(role) {
+		return nil, fmt.Errorf("%w : %s", ErrUnknownRole, role)
+	}
+	var user User
+	if tx := dbClient.Find(&user, "slack_user_id = ?", slackUserId); tx.Error != nil {
+		return nil, tx.Error
+	} else if user.ID == 0 {
+		return nil, fmt.Errorf("%w : %s", ErrUnknownUser, slackUserId)
+	}
+
+	change := &RoleChange{
+		SlackUserID:          slackUserId,
+		PreviousRole:         previousRole,
+		Role:                 role,
+		ChangedBySlackUserID: bySlackUserId,
+	}
+	err := dbClient.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("role", role).Error; err != nil {
+			return err
+		}
+		return tx.Create(change).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// FetchRoleAssignments returns the people having a role assigned in Simba, sorted by username.
+func FetchRoleAssignments(dbClient *gorm.DB) ([]*User, error) {
+	var users []*User
+	if tx := dbClient.Where("role <> ''").Order("username").Find(&users); tx.Error != nil {
+		return nil, tx.Error
+	}
+	return users, nil
+}
+
+// FetchRoleChanges returns the last limit role changes, the most recent first.
+func FetchRoleChanges(dbClient *gorm.DB, limit int) ([]RoleChange, error) {
+	var changes []RoleChange
+	if tx := dbClient.Order("created_at DESC").Limit(limit).Find(&changes); tx.Error != nil {
+		return nil, tx.Error
+	}
+	return changes, nil
+}
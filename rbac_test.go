@@ -0,0 +1,55 @@
+package simba_test
+
+import (
+	"testing"
+
+	"github.com/saisona/simba"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleOf(t *testing.T) {
+	assert.Equal(t, simba.RoleMember, simba.RoleOf(nil, nil))
+	assert.Equal(t, simba.RoleMember, simba.RoleOf(&simba.User{}, &slack.User{}))
+	assert.Equal(t, simba.RoleManager, simba.RoleOf(&simba.User{IsManager: true}, &slack.User{}))
+	assert.Equal(t, simba.RoleAdmin, simba.RoleOf(&simba.User{IsManager: true}, &slack.User{IsAdmin: true}))
+	assert.Equal(t, simba.RoleOwner, simba.RoleOf(nil, &slack.User{IsAdmin: true, IsOwner: true}))
+	assert.Equal(t, simba.RoleOwner, simba.RoleOf(nil, &slack.User{IsPrimaryOwner: true}))
+}
+
+func TestRoleOfAssigned(t *testing.T) {
+	assert.Equal(t, simba.RoleViewer, simba.RoleOf(&simba.User{Role: simba.RoleViewer}, &slack.User{IsAdmin: true}))
+	assert.Equal(t, simba.RoleAdmin, simba.RoleOf(&simba.User{Role: simba.RoleAdmin}, &slack.User{}))
+	// A role Simba does not know about falls back on Slack
+	assert.Equal(t, simba.RoleMember, simba.RoleOf(&simba.User{Role: "superhero"}, &slack.User{}))
+}
+
+func TestCan(t *testing.T) {
+	for _, role := range simba.Roles {
+		assert.True(t, simba.IsRole(role))
+	}
+	assert.False(t, simba.IsRole(""))
+
+	assert.True(t, simba.Can(simba.RoleOwner, simba.PermManageRoles))
+	assert.True(t, simba.Can(simba.RoleAdmin, simba.PermManageTokens))
+	assert.True(t, simba.Can(simba.RoleManager, simba.PermReachOut))
+	assert.False(t, simba.Can(simba.RoleManager, simba.PermViewEveryone))
+	assert.False(t, simba.Can(simba.RoleManager, simba.PermManageHierarchy))
+	assert.True(t, simba.Can(simba.RoleViewer, simba.PermViewTeam))
+	assert.False(t, simba.Can(simba.RoleViewer, simba.PermExport))
+	assert.False(t, simba.Can(simba.RoleViewer, simba.PermReachOut))
+	assert.False(t, simba.Can(simba.RoleMember, simba.PermViewTeam))
+	assert.False(t, simba.Can("superhero", simba.PermViewTeam))
+}
+
+func TestCanAssignRole(t *testing.T) {
+	assert.True(t, simba.CanAssignRole(simba.RoleOwner, simba.RoleMember, simba.RoleOwner))
+	assert.True(t, simba.CanAssignRole(simba.RoleOwner, simba.RoleAdmin, simba.RoleViewer))
+	assert.True(t, simba.CanAssignRole(simba.RoleAdmin, simba.RoleMember, simba.RoleManager))
+	assert.True(t, simba.CanAssignRole(simba.RoleAdmin, simba.RoleManager, simba.RoleViewer))
+
+	assert.False(t, simba.CanAssignRole(simba.RoleAdmin, simba.RoleMember, simba.RoleAdmin))
+	assert.False(t, simba.CanAssignRole(simba.RoleAdmin, simba.RoleOwner, simba.RoleMember))
+	assert.False(t, simba.CanAssignRole(simba.RoleManager, simba.RoleMember, simba.RoleViewer))
+	assert.False(t, simba.CanAssignRole(simba.RoleOwner, simba.RoleMember, "superhero"))
+}
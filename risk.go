@@ -0,0 +1,276 @@
+package simba
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+const (
+	// RiskRecentMoods is the number of latest moods looked at by the drop and feelings rules
+	RiskRecentMoods = 5
+	// RiskBaselineMoods is the number of moods before the recent ones making the baseline
+	RiskBaselineMoods = 20
+
+	RiskReasonConsecutive = "consecutive"
+	RiskReasonDrop        = "drop"
+	RiskReasonFeelings    = "feelings"
+)
+
+// RiskRules are the thresholds of the burnout risk detector, a zero value disables a rule.
+type RiskRules struct {
+	// ConsecutiveBadMoods in a row, days off excluded
+	ConsecutiveBadMoods int
+	// BaselineDrop is the score drop (out of 100) of the recent moods against the baseline
+	BaselineDrop int
+	// TiredRatio is the percentage of Tired or Frustrated feelings in the recent moods
+	TiredRatio int
+	// Cooldown between two alerts about the same person
+	Cooldown time.Duration
+}
+
+// BurnoutRisk is a person whose recent moods match at least one rule.
+type BurnoutRisk struct {
+	User    *User
+	Reasons []string
+}
+
+// DetectBurnoutRisk returns the rules matched by the moods of a person, newest first.
+func DetectBurnoutRisk(moods []DailyMood, rules *RiskRules) []string {
+	filtered := []DailyMood{}
+	for _, m := range moods {
+		if !m.IsOff() {
+			filtered = append(filtered, m)
+		}
+	}
+
+	reasons := []string{}
+	if n := rules.ConsecutiveBadMoods; n > 0 && len(filtered) >= n {
+		consecutive := true
+		for _, m := range filtered[:n] {
+			consecutive = consecutive && m.Mood == "bad_mood"
+		}
+		if consecutive {
+			reasons = append(reasons, RiskReasonConsecutive)
+		}
+	}
+
+	if len(filtered) < RiskRecentMoods {
+		return reasons
+	}
+	recent, baseline := filtered[:RiskRecentMoods], filtered[RiskRecentMoods:]
+	if len(baseline) > RiskBaselineMoods {
+		baseline = baseline[:RiskBaselineMoods]
+	}
+	// A baseline shorter than the recent window says nothing about the person's habits
+	if rules.BaselineDrop > 0 && len(baseline) >= RiskRecentMoods {
+		if averageScore(baseline)-averageScore(recent) >= float64(rules.BaselineDrop) {
+			reasons = append(reasons, RiskReasonDrop)
+		}
+	}
+
+	if rules.TiredRatio > 0 {
+		tired := 0
+		for _, m := range recent {
+			if m.Feeling == "Tired" || m.Feeling == "Frustrated" {
+				tired++
+			}
+		}
+		if tired*100 >= rules.TiredRatio*len(recent) {
+			reasons = append(reasons, RiskReasonFeelings)
+		}
+	}
+	return reasons
+}
+
+func averageScore(moods []DailyMood) float64 {
+	sum := 0.0
+	for _, m := range moods {
+		sum += moodScores[m.Mood]
+	}
+	return sum / float64(len(moods))
+}
+
+// FetchBurnoutRisks runs the detector against every person not paused at now.
+func FetchBurnoutRisks(dbClient *gorm.DB, rules *RiskRules, now time.Time) ([]BurnoutRisk, error) {
+	var users []*User
+	if tx := dbClient.Find(&users); tx.Error != nil {
+		return nil, tx.Error
+	}
+
+	risks := []BurnoutRisk{}
+	for _, u := range users {
+		if u.IsPaused(now) {
+			continue
+		}
+		moods, err := FetchLastMoodsBySlackUserId(dbClient, u.SlackUserID, RiskRecentMoods+RiskBaselineMoods)
+		if err != nil {
+			return nil, err
+		}
+		if reasons := DetectBurnoutRisk(moods, rules); len(reasons) > 0 {
+			risks = append(risks, BurnoutRisk{User: u, Reasons: reasons})
+		}
+	}
+	return risks, nil
+}
+
+// IsRiskAlertCoolingDown tells if an alert about slackUserId has been sent less than cooldown ago.
+func IsRiskAlertCoolingDown(
+	dbClient *gorm.DB,
+	slackUserId string,
+	cooldown time.Duration,
+	now time.Time,
+) (bool, error) {
+	var count int64
+	tx := dbClient.Model(&RiskAlert{}).
+		Where("slack_user_id = ? AND sent_at > ?", slackUserId, now.Add(-cooldown)).
+		Count(&count)
+	if tx.Error != nil {
+		return false, tx.Error
+	}
+	return count > 0, nil
+}
+
+// FetchManagersOf returns the manager user reports to. While no hierarchy has been set up, it
+// returns the managers of the channel of user instead, user excluded. Once there is one, someone
+// reporting to nobody has no manager: warning every manager of the channel would tell them about
+// people they cannot see.
+func FetchManagersOf(dbClient *gorm.DB, user *User) ([]*User, error) {
+	var managers []*User
+	if user.ManagerSlackUserID != "" {
+		if tx := dbClient.Where("slack_user_id = ?", user.ManagerSlackUserID).Find(&managers); tx.Error != nil {
+			return nil, tx.Error
+		} else if len(managers) > 0 {
+			return managers, nil
+		}
+	}
+	var users []*User
+	if tx := dbClient.Find(&users); tx.Error != nil {
+		return nil, tx.Error
+	} else if HasHierarchy(users) {
+		return []*User{}, nil
+	}
+	tx := dbClient.Where("is_manager = ? AND slack_channel_id = ? AND id <> ?", true, user.SlackChannelId, user.ID).
+		Find(&managers)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return managers, nil
+}
+
+// RiskReasons explains each matched rule in the language of the reader.
+func RiskReasons(reasons []string, rules *RiskRules, locale string) []string {
+	lines := []string{}
+	for _, reason := range reasons {
+		switch reason {
+		case RiskReasonConsecutive:
+			lines = append(lines, T(locale, "risk.reason.consecutive", rules.ConsecutiveBadMoods))
+		case RiskReasonDrop:
+			lines = append(lines, T(locale, "risk.reason.drop", RiskRecentMoods))
+		case RiskReasonFeelings:
+			lines = append(lines, T(locale, "risk.reason.feelings", RiskRecentMoods))
+		}
+	}
+	return lines
+}
+
+// RiskReasonsText lists the matched rules in the language of the reader.
+func RiskReasonsText(reasons []string, rules *RiskRules, locale string) string {
+	lines := []string{}
+	for _, line := range RiskReasons(reasons, rules, locale) {
+		lines = append(lines, "• "+line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// sendRiskAlert sends the confidential DM about risk to a manager, with a button to reach out.
+func sendRiskAlert(client *slack.Client, risk BurnoutRisk, rules *RiskRules, managerSlackId string) error {
+	locale := FetchUserLocale(client, managerSlackId)
+	text := T(locale, "risk.alert", risk.User.SlackUserID, RiskReasonsText(risk.Reasons, rules, locale))
+	outreachButton := slack.NewButtonBlockElement(
+		fmt.Sprintf("direct_message_%s", risk.User.SlackUserID),
+		risk.User.SlackUserID,
+		slackTextBlock(T(locale, "risk.reach_out")),
+	)
+	_, _, err := client.PostMessage(
+		managerSlackId,
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionBlocks(
+			slack.NewSectionBlock(slackMkDownBlock(text), nil, nil),
+			slack.NewContextBlock("", slackMkDownBlock(T(locale, "risk.confidential"))),
+			slack.NewActionBlock("risk_actions", outreachButton),
+		),
+	)
+	return err
+}
+
+// SendBurnoutRiskAlerts warns the managers of the people at risk, at most once per cooldown per person.
+func SendBurnoutRiskAlerts(dbClient *gorm.DB, client *slack.Client, config *Config) error {
+	now := time.Now()
+	risks, err := FetchBurnoutRisks(dbClient, config.RISK, now)
+	if err != nil {
+		return err
+	}
+
+	for _, risk := range risks {
+		coolingDown, err := IsRiskAlertCoolingDown(dbClient, risk.User.SlackUserID, config.RISK.Cooldown, now)
+		if err != nil {
+			return err
+		} else if coolingDown {
+			continue
+		}
+		managers, err := FetchManagersOf(dbClient, risk.User)
+		if err != nil {
+			return err
+		} else if len(managers) == 0 {
+			log.Printf("%s is at risk but has no manager to warn", risk.User.SlackUserID)
+			continue
+		}
+
+		warned := []string{}
+		for _, manager := range managers {
+			// Emails are sent whatever happens on Slack, for the managers who barely open it
+			emailed, err := sendRiskAlertEmail(client, config, risk, manager)
+			if err != nil {
+				log.Printf("#sendRiskAlertEmail to %s error => %s", manager.SlackUserID, err)
+			}
+			if err := sendRiskAlert(client, risk, config.RISK, manager.SlackUserID); err != nil {
+				log.Printf("#sendRiskAlert to %s error => %s", manager.SlackUserID, err)
+				if !emailed {
+					continue
+				}
+			}
+			warned = append(warned, manager.SlackUserID)
+			alert := &RiskAlert{
+				SlackUserID:        risk.User.SlackUserID,
+				ManagerSlackUserID: manager.SlackUserID,
+				Reasons:            strings.Join(risk.Reasons, ","),
+				SentAt:             now,
+			}
+			if tx := dbClient.Create(alert); tx.Error != nil {
+				return tx.Error
+			}
+		}
+
+		if len(warned) > 0 {
+			alert := WebhookAlertData{SlackUserID: risk.User.SlackUserID, Reasons: risk.Reasons, ManagerSlackUserIDs: warned}
+			if err := EmitWebhookEvent(dbClient, WebhookEventAlertTriggered, alert); err != nil {
+				log.Printf("#EmitWebhookEvent error => %s", err)
+			}
+		}
+	}
+	return nil
+}
+
+// RiskAlert records that a manager has been warned about someone, for the cooldown.
+type RiskAlert struct {
+	gorm.Model
+	SlackUserID        string `gorm:"index"`
+	ManagerSlackUserID string
+	Reasons            string
+	SentAt             time.Time
+}
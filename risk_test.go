@@ -0,0 +1,89 @@
+package simba_test
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+)
+
+var fakeRiskRules = &simba.RiskRules{ConsecutiveBadMoods: 3, BaselineDrop: 30, TiredRatio: 60}
+
+// fakeMoods builds moods newest first, all with the same feeling
+func fakeMoods(feeling string, moods ...string) []simba.DailyMood {
+	dailyMoods := []simba.DailyMood{}
+	for _, mood := range moods {
+		dailyMoods = append(dailyMoods, simba.DailyMood{Mood: mood, Feeling: feeling})
+	}
+	return dailyMoods
+}
+
+func TestDetectBurnoutRiskNone(t *testing.T) {
+	assert.Empty(t, simba.DetectBurnoutRisk(nil, fakeRiskRules))
+	moods := fakeMoods("Happy", "good_mood", "bad_mood", "bad_mood", "good_mood", "good_mood", "good_mood")
+	assert.Empty(t, simba.DetectBurnoutRisk(moods, fakeRiskRules))
+}
+
+func TestDetectBurnoutRiskConsecutive(t *testing.T) {
+	moods := fakeMoods("Sad", "bad_mood", "bad_mood", "bad_mood")
+	assert.Equal(t, []string{simba.RiskReasonConsecutive}, simba.DetectBurnoutRisk(moods, fakeRiskRules))
+
+	// Days off do not break the streak
+	moods = append(moods[:1], append([]simba.DailyMood{{Status: simba.DailyMoodStatusOff}}, moods[1:]...)...)
+	assert.Equal(t, []string{simba.RiskReasonConsecutive}, simba.DetectBurnoutRisk(moods, fakeRiskRules))
+
+	disabled := &simba.RiskRules{}
+	assert.Empty(t, simba.DetectBurnoutRisk(moods, disabled))
+}
+
+func TestDetectBurnoutRiskDrop(t *testing.T) {
+	moods := append(
+		fakeMoods("Neutral", "average_mood", "average_mood", "bad_mood", "average_mood", "average_mood"),
+		fakeMoods("Happy", "good_mood", "good_mood", "good_mood", "good_mood", "good_mood", "good_mood")...,
+	)
+	assert.Equal(t, []string{simba.RiskReasonDrop}, simba.DetectBurnoutRisk(moods, fakeRiskRules))
+
+	// Not enough history to know the usual mood
+	assert.Empty(t, simba.DetectBurnoutRisk(moods[:8], fakeRiskRules))
+}
+
+func TestDetectBurnoutRiskFeelings(t *testing.T) {
+	moods := fakeMoods("Tired", "average_mood", "average_mood", "average_mood")
+	moods = append(moods, fakeMoods("Happy", "good_mood", "good_mood")...)
+	assert.Equal(t, []string{simba.RiskReasonFeelings}, simba.DetectBurnoutRisk(moods, fakeRiskRules))
+	assert.Empty(t, simba.DetectBurnoutRisk(moods[1:], fakeRiskRules))
+}
+
+func TestRiskReasonsText(t *testing.T) {
+	assert.Equal(
+		t,
+		"• 3 bad moods in a row\n• often tired or frustrated over their last 5 moods",
+		simba.RiskReasonsText([]string{simba.RiskReasonConsecutive, simba.RiskReasonFeelings}, fakeRiskRules, "en"),
+	)
+}
+
+func TestFetchManagersOfFallback(t *testing.T) {
+	user := &simba.User{SlackUserID: "U1", SlackChannelId: "C1"}
+	columns := []string{"id", "slack_user_id", "is_manager", "manager_slack_user_id"}
+
+	// No hierarchy yet, the managers of the channel are warned
+	db, _ := newFakeGormDB(t, map[string]fakeTable{"users": {
+		Columns: columns,
+		Rows:    [][]driver.Value{{int64(2), "U2", true, ""}},
+	}})
+	managers, err := simba.FetchManagersOf(db, user)
+	assert.Nil(t, err)
+	if assert.Len(t, managers, 1) {
+		assert.Equal(t, "U2", managers[0].SlackUserID)
+	}
+
+	// With a hierarchy, someone reporting to nobody has no manager
+	db, _ = newFakeGormDB(t, map[string]fakeTable{"users": {
+		Columns: columns,
+		Rows:    [][]driver.Value{{int64(2), "U2", true, ""}, {int64(3), "U3", false, "U2"}},
+	}})
+	managers, err = simba.FetchManagersOf(db, user)
+	assert.Nil(t, err)
+	assert.Empty(t, managers)
+}
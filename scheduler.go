@@ -17,7 +17,23 @@ import (
 	"gorm.io/gorm"
 )
 
-func funcHandler(dbClient *gorm.DB, client *slack.Client, config *Config, threadTS string) error {
+// skipHoliday tells if today is a holiday of the channel, in which case nothing is posted.
+func skipHoliday(dbClient *gorm.DB, config *Config) bool {
+	isHoliday, err := IsHoliday(dbClient, config.CHANNEL_ID, time.Now())
+	if err != nil {
+		log.Printf("#IsHoliday error => %s", err)
+		return false
+	} else if isHoliday {
+		log.Printf("Today is a holiday for %s, skipping", config.CHANNEL_ID)
+	}
+	return isHoliday
+}
+
+func funcHandler(dbClient *gorm.DB, client *slack.Client, config *Config, threadTS string) (err error) {
+	defer func() { ObserveSchedulerRun("daily", err) }()
+	if skipHoliday(dbClient, config) {
+		return nil
+	}
 	threadTs, err := SendSlackBlocks(client, config, dbClient, threadTS, true)
 	if err != nil {
 		log.Printf("#SendSlackBlocks error => %s", err)
@@ -25,6 +41,60 @@ func funcHandler(dbClient *gorm.DB, client *slack.Client, config *Config, thread
 	}
 	// Sending threadTS
 	config.SLACK_MESSAGE_CHANNEL <- threadTs
+
+	poll := WebhookPollData{ChannelID: config.CHANNEL_ID, ThreadTS: threadTs}
+	if err := EmitWebhookEvent(dbClient, WebhookEventPollOpened, poll); err != nil {
+		log.Printf("#EmitWebhookEvent error => %s", err)
+	}
+
+	if giphyClient := NewGiphyClient(config); giphyClient != nil {
+		tag := config.GIPHY_TAG
+		if tag == "" {
+			tag = GiphyTagFromMood("")
+		}
+		if err := SendGif(client, giphyClient, config.CHANNEL_ID, threadTs, tag); err != nil {
+			log.Printf("#SendGif error => %s", err)
+		}
+	}
+	return nil
+}
+
+func summaryHandler(dbClient *gorm.DB, client *slack.Client, config *Config) (err error) {
+	defer func() { ObserveSchedulerRun("summary", err) }()
+	if skipHoliday(dbClient, config) {
+		return nil
+	}
+	if err := SendDailySummary(dbClient, client, config); err != nil {
+		log.Printf("#SendDailySummary error => %s", err)
+		return err
+	}
+	return nil
+}
+
+func weeklySummaryHandler(dbClient *gorm.DB, client *slack.Client, config *Config) (err error) {
+	defer func() { ObserveSchedulerRun("weekly_summary", err) }()
+	if err := SendWeeklySummary(dbClient, client, config); err != nil {
+		log.Printf("#SendWeeklySummary error => %s", err)
+		return err
+	}
+	return nil
+}
+
+func riskHandler(dbClient *gorm.DB, client *slack.Client, config *Config) (err error) {
+	defer func() { ObserveSchedulerRun("risk", err) }()
+	if err := SendBurnoutRiskAlerts(dbClient, client, config); err != nil {
+		log.Printf("#SendBurnoutRiskAlerts error => %s", err)
+		return err
+	}
+	return nil
+}
+
+func webhookHandler(dbClient *gorm.DB) (err error) {
+	defer func() { ObserveSchedulerRun("webhooks", err) }()
+	if err := DeliverDueWebhooks(dbClient, webhookHTTPClient, time.Now()); err != nil {
+		log.Printf("#DeliverDueWebhooks error => %s", err)
+		return err
+	}
 	return nil
 }
 
@@ -50,5 +120,44 @@ func InitScheduler(
 		return scheduler, job, err
 	}
 
+	if config.SUMMARY_CRON != "" {
+		if os.Getenv("APP_ENV") == "production" {
+			scheduler.CronWithSeconds(config.SUMMARY_CRON)
+		} else {
+			scheduler.Every(10).Minute()
+		}
+		if _, err := scheduler.Do(summaryHandler, dbClient, client, config); err != nil {
+			return scheduler, job, err
+		}
+	}
+
+	if config.WEEKLY_SUMMARY_CRON != "" {
+		if os.Getenv("APP_ENV") == "production" {
+			scheduler.CronWithSeconds(config.WEEKLY_SUMMARY_CRON)
+		} else {
+			scheduler.Every(30).Minute()
+		}
+		if _, err := scheduler.Do(weeklySummaryHandler, dbClient, client, config); err != nil {
+			return scheduler, job, err
+		}
+	}
+
+	if config.RISK_CRON != "" {
+		if os.Getenv("APP_ENV") == "production" {
+			scheduler.CronWithSeconds(config.RISK_CRON)
+		} else {
+			scheduler.Every(30).Minute()
+		}
+		if _, err := scheduler.Do(riskHandler, dbClient, client, config); err != nil {
+			return scheduler, job, err
+		}
+	}
+
+	// A slow endpoint must not get the same deliveries sent twice
+	scheduler.Every(WebhookPollInterval).SingletonMode()
+	if _, err := scheduler.Do(webhookHandler, dbClient); err != nil {
+		return scheduler, job, err
+	}
+
 	return scheduler, job, nil
 }
@@ -0,0 +1,122 @@
+package simba
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SlackSignIn signs people in with their Slack account through Sign in with Slack (OpenID Connect).
+type SlackSignIn struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	BaseURL      string
+	// TeamID is the only Slack workspace allowed to sign in, the one Simba is installed in
+	TeamID     string
+	HTTPClient *http.Client
+}
+
+// SlackIdentity is who signed in, as told by openid.connect.userInfo.
+type SlackIdentity struct {
+	SlackUserID string `json:"https://slack.com/user_id"`
+	TeamID      string `json:"https://slack.com/team_id"`
+	Name        string `json:"name"`
+	Locale      string `json:"locale"`
+}
+
+// NewSlackSignIn returns a client configured from config or nil when the dashboard is not configured.
+func NewSlackSignIn(config *Config) *SlackSignIn {
+	if config.SLACK_CLIENT_ID == "" || config.DASHBOARD_URL == "" {
+		return nil
+	}
+	return &SlackSignIn{
+		ClientID:     config.SLACK_CLIENT_ID,
+		ClientSecret: config.SLACK_CLIENT_SECRET,
+		RedirectURL:  config.DASHBOARD_URL + "/dashboard/callback",
+		BaseURL:      "https://slack.com",
+		HTTPClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// AuthorizeURL is where people are sent to sign in, state coming back untouched on the callback.
+func (s *SlackSignIn) AuthorizeURL(state string) string {
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("scope", "openid profile")
+	params.Set("client_id", s.ClientID)
+	params.Set("redirect_uri", s.RedirectURL)
+	params.Set("state", state)
+	return fmt.Sprintf("%s/openid/connect/authorize?%s", s.BaseURL, params.Encode())
+}
+
+// slackOpenIDResponse is the envelope of the openid.connect.* methods.
+type slackOpenIDResponse struct {
+	Ok          bool   `json:"ok"`
+	Error       string `json:"error"`
+	AccessToken string `json:"access_token"`
+	SlackIdentity
+}
+
+func (s *SlackSignIn) call(req *http.Request) (*slackOpenIDResponse, error) {
+	resp, err := s.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var openIDResponse slackOpenIDResponse
+	if err := json.NewDecoder(resp.Body).Decode(&openIDResponse); err != nil {
+		return nil, err
+	} else if resp.StatusCode != http.StatusOK || !openIDResponse.Ok {
+		return nil, fmt.Errorf(
+			"%s failed with status %d : %s",
+			req.URL.Path,
+			resp.StatusCode,
+			openIDResponse.Error,
+		)
+	}
+	return &openIDResponse, nil
+}
+
+// Exchange trades the code of the callback for the identity of who signed in, who must be a
+// member of the workspace TeamID since any Slack account can go through Sign in with Slack.
+func (s *SlackSignIn) Exchange(code string) (*SlackIdentity, error) {
+	form := url.Values{}
+	form.Set("client_id", s.ClientID)
+	form.Set("client_secret", s.ClientSecret)
+	form.Set("code", code)
+	form.Set("redirect_uri", s.RedirectURL)
+	form.Set("grant_type", "authorization_code")
+	tokenReq, err := http.NewRequest(
+		http.MethodPost,
+		s.BaseURL+"/api/openid.connect.token",
+		strings.NewReader(form.Encode()),
+	)
+	if err != nil {
+		return nil, err
+	}
+	tokenReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	token, err := s.call(tokenReq)
+	if err != nil {
+		return nil, err
+	}
+
+	userInfoReq, err := http.NewRequest(http.MethodGet, s.BaseURL+"/api/openid.connect.userInfo", nil)
+	if err != nil {
+		return nil, err
+	}
+	userInfoReq.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	userInfo, err := s.call(userInfoReq)
+	if err != nil {
+		return nil, err
+	} else if userInfo.SlackUserID == "" {
+		return nil, fmt.Errorf("openid.connect.userInfo did not return a Slack user id")
+	} else if s.TeamID == "" || userInfo.TeamID != s.TeamID {
+		return nil, fmt.Errorf("%w : %s signed in with %s", ErrWrongTeam, userInfo.SlackUserID, userInfo.TeamID)
+	}
+	return &userInfo.SlackIdentity, nil
+}
@@ -0,0 +1,93 @@
+package simba_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeSlackSignIn(baseURL string) *simba.SlackSignIn {
+	return &simba.SlackSignIn{
+		ClientID:     "client-id",
+		ClientSecret: "client-secret",
+		RedirectURL:  "https://simba.example.com/dashboard/callback",
+		BaseURL:      baseURL,
+		TeamID:       "T0001",
+		HTTPClient:   http.DefaultClient,
+	}
+}
+
+func TestNewSlackSignInNotConfigured(t *testing.T) {
+	assert.Nil(t, simba.NewSlackSignIn(&simba.Config{}))
+	assert.Nil(t, simba.NewSlackSignIn(&simba.Config{SLACK_CLIENT_ID: "client-id"}))
+
+	signIn := simba.NewSlackSignIn(&simba.Config{
+		SLACK_CLIENT_ID: "client-id",
+		DASHBOARD_URL:   "https://simba.example.com",
+	})
+	assert.NotNil(t, signIn)
+	assert.Equal(t, "https://simba.example.com/dashboard/callback", signIn.RedirectURL)
+}
+
+func TestSlackSignInAuthorizeURL(t *testing.T) {
+	authorizeURL, err := url.Parse(fakeSlackSignIn("https://slack.com").AuthorizeURL("some-state"))
+	assert.Nil(t, err)
+	assert.Equal(t, "/openid/connect/authorize", authorizeURL.Path)
+	query := authorizeURL.Query()
+	assert.Equal(t, "code", query.Get("response_type"))
+	assert.Equal(t, "openid profile", query.Get("scope"))
+	assert.Equal(t, "client-id", query.Get("client_id"))
+	assert.Equal(t, "some-state", query.Get("state"))
+	assert.Equal(t, "https://simba.example.com/dashboard/callback", query.Get("redirect_uri"))
+}
+
+func TestSlackSignInExchange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/openid.connect.token":
+			if r.FormValue("code") != "good-code" || r.FormValue("client_secret") != "client-secret" {
+				w.Write([]byte(`{"ok":false,"error":"invalid_code"}`))
+				return
+			}
+			w.Write([]byte(`{"ok":true,"access_token":"xoxp-token"}`))
+		case "/api/openid.connect.userInfo":
+			if r.Header.Get("Authorization") != "Bearer xoxp-token" {
+				w.Write([]byte(`{"ok":false,"error":"invalid_auth"}`))
+				return
+			}
+			w.Write([]byte(`{
+				"ok": true,
+				"sub": "U0001",
+				"https://slack.com/user_id": "U0001",
+				"https://slack.com/team_id": "T0001",
+				"name": "Simba",
+				"locale": "fr-FR"
+			}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	signIn := fakeSlackSignIn(server.URL)
+
+	identity, err := signIn.Exchange("good-code")
+	assert.Nil(t, err)
+	assert.Equal(t, &simba.SlackIdentity{SlackUserID: "U0001", TeamID: "T0001", Name: "Simba", Locale: "fr-FR"}, identity)
+
+	identity, err = signIn.Exchange("wrong-code")
+	assert.Nil(t, identity)
+	assert.ErrorContains(t, err, "invalid_code")
+
+	signIn.TeamID = "T0002"
+	identity, err = signIn.Exchange("good-code")
+	assert.Nil(t, identity)
+	assert.ErrorIs(t, err, simba.ErrWrongTeam)
+
+	signIn.TeamID = ""
+	_, err = signIn.Exchange("good-code")
+	assert.ErrorIs(t, err, simba.ErrWrongTeam)
+}
@@ -8,13 +8,10 @@
 package simba
 
 import (
-	"encoding/json"
-	"errors"
+	"bytes"
 	"fmt"
+	"io"
 	"log"
-	"math/rand"
-	"net/http"
-	"os"
 	"strings"
 	"time"
 
@@ -44,31 +41,57 @@ func slackTextBlock(text string) *slack.TextBlockObject {
 	return textObject
 }
 
-func SendImage(client *slack.Client, channelId, filePath, title, comment string) error {
-	if fileInfo, err := os.Stat(filePath); errors.Is(err, os.ErrNotExist) {
-		return fmt.Errorf("file %s does not exists", filePath)
-	} else {
-		sizeMo := fileInfo.Size() / 1e5
-		sizeRest := fileInfo.Size() % 1e5
-		log.Printf("Uploading %d,%dMo file", sizeMo, sizeRest)
-	}
-
-	file, err := client.UploadFile(
-		slack.FileUploadParameters{
-			File:           filePath,
-			Title:          title,
-			InitialComment: comment,
-			Content:        "",
-			Channels:       []string{channelId},
-		},
-	)
+// SendImage uploads the image read from reader to the channel (in thread if threadTS is set).
+func SendImage(
+	client *slack.Client,
+	channelId, threadTS string,
+	reader io.Reader,
+	filename, title, comment string,
+) error {
+	file, err := uploadImage(client, reader, slack.UploadFileV2Parameters{
+		Filename:        filename,
+		Title:           title,
+		AltTxt:          title,
+		InitialComment:  comment,
+		Channel:         channelId,
+		ThreadTimestamp: threadTS,
+	})
 	if err != nil {
 		return err
 	}
-	log.Printf("mimeType=%s\n", file.Mimetype)
+	log.Printf("Uploaded %s as %s", filename, file.ID)
 	return nil
 }
 
+// UploadImage uploads an image to Slack without sharing it in a channel,
+// so it can be shown in image blocks with its file id.
+func UploadImage(slackClient *slack.Client, reader io.Reader, filename, title string) (*slack.FileSummary, error) {
+	return uploadImage(slackClient, reader, slack.UploadFileV2Parameters{
+		Filename: filename,
+		Title:    title,
+		AltTxt:   title,
+	})
+}
+
+// uploadImage reads reader to know its size, as the external upload flow needs it first.
+func uploadImage(
+	slackClient *slack.Client,
+	reader io.Reader,
+	params slack.UploadFileV2Parameters,
+) (*slack.FileSummary, error) {
+	var content bytes.Buffer
+	size, err := io.Copy(&content, reader)
+	if err != nil {
+		return nil, err
+	} else if size == 0 {
+		return nil, fmt.Errorf("image %s is empty", params.Filename)
+	}
+	log.Printf("Uploading %s (%d bytes)", params.Filename, size)
+	params.Reader = &content
+	params.FileSize = int(size)
+	return slackClient.UploadFileV2(params)
+}
+
 func SendSlackTSMessage(
 	client *slack.Client,
 	config *Config,
@@ -102,6 +125,18 @@ func SendSlackTSMessageToUser(
 	return threadTS, nil
 }
 
+func SendSlackThreadReply(client *slack.Client, channelId, message, ts string) (string, error) {
+	_, threadTS, _, err := client.SendMessage(
+		channelId,
+		slackTextObject(message),
+		slack.MsgOptionTS(ts),
+	)
+	if err != nil {
+		return "", err
+	}
+	return threadTS, nil
+}
+
 func SendSlackMessage(client *slack.Client, config *Config, message string) (string, error) {
 	_, threadTS, _, err := client.SendMessage(config.CHANNEL_ID, slackTextObject(message))
 	if err != nil {
@@ -119,9 +154,7 @@ func SendSlackMessageToUser(client *slack.Client, userId, message string) (strin
 }
 
 func SendErrorMessageToUser(client *slack.Client, userId string, errToSend error) (string, error) {
-	errMessageThread := fmt.Sprintf(
-		"[ERROR] An error occured with your action please contact your admin. Info in the thread",
-	)
+	errMessageThread := T(FetchUserLocale(client, userId), "error.generic")
 	errMessage := fmt.Sprintf("%s", errToSend.Error())
 
 	threadTS, err := SendSlackMessageToUser(client, userId, errMessageThread)
@@ -136,61 +169,54 @@ func SendErrorMessageToUser(client *slack.Client, userId string, errToSend error
 	return threadTS, nil
 }
 
-func fetchQuoteOfTheDay() (string, string, error) {
-	req, err := http.NewRequest("GET", "https://type.fit/api/quotes", nil)
-	if err != nil {
-		return "", "", err
+// quoteOfTheDay picks a new quote for a new daily mood, or keeps the one already shown on updates
+func quoteOfTheDay(dbClient *gorm.DB, config *Config, firstPrint bool) (*Quote, error) {
+	if !firstPrint {
+		if quote, err := FetchCurrentQuote(dbClient, config.CHANNEL_ID); err == nil {
+			return quote, nil
+		}
 	}
-	resp, err := http.DefaultClient.Do(req)
+	provider, err := QuoteProviderForChannel(dbClient, config, config.CHANNEL_ID)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
-	respJson := make([]map[string]string, 50)
-
-	reader := json.NewDecoder(resp.Body)
-	if err = reader.Decode(&respJson); err != nil {
-		return "", "", err
-	}
-
-	min := 0
-	max := len(respJson)
-	index := (rand.Intn(max-min) + min)
-	return respJson[index]["author"], respJson[index]["text"], nil
+	return PickQuoteOfTheDay(dbClient, provider, config.CHANNEL_ID, config.QUOTE_NO_REPEAT_DAYS)
 }
 
-func AddingContextAuthor(authorName string) *slack.ContextBlock {
+func AddingContextAuthor(authorName, locale string) *slack.ContextBlock {
 	blockId := fmt.Sprintf("author_context_qotd_%d", time.Now().UnixMilli())
-	textBlock := slack.NewTextBlockObject("plain_text", "From "+authorName, true, false)
+	textBlock := slack.NewTextBlockObject(
+		"plain_text",
+		T(locale, "checkin.quote_author", authorName),
+		true,
+		false,
+	)
 	return slack.NewContextBlock(blockId, textBlock)
 }
 
-func firstSectionBlock() (string, *slack.SectionBlock) {
-	author, qotd, err := fetchQuoteOfTheDay()
+func firstSectionBlock(dbClient *gorm.DB, config *Config, firstPrint bool) (string, *slack.SectionBlock) {
+	quote, err := quoteOfTheDay(dbClient, config, firstPrint)
 	if err != nil {
-		qotd = "Meow"
-		author = "Simba"
+		quote = &Quote{Author: "Simba", Text: "Meow"}
 		log.Printf("Failed fetch Quote of the Day = %s", err.Error())
 	}
-	quoteOfTheDay := fmt.Sprintf(
-		"Hey folks! What is your mood today:\nQuote of the Day: *%s*",
-		qotd,
-	)
+	quoteOfTheDay := T(config.LOCALE, "checkin.title", quote.Text)
 	firstLine := slackMkDownBlock(quoteOfTheDay)
 	sectionBlock := slack.NewSectionBlock(firstLine, nil, nil)
-	return author, sectionBlock
+	return quote.Author, sectionBlock
 }
 
 func FetchUserById(slackClient *slack.Client, userId string) (*slack.User, error) {
 	return slackClient.GetUserInfo(userId)
 }
 
-func actionSectionBlock() *slack.ActionBlock {
+func actionSectionBlock(locale string) *slack.ActionBlock {
 	timeNow := time.Now().UnixMilli()
 	actionBlockId := fmt.Sprintf("action_block_mood_user_%d", timeNow)
 
 	goodMoodButtonText := slack.NewTextBlockObject(
 		slack.PlainTextType,
-		"Good Mood :heart:",
+		MoodLabel("good_mood", locale),
 		true,
 		false,
 	)
@@ -210,7 +236,7 @@ func actionSectionBlock() *slack.ActionBlock {
 
 	averageMoodButtonText := slack.NewTextBlockObject(
 		slack.PlainTextType,
-		"Meow :yellow_heart:",
+		MoodLabel("average_mood", locale),
 		true,
 		false,
 	)
@@ -230,7 +256,7 @@ func actionSectionBlock() *slack.ActionBlock {
 
 	badMoodButtonText := slack.NewTextBlockObject(
 		slack.PlainTextType,
-		"Grr ! :black_heart:",
+		MoodLabel("bad_mood", locale),
 		true,
 		false,
 	)
@@ -248,14 +274,35 @@ func actionSectionBlock() *slack.ActionBlock {
 		return nil
 	}
 
-	return slack.NewActionBlock(actionBlockId, goodMoodButton, averageMoodButton, badMoodButton)
+	dayOffButtonText := slack.NewTextBlockObject(slack.PlainTextType, T(locale, "checkin.day_off"), true, false)
+	dayOffButton := slack.NewButtonBlockElement(
+		fmt.Sprintf("day_off_%d", timeNow),
+		DailyMoodStatusOff,
+		dayOffButtonText,
+	)
+	if dayOffButtonText.Validate() != nil {
+		log.Printf("WARNING dayOff button display failed: %s", dayOffButtonText.Validate().Error())
+		return nil
+	}
+
+	return slack.NewActionBlock(
+		actionBlockId,
+		goodMoodButton,
+		averageMoodButton,
+		badMoodButton,
+		dayOffButton,
+	)
 }
 
-func drawResults(userWithDailyMoods []*User) ([]slack.Block, error) {
+func drawResults(userWithDailyMoods []*User, locale string) ([]slack.Block, error) {
 	blockMessageArray := []slack.Block{}
+	usersOff := []string{}
 	for _, u := range userWithDailyMoods {
 		if len(u.Moods) == 0 {
 			continue
+		} else if u.Moods[0].IsOff() {
+			usersOff = append(usersOff, u.Username)
+			continue
 		}
 		firstField := slackTextBlock(u.Username)
 		if firstField.Validate() != nil {
@@ -274,7 +321,7 @@ func drawResults(userWithDailyMoods []*User) ([]slack.Block, error) {
 					"%s %s %s",
 					FromMoodToSmiley(userMood),
 					FromFeelingToSmiley(userFeeling),
-					userFeeling,
+					T(locale, "feeling."+userFeeling),
 				),
 			)
 			if secondField.Validate() != nil {
@@ -286,7 +333,7 @@ func drawResults(userWithDailyMoods []*User) ([]slack.Block, error) {
 
 			fields = append(fields, secondField)
 		} else {
-			secondField := slackTextBlock(fmt.Sprintf("%s %s", FromMoodToSmiley(userMood), strings.ToUpper(strings.ReplaceAll(userMood, "_", " "))))
+			secondField := slackTextBlock(fmt.Sprintf("%s %s", FromMoodToSmiley(userMood), strings.ToUpper(T(locale, "mood.name."+userMood))))
 			if secondField.Validate() != nil {
 				return blockMessageArray, fmt.Errorf("#drawResults::second hasFeeling= %s", secondField.Validate().Error())
 			}
@@ -310,18 +357,28 @@ func drawResults(userWithDailyMoods []*User) ([]slack.Block, error) {
 		}
 
 	}
+
+	if len(usersOff) > 0 {
+		offText := slackMkDownBlock(T(locale, "checkin.users_off", strings.Join(usersOff, ", ")))
+		if offText.Validate() != nil {
+			return blockMessageArray, fmt.Errorf("#drawResults::offText = %s", offText.Validate().Error())
+		}
+		blockMessageArray = append(blockMessageArray, slack.NewContextBlock("users_off", offText))
+	}
 	return blockMessageArray, nil
 }
 
 func fromJsonToBlocks(
 	dbClient *gorm.DB,
-	channelId, threadTS string,
+	config *Config,
+	threadTS string,
 	firstPrint bool,
 ) slack.Message {
+	locale := config.LOCALE
 	var blockMessage slack.Message = slack.NewBlockMessage()
-	authorName, slackFirstSection := firstSectionBlock()
-	contextBlock := AddingContextAuthor(authorName)
-	actions := actionSectionBlock()
+	authorName, slackFirstSection := firstSectionBlock(dbClient, config, firstPrint)
+	contextBlock := AddingContextAuthor(authorName, locale)
+	actions := actionSectionBlock(locale)
 	blockMessage.Blocks.BlockSet = append(
 		blockMessage.Blocks.BlockSet,
 		slackFirstSection,
@@ -335,7 +392,7 @@ func fromJsonToBlocks(
 			panic(err)
 		}
 
-		blockMessageArray, err := drawResults(userWithDailyMoods)
+		blockMessageArray, err := drawResults(userWithDailyMoods, locale)
 		if err != nil {
 			log.Panicf("[ERROR] drawResults : %s", err.Error())
 		}
@@ -383,20 +440,87 @@ func FromFeelingToSmiley(feeling string) string {
 	}
 }
 
-func ContextInputText() *slack.InputBlock {
+// Moods is the ordered list of moods a user can share.
+var Moods = []string{"good_mood", "average_mood", "bad_mood"}
+
+// MoodFeelings lists the feelings available for each mood.
+var MoodFeelings = map[string][]string{
+	"good_mood":    {"Excited", "Happy", "Chilling"},
+	"average_mood": {"Neutral", "Frustrated", "Tired"},
+	"bad_mood":     {"Sad", "Mad", "Disappointed"},
+}
+
+// FromFeelingToMood returns the mood a feeling belongs to or an empty string.
+func FromFeelingToMood(feeling string) string {
+	for mood, feelings := range MoodFeelings {
+		for _, f := range feelings {
+			if f == feeling {
+				return mood
+			}
+		}
+	}
+	return ""
+}
+
+// ParseFeeling matches a free text word against known feelings in any locale (case insensitive).
+func ParseFeeling(word string) string {
+	for _, feelings := range MoodFeelings {
+		for _, f := range feelings {
+			if strings.EqualFold(f, word) {
+				return f
+			}
+			for locale := range catalog {
+				if strings.EqualFold(T(locale, "feeling."+f), word) {
+					return f
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// FromReactionToMood maps an emoji reaction name (without colons) to a mood and an optional feeling.
+// Both are empty when the reaction is not a mood.
+func FromReactionToMood(reaction string) (string, string) {
+	reaction = strings.SplitN(reaction, "::", 2)[0]
+	smiley := fmt.Sprintf(":%s:", reaction)
+	for _, mood := range Moods {
+		if FromMoodToSmiley(mood) == smiley {
+			return mood, ""
+		}
+		for _, feeling := range MoodFeelings[mood] {
+			if FromFeelingToSmiley(feeling) == smiley {
+				return mood, feeling
+			}
+		}
+	}
+	return "", ""
+}
+
+func ContextInputText(locale string) *slack.InputBlock {
 	blockId := "MoodContext"
 	actionId := "mood_ctxt"
 
 	// If does not work use true as emoji
-	inputBlockElem := slack.NewPlainTextInputBlockElement(slackTextBlock("Context"), actionId)
-	inputBlock := slack.NewInputBlock(blockId, slackTextBlock("Context"), nil, inputBlockElem)
+	inputBlockElem := slack.NewPlainTextInputBlockElement(
+		slackTextBlock(T(locale, "input.context.label")),
+		actionId,
+	)
+	inputBlock := slack.NewInputBlock(
+		blockId,
+		slackTextBlock(T(locale, "input.context.label")),
+		nil,
+		inputBlockElem,
+	)
 
 	// Modifiers
+	inputBlockElem.MaxLength = MaxContextLength
+	inputBlockElem.Multiline = true
 	inputBlock.DispatchAction = false
 	inputBlock.Optional = true
 	inputBlock.Hint = slack.NewTextBlockObject(
 		slack.PlainTextType,
-		"To add a bit of context",
+		T(locale, "input.context.hint"),
 		true,
 		false,
 	)
@@ -404,6 +528,86 @@ func ContextInputText() *slack.InputBlock {
 	return inputBlock
 }
 
+// MoodLabel returns the text displayed on the button of a mood.
+func MoodLabel(mood, locale string) string {
+	if _, ok := MoodFeelings[mood]; !ok {
+		return FromMoodToSmiley(mood)
+	}
+	return T(locale, "mood.label."+mood)
+}
+
+// MoodInputRadio renders the mood choice of the mood modal with initialMood already selected.
+func MoodInputRadio(initialMood, locale string) *slack.InputBlock {
+	blockId := "MoodSelect"
+	actionId := "mood_select"
+
+	options := []*slack.OptionBlockObject{}
+	var initialOption *slack.OptionBlockObject
+	for _, mood := range Moods {
+		option := slack.NewOptionBlockObject(mood, slackTextBlock(MoodLabel(mood, locale)), nil)
+		if mood == initialMood {
+			initialOption = option
+		}
+		options = append(options, option)
+	}
+
+	radio := slack.NewRadioButtonsBlockElement(actionId, options...)
+	radio.InitialOption = initialOption
+	return slack.NewInputBlock(blockId, slackTextBlock(T(locale, "input.mood.label")), nil, radio)
+}
+
+// FeelingInputSelect renders the feeling choice of the mood modal grouped by mood.
+func FeelingInputSelect(locale string) *slack.InputBlock {
+	blockId := "MoodFeeling"
+	actionId := "mood_feeling"
+
+	groups := []*slack.OptionGroupBlockObject{}
+	for _, mood := range Moods {
+		options := []*slack.OptionBlockObject{}
+		for _, feeling := range MoodFeelings[mood] {
+			text := fmt.Sprintf("%s %s", T(locale, "feeling."+feeling), FromFeelingToSmiley(feeling))
+			options = append(options, slack.NewOptionBlockObject(feeling, slackTextBlock(text), nil))
+		}
+		groups = append(
+			groups,
+			slack.NewOptionGroupBlockElement(slackTextBlock(MoodLabel(mood, locale)), options...),
+		)
+	}
+
+	selectElement := slack.NewOptionsGroupSelectBlockElement(
+		slack.OptTypeStatic,
+		slackTextBlock(T(locale, "input.feeling.placeholder")),
+		actionId,
+		groups...,
+	)
+	inputBlock := slack.NewInputBlock(
+		blockId,
+		slackTextBlock(T(locale, "input.feeling.label")),
+		nil,
+		selectElement,
+	)
+	inputBlock.Optional = true
+	return inputBlock
+}
+
+// MaxContextLength is the maximum number of characters of a mood context.
+const MaxContextLength = 500
+
+// ValidateMoodSubmission checks the values of the mood modal.
+// It returns errors keyed by block id as expected by response_action errors.
+func ValidateMoodSubmission(mood, feeling, context, locale string) map[string]string {
+	errs := map[string]string{}
+	if _, ok := MoodFeelings[mood]; !ok {
+		errs["MoodSelect"] = T(locale, "error.mood.missing")
+	} else if feeling != "" && FromFeelingToMood(feeling) != mood {
+		errs["MoodFeeling"] = T(locale, "error.feeling.mismatch", T(locale, "feeling."+feeling))
+	}
+	if len([]rune(context)) > MaxContextLength {
+		errs["MoodContext"] = T(locale, "error.context.too_long", MaxContextLength)
+	}
+	return errs
+}
+
 func SendSlackBlocks(
 	client *slack.Client,
 	config *Config,
@@ -411,7 +615,7 @@ func SendSlackBlocks(
 	threadTS string,
 	firstPrint bool,
 ) (string, error) {
-	blockMessage := fromJsonToBlocks(dbClient, config.CHANNEL_ID, threadTS, firstPrint)
+	blockMessage := fromJsonToBlocks(dbClient, config, threadTS, firstPrint)
 	_, threadTS, err := client.PostMessage(
 		config.CHANNEL_ID,
 		slack.MsgOptionBlocks(blockMessage.Blocks.BlockSet...),
@@ -428,7 +632,7 @@ func UpdateMessage(
 	dbClient *gorm.DB,
 	threadTS string,
 ) (string, error) {
-	slackMessage := fromJsonToBlocks(dbClient, config.CHANNEL_ID, threadTS, false)
+	slackMessage := fromJsonToBlocks(dbClient, config, threadTS, false)
 	_, newThreadTS, _, err := client.UpdateMessage(
 		config.CHANNEL_ID,
 		threadTS,
@@ -1,6 +1,7 @@
 package simba_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/saisona/simba"
@@ -10,13 +11,13 @@ import (
 )
 
 func TestAddingContextAuthor(t *testing.T) {
-	contextBlock := simba.AddingContextAuthor("fake_author")
+	contextBlock := simba.AddingContextAuthor("fake_author", simba.DefaultLocale)
 	assert.Contains(t, contextBlock.BlockID, "author_context_qotd_", "BlockId wrong")
 	assert.Len(t, contextBlock.ContextElements.Elements, 1, "Should always have only 1 elem")
 }
 
 func TestContextInputText(t *testing.T) {
-	contextInput := simba.ContextInputText()
+	contextInput := simba.ContextInputText(simba.DefaultLocale)
 	assert.Equal(t, contextInput.BlockID, "MoodContext", "Should be MoodContext")
 	assert.NotEqual(t, contextInput.Element, nil)
 	assert.IsType(t, &slack.PlainTextInputBlockElement{}, contextInput.Element)
@@ -244,3 +245,100 @@ func TestDrawResultsTwoUsersOneMoodOneFeelingWithContext(t *testing.T) {
 	// anyMixedBlock2 := contextBlock2.ContextElements.Elements[0].(*slack.TextBlockObject)
 	// assert.Equal(t, anyMixedBlock2.Text, "Wanna cry")
 }
+
+func TestMoodInputRadio(t *testing.T) {
+	moodInput := simba.MoodInputRadio("average_mood", simba.DefaultLocale)
+	assert.Equal(t, "MoodSelect", moodInput.BlockID)
+	assert.False(t, moodInput.Optional)
+	assert.IsType(t, &slack.RadioButtonsBlockElement{}, moodInput.Element)
+
+	radio := moodInput.Element.(*slack.RadioButtonsBlockElement)
+	assert.Equal(t, "mood_select", radio.ActionID)
+	assert.Len(t, radio.Options, 3)
+	assert.Equal(t, "average_mood", radio.InitialOption.Value)
+}
+
+func TestFeelingInputSelect(t *testing.T) {
+	feelingInput := simba.FeelingInputSelect(simba.DefaultLocale)
+	assert.Equal(t, "MoodFeeling", feelingInput.BlockID)
+	assert.True(t, feelingInput.Optional)
+	assert.IsType(t, &slack.SelectBlockElement{}, feelingInput.Element)
+
+	selectElement := feelingInput.Element.(*slack.SelectBlockElement)
+	assert.Equal(t, "mood_feeling", selectElement.ActionID)
+	assert.Len(t, selectElement.OptionGroups, 3)
+}
+
+func TestValidateMoodSubmission(t *testing.T) {
+	assert.Empty(t, simba.ValidateMoodSubmission("good_mood", "", "", simba.DefaultLocale))
+	assert.Empty(t, simba.ValidateMoodSubmission("good_mood", "Happy", "Small one", simba.DefaultLocale))
+
+	errs := simba.ValidateMoodSubmission("", "", "", simba.DefaultLocale)
+	assert.Contains(t, errs, "MoodSelect")
+
+	errs = simba.ValidateMoodSubmission("good_mood", "Sad", "", simba.DefaultLocale)
+	assert.Contains(t, errs, "MoodFeeling")
+
+	errs = simba.ValidateMoodSubmission(
+		"bad_mood",
+		"Sad",
+		strings.Repeat("a", simba.MaxContextLength+1),
+		simba.DefaultLocale,
+	)
+	assert.Contains(t, errs, "MoodContext")
+}
+
+func TestFromReactionToMood(t *testing.T) {
+	mood, feeling := simba.FromReactionToMood("heart")
+	assert.Equal(t, "good_mood", mood)
+	assert.Equal(t, "", feeling)
+
+	mood, feeling = simba.FromReactionToMood("black_heart")
+	assert.Equal(t, "bad_mood", mood)
+	assert.Equal(t, "", feeling)
+
+	mood, feeling = simba.FromReactionToMood("yawning_face")
+	assert.Equal(t, "average_mood", mood)
+	assert.Equal(t, "Tired", feeling)
+
+	mood, feeling = simba.FromReactionToMood("thumbsup::skin-tone-2")
+	assert.Equal(t, "", mood)
+	assert.Equal(t, "", feeling)
+}
+
+func TestDrawResultsUserOff(t *testing.T) {
+	fakeMood := simba.DailyMood{
+		Model:   gorm.Model{ID: 1},
+		UserID:  1,
+		Mood:    "good_mood",
+		Feeling: "Happy",
+	}
+	fakeDayOff := simba.DailyMood{
+		Model:  gorm.Model{ID: 2},
+		UserID: 2,
+		Status: simba.DailyMoodStatusOff,
+	}
+	fakeSimbaUser1 := &simba.User{
+		Model:    gorm.Model{ID: 1},
+		Username: "fake_username",
+		Moods:    []simba.DailyMood{fakeMood},
+	}
+	fakeSimbaUser2 := &simba.User{
+		Model:    gorm.Model{ID: 2},
+		Username: "fake_username_off",
+		Moods:    []simba.DailyMood{fakeDayOff},
+	}
+	slackBlocks, err := simba.DrawResults([]*simba.User{fakeSimbaUser2, fakeSimbaUser1})
+	if err != nil {
+		t.Error(err)
+	}
+	assert.Len(t, slackBlocks, 2)
+	assert.IsType(t, &slack.SectionBlock{}, slackBlocks[0])
+	assert.Equal(t, "fake_username", slackBlocks[0].(*slack.SectionBlock).Fields[0].Text)
+
+	assert.IsType(t, &slack.ContextBlock{}, slackBlocks[1])
+	contextBlock := slackBlocks[1].(*slack.ContextBlock)
+	assert.Equal(t, "users_off", contextBlock.BlockID)
+	offText := contextBlock.ContextElements.Elements[0].(*slack.TextBlockObject)
+	assert.Equal(t, ":zzz: Not working today: fake_username_off", offText.Text)
+}
@@ -0,0 +1,302 @@
+package simba
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"io"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/saisona/simba/plot"
+	"github.com/slack-go/slack"
+	"gorm.io/gorm"
+)
+
+// DominantMood returns the most shared mood (or an empty string if nobody answered).
+func DominantMood(userWithDailyMoods []*User) string {
+	moodCount, _ := CountMoods(userWithDailyMoods)
+	dominantMood := ""
+	for _, mood := range Moods {
+		if moodCount[mood] > 0 && moodCount[mood] > moodCount[dominantMood] {
+			dominantMood = mood
+		}
+	}
+	return dominantMood
+}
+
+// CountMoods counts the moods of the users by mood and in total.
+func CountMoods(userWithDailyMoods []*User) (map[string]int, int) {
+	total := 0
+	moodCount := map[string]int{}
+	for _, u := range userWithDailyMoods {
+		for _, m := range u.Moods {
+			if m.IsOff() {
+				continue
+			}
+			moodCount[m.Mood] += 1
+			total += 1
+		}
+	}
+	return moodCount, total
+}
+
+// CountDaysOff counts the users who said they were not working.
+func CountDaysOff(userWithDailyMoods []*User) int {
+	daysOff := 0
+	for _, u := range userWithDailyMoods {
+		for _, m := range u.Moods {
+			if m.IsOff() {
+				daysOff += 1
+			}
+		}
+	}
+	return daysOff
+}
+
+// FetchLatestThreadTS returns the thread of the daily mood answered since the given date.
+func FetchLatestThreadTS(dbClient *gorm.DB, since time.Time) (string, error) {
+	var dailyMood DailyMood
+	tx := dbClient.Where("created_at >= ?", since).Order("created_at DESC").Limit(1).Find(&dailyMood)
+	if tx.Error != nil {
+		return "", tx.Error
+	}
+	return dailyMood.ThreadTS, nil
+}
+
+// DailySummaryText renders the mood percentages of a daily mood thread, hidden when fewer
+// than minGroupSize people shared a mood.
+func DailySummaryText(userWithDailyMoods []*User, minGroupSize int, locale string) string {
+	people := countUsersWithMood(userWithDailyMoods)
+	return summaryText(userWithDailyMoods, people, minGroupSize, "mention.stats.title", "mention.stats.off", locale)
+}
+
+// WeeklySummaryText renders the mood percentages of all the moods shared in a week, hidden
+// when fewer than minGroupSize people shared a mood.
+func WeeklySummaryText(moods []DailyMood, minGroupSize int, locale string) string {
+	people := CountPeopleWithMood(moods)
+	return summaryText([]*User{{Moods: moods}}, people, minGroupSize, "summary.weekly.title", "summary.weekly.off", locale)
+}
+
+func summaryText(userWithDailyMoods []*User, people, minGroupSize int, titleKey, offKey, locale string) string {
+	moodCount, total := CountMoods(userWithDailyMoods)
+	daysOff := CountDaysOff(userWithDailyMoods)
+	if total == 0 && daysOff == 0 {
+		return T(locale, "mention.stats.none")
+	}
+
+	lines := []string{}
+	if total > 0 && !IsAnonymous(people, minGroupSize) {
+		lines = append(lines, T(locale, "summary.hidden", minGroupSize))
+	} else if total > 0 {
+		lines = append(lines, T(locale, titleKey, total))
+		for _, mood := range Moods {
+			percent := float64(moodCount[mood]) / float64(total) * 100
+			lines = append(lines, fmt.Sprintf("%s %.2f%%", FromMoodToSmiley(mood), percent))
+		}
+	}
+	if daysOff > 0 {
+		lines = append(lines, T(locale, offKey, daysOff))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// moodColors are the colors of each mood (and of days off) in charts.
+var moodColors = map[string]color.Color{
+	"good_mood":        color.RGBA{0x2e, 0xb6, 0x7d, 0xff},
+	"average_mood":     color.RGBA{0xec, 0xb2, 0x2e, 0xff},
+	"bad_mood":         color.RGBA{0xe0, 0x1e, 0x5a, 0xff},
+	DailyMoodStatusOff: color.RGBA{0x9e, 0x9e, 0x9e, 0xff},
+}
+
+// RenderDailyMoodChart draws the number of people by mood (and off) as a PNG bar chart.
+func RenderDailyMoodChart(userWithDailyMoods []*User, locale string, w io.Writer) error {
+	moodCount, _ := CountMoods(userWithDailyMoods)
+	bars := []plot.Bar{}
+	for _, mood := range Moods {
+		bars = append(bars, plot.Bar{
+			Label: T(locale, "mood.name."+mood),
+			Value: float64(moodCount[mood]),
+			Color: moodColors[mood],
+		})
+	}
+	bars = append(bars, plot.Bar{
+		Label: T(locale, "summary.chart.off"),
+		Value: float64(CountDaysOff(userWithDailyMoods)),
+		Color: moodColors[DailyMoodStatusOff],
+	})
+	chart := &plot.BarChart{Title: T(locale, "summary.chart.daily"), Bars: bars}
+	return chart.Render(w)
+}
+
+// RenderWeeklyMoodHeatmap draws how many people shared each mood each working day
+// of the week starting at weekStart. Days when fewer than minGroupSize people shared a
+// mood are left blank.
+func RenderWeeklyMoodHeatmap(
+	moods []DailyMood,
+	weekStart time.Time,
+	minGroupSize int,
+	locale string,
+	w io.Writer,
+) error {
+	const workingDays = 5
+	rows := []string{}
+	values := [][]float64{}
+	for _, mood := range Moods {
+		rows = append(rows, T(locale, "mood.name."+mood))
+		values = append(values, make([]float64, workingDays))
+	}
+	columns := make([]string, workingDays)
+	for day := 0; day < workingDays; day++ {
+		columns[day] = FormatDay(weekStart.AddDate(0, 0, day), locale)
+	}
+
+	moodsByDay := make([][]DailyMood, workingDays)
+	for _, m := range moods {
+		day := int(HolidayDate(m.CreatedAt).Sub(HolidayDate(weekStart)).Hours() / 24)
+		if m.IsOff() || day < 0 || day >= workingDays {
+			continue
+		}
+		moodsByDay[day] = append(moodsByDay[day], m)
+	}
+	for day, dayMoods := range moodsByDay {
+		if !IsAnonymous(CountPeopleWithMood(dayMoods), minGroupSize) {
+			continue
+		}
+		for _, m := range dayMoods {
+			for idx, mood := range Moods {
+				if m.Mood == mood {
+					values[idx][day]++
+				}
+			}
+		}
+	}
+
+	heatmap := &plot.Heatmap{
+		Title:   T(locale, "summary.chart.weekly", FormatDay(weekStart, locale)),
+		Rows:    rows,
+		Columns: columns,
+		Values:  values,
+	}
+	return heatmap.Render(w)
+}
+
+// FetchMoodsBetween returns the moods (and days off) shared between from and to.
+func FetchMoodsBetween(dbClient *gorm.DB, from, to time.Time) ([]DailyMood, error) {
+	var moods []DailyMood
+	if tx := dbClient.Where("created_at BETWEEN ? AND ?", from, to).Find(&moods); tx.Error != nil {
+		return nil, tx.Error
+	}
+	return moods, nil
+}
+
+// SendDailySummary posts the end of day summary in the thread of today's daily mood,
+// with a GIF matching the dominant mood when Giphy is configured.
+func SendDailySummary(dbClient *gorm.DB, client *slack.Client, config *Config) error {
+	now := time.Now()
+	startOfDay := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	threadTS, err := FetchLatestThreadTS(dbClient, startOfDay)
+	if err != nil {
+		return err
+	} else if threadTS == "" {
+		log.Printf("No daily mood has been shared today, skipping summary")
+		return nil
+	}
+
+	userWithDailyMoods, err := FetchAllDailyMoodsByThreadTS(dbClient, threadTS)
+	if err != nil {
+		return err
+	}
+	summary := DailySummaryText(userWithDailyMoods, config.MIN_GROUP_SIZE, config.LOCALE)
+	if _, err := SendSlackTSMessage(client, config, summary, threadTS); err != nil {
+		return err
+	}
+	_, answers := CountMoods(userWithDailyMoods)
+	daysOff := CountDaysOff(userWithDailyMoods)
+	poll := WebhookPollData{ChannelID: config.CHANNEL_ID, ThreadTS: threadTS, Answers: &answers, DaysOff: &daysOff}
+	if err := EmitWebhookEvent(dbClient, WebhookEventPollClosed, poll); err != nil {
+		log.Printf("Failed to emit %s : %s", WebhookEventPollClosed, err.Error())
+	}
+	// The chart and the GIF of the dominant mood tell as much as the percentages
+	if !IsAnonymous(countUsersWithMood(userWithDailyMoods), config.MIN_GROUP_SIZE) {
+		return nil
+	}
+
+	var chart bytes.Buffer
+	if err := RenderDailyMoodChart(userWithDailyMoods, config.LOCALE, &chart); err != nil {
+		log.Printf("Failed to render summary chart : %s", err.Error())
+	} else if err := SendImage(
+		client,
+		config.CHANNEL_ID,
+		threadTS,
+		&chart,
+		fmt.Sprintf("daily_mood_%s.png", now.Format("2006-01-02")),
+		T(config.LOCALE, "summary.chart.daily"),
+		"",
+	); err != nil {
+		log.Printf("Failed to send summary chart : %s", err.Error())
+	}
+
+	if giphyClient := NewGiphyClient(config); giphyClient != nil {
+		tag := config.GIPHY_TAG
+		if tag == "" {
+			tag = GiphyTagFromMood(DominantMood(userWithDailyMoods))
+		}
+		if err := SendGif(client, giphyClient, config.CHANNEL_ID, threadTS, tag); err != nil {
+			log.Printf("Failed to send summary gif : %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// SendWeeklySummary posts the mood percentages of the current week with a heatmap of
+// the moods of each day.
+func SendWeeklySummary(dbClient *gorm.DB, client *slack.Client, config *Config) error {
+	weekStart := WeekStart(time.Now())
+	start := time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day(), 0, 0, 0, 0, time.Local)
+	moods, err := FetchMoodsBetween(dbClient, start, start.AddDate(0, 0, 7))
+	if err != nil {
+		return err
+	} else if len(moods) == 0 {
+		log.Printf("No daily mood has been shared this week, skipping weekly summary")
+		return nil
+	}
+
+	summary := WeeklySummaryText(moods, config.MIN_GROUP_SIZE, config.LOCALE)
+	digest := NewWeeklyDigest(moods, weekStart, config.MIN_GROUP_SIZE)
+	digest.DashboardURL = config.DASHBOARD_URL
+	members, err := FetchTeamParticipation(dbClient, client, config.CHANNEL_ID, start, time.Now())
+	if err != nil {
+		log.Printf("Failed to fetch weekly participation : %s", err.Error())
+	} else {
+		participation := TeamParticipation(members)
+		summary += "\n" + ParticipationText(participation, config.LOCALE)
+		digest.Participation = &participation
+	}
+
+	threadTS, err := SendSlackMessage(client, config, summary)
+	if err != nil {
+		return err
+	}
+	if err := SendWeeklyDigestEmails(dbClient, client, config, digest); err != nil {
+		log.Printf("#SendWeeklyDigestEmails error => %s", err)
+	}
+	if !IsAnonymous(CountPeopleWithMood(moods), config.MIN_GROUP_SIZE) {
+		return nil
+	}
+
+	var heatmap bytes.Buffer
+	if err := RenderWeeklyMoodHeatmap(moods, weekStart, config.MIN_GROUP_SIZE, config.LOCALE, &heatmap); err != nil {
+		return err
+	}
+	return SendImage(
+		client,
+		config.CHANNEL_ID,
+		threadTS,
+		&heatmap,
+		fmt.Sprintf("weekly_mood_%s.png", weekStart.Format("2006-01-02")),
+		T(config.LOCALE, "summary.chart.weekly", FormatDay(weekStart, config.LOCALE)),
+		"",
+	)
+}
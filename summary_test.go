@@ -0,0 +1,88 @@
+package simba_test
+
+import (
+	"bytes"
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+)
+
+func fakeUsersWithMoods(moods ...string) []*simba.User {
+	users := []*simba.User{}
+	for _, mood := range moods {
+		users = append(users, &simba.User{Moods: []simba.DailyMood{{Mood: mood}}})
+	}
+	return users
+}
+
+func TestDominantMood(t *testing.T) {
+	assert.Equal(t, "", simba.DominantMood(fakeUsersWithMoods()))
+	assert.Equal(t, "bad_mood", simba.DominantMood(fakeUsersWithMoods("bad_mood", "bad_mood", "good_mood")))
+	assert.Equal(t, "good_mood", simba.DominantMood(fakeUsersWithMoods("average_mood", "good_mood")))
+}
+
+func TestDailySummaryText(t *testing.T) {
+	assert.Equal(t, "Nobody shared their mood yet today.", simba.DailySummaryText(fakeUsersWithMoods(), 1, "en"))
+	assert.Equal(
+		t,
+		"Today's mood (2 answers):\n:heart: 50.00%\n:yellow_heart: 0.00%\n:black_heart: 50.00%",
+		simba.DailySummaryText(fakeUsersWithMoods("good_mood", "bad_mood"), 1, "en"),
+	)
+}
+
+func TestDailySummaryTextWithDaysOff(t *testing.T) {
+	users := fakeUsersWithMoods("good_mood")
+	users = append(users, &simba.User{Moods: []simba.DailyMood{{Status: simba.DailyMoodStatusOff}}})
+
+	moodCount, total := simba.CountMoods(users)
+	assert.Equal(t, 1, total)
+	assert.Equal(t, 1, moodCount["good_mood"])
+	assert.Equal(t, 1, simba.CountDaysOff(users))
+	assert.Equal(
+		t,
+		"Today's mood (1 answers):\n:heart: 100.00%\n:yellow_heart: 0.00%\n:black_heart: 0.00%\n:zzz: 1 not working today",
+		simba.DailySummaryText(users, 1, "en"),
+	)
+	assert.Equal(t, ":zzz: 1 not working today", simba.DailySummaryText(users[1:], 1, "en"))
+}
+
+func TestWeeklySummaryText(t *testing.T) {
+	moods := []simba.DailyMood{
+		{Mood: "good_mood"},
+		{Mood: "good_mood"},
+		{Mood: "average_mood"},
+		{Mood: "bad_mood"},
+		{Status: simba.DailyMoodStatusOff},
+	}
+	assert.Equal(
+		t,
+		"This week's mood (4 answers):\n:heart: 50.00%\n:yellow_heart: 25.00%\n:black_heart: 25.00%\n:zzz: 1 days off this week",
+		simba.WeeklySummaryText(moods, 1, "en"),
+	)
+}
+
+func TestRenderDailyMoodChart(t *testing.T) {
+	users := fakeUsersWithMoods("good_mood", "bad_mood", "good_mood")
+	var buffer bytes.Buffer
+	assert.Nil(t, simba.RenderDailyMoodChart(users, "fr", &buffer))
+	_, err := png.Decode(&buffer)
+	assert.Nil(t, err)
+}
+
+func TestRenderWeeklyMoodHeatmap(t *testing.T) {
+	monday := utcDay(2026, time.October, 12)
+	moods := []simba.DailyMood{
+		fakeMoodAt(1, "good_mood", monday),
+		fakeMoodAt(2, "bad_mood", monday.AddDate(0, 0, 2)),
+		// the week-end and the next week are left out
+		fakeMoodAt(1, "good_mood", monday.AddDate(0, 0, 5)),
+		fakeMoodAt(1, "good_mood", monday.AddDate(0, 0, 7)),
+	}
+	var buffer bytes.Buffer
+	assert.Nil(t, simba.RenderWeeklyMoodHeatmap(moods, monday, 1, "en", &buffer))
+	_, err := png.Decode(&buffer)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,181 @@
+package simba
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/saisona/simba/plot"
+	"gorm.io/gorm"
+)
+
+// TrendWindows are the number of weeks admins can look back on in the Home tab.
+var TrendWindows = []int{4, 12, 52}
+
+// TrendRollingWeeks is the number of weeks averaged together for the rolling mood score.
+const TrendRollingWeeks = 4
+
+// moodScores turns moods into a 0 to 100 score so they can be averaged.
+var moodScores = map[string]float64{
+	"good_mood":    100,
+	"average_mood": 50,
+	"bad_mood":     0,
+}
+
+// TrendPoint is the mood score and participation of a week.
+// Score is NaN when nobody, or too few people to stay anonymous, shared a mood and Participation
+// when there was no working day.
+type TrendPoint struct {
+	WeekStart     time.Time
+	Moods         int
+	ScoreSum      float64
+	Score         float64
+	Participation float64
+}
+
+// WeekStart returns the monday of the week of t.
+func WeekStart(t time.Time) time.Time {
+	day := HolidayDate(t)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// ComputeMoodTrend buckets moods by week between from and to, then computes the rolling
+// average score and the participation of teamSize people on working days.
+// The score is left out when fewer than minGroupSize people shared the moods averaged.
+func ComputeMoodTrend(
+	moods []DailyMood,
+	holidays []Holiday,
+	teamSize, minGroupSize int,
+	from, to time.Time,
+) []TrendPoint {
+	points := []TrendPoint{}
+	indexes := map[time.Time]int{}
+	for week := WeekStart(from); !week.After(HolidayDate(to)); week = week.AddDate(0, 0, 7) {
+		indexes[week] = len(points)
+		points = append(points, TrendPoint{WeekStart: week, Score: math.NaN(), Participation: math.NaN()})
+	}
+
+	answers := make([]map[string]bool, len(points))
+	people := make([]map[uint]bool, len(points))
+	for idx := range answers {
+		answers[idx] = map[string]bool{}
+		people[idx] = map[uint]bool{}
+	}
+	for _, m := range moods {
+		idx, ok := indexes[WeekStart(m.CreatedAt)]
+		if !ok {
+			continue
+		}
+		// Days off are answers but not moods
+		answers[idx][fmt.Sprintf("%d::%s", m.UserID, m.CreatedAt.Format("2006-01-02"))] = true
+		if score, isMood := moodScores[m.Mood]; isMood && !m.IsOff() {
+			points[idx].Moods++
+			points[idx].ScoreSum += score
+			people[idx][m.UserID] = true
+		}
+	}
+
+	for idx := range points {
+		moodCount, scoreSum := 0, 0.0
+		rollingPeople := map[uint]bool{}
+		for rolling := idx; rolling >= 0 && rolling > idx-TrendRollingWeeks; rolling-- {
+			moodCount += points[rolling].Moods
+			scoreSum += points[rolling].ScoreSum
+			for userId := range people[rolling] {
+				rollingPeople[userId] = true
+			}
+		}
+		if moodCount > 0 && IsAnonymous(len(rollingPeople), minGroupSize) {
+			points[idx].Score = scoreSum / float64(moodCount)
+		}
+
+		weekEnd := points[idx].WeekStart.AddDate(0, 0, 6)
+		if weekEnd.After(HolidayDate(to)) {
+			weekEnd = HolidayDate(to)
+		}
+		weekStart := points[idx].WeekStart
+		if weekStart.Before(HolidayDate(from)) {
+			weekStart = HolidayDate(from)
+		}
+		if expected := teamSize * WorkingDaysBetween(weekStart, weekEnd, holidays); expected > 0 {
+			points[idx].Participation = math.Min(100, float64(len(answers[idx]))/float64(expected)*100)
+		}
+	}
+	return points
+}
+
+// FetchMoodTrend computes the weekly trend of the moods shared between from and to by the
+// audience (Audience.SlackUserIDList, nil being everyone). The team is made of the users of the
+// audience not currently paused.
+func FetchMoodTrend(
+	dbClient *gorm.DB,
+	channelId string,
+	audience []string,
+	minGroupSize int,
+	from, to time.Time,
+) ([]TrendPoint, error) {
+	usersTx := dbClient.Model(&User{})
+	if audience != nil {
+		usersTx = usersTx.Where("slack_user_id IN ?", audience)
+	}
+	// The users query is used twice, as subquery and on its own
+	usersTx = usersTx.Session(&gorm.Session{})
+
+	var moods []DailyMood
+	tx := dbClient.Where("created_at BETWEEN ? AND ?", HolidayDate(from), HolidayDate(to).AddDate(0, 0, 1))
+	if audience != nil {
+		tx = tx.Where("user_id IN (?)", usersTx.Select("id"))
+	}
+	if tx := tx.Find(&moods); tx.Error != nil {
+		return nil, tx.Error
+	}
+	holidays, err := FetchHolidays(dbClient, channelId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var users []User
+	if tx := usersTx.Find(&users); tx.Error != nil {
+		return nil, tx.Error
+	}
+	teamSize := 0
+	for _, u := range users {
+		if !u.IsPaused(time.Now()) {
+			teamSize++
+		}
+	}
+	return ComputeMoodTrend(moods, holidays, teamSize, minGroupSize, from, to), nil
+}
+
+// RenderMoodTrend draws the score and participation of points as a PNG line chart.
+func RenderMoodTrend(points []TrendPoint, locale string, w io.Writer) error {
+	labels := make([]string, len(points))
+	scores := make([]float64, len(points))
+	participations := make([]float64, len(points))
+	for idx, p := range points {
+		labels[idx] = p.WeekStart.Format("02/01")
+		scores[idx] = p.Score
+		participations[idx] = p.Participation
+	}
+	title := T(locale, "trend.title")
+	if len(points) > 0 {
+		title = T(
+			locale,
+			"trend.title.range",
+			points[0].WeekStart.Format("02/01/2006"),
+			points[len(points)-1].WeekStart.AddDate(0, 0, 6).Format("02/01/2006"),
+		)
+	}
+	chart := &plot.LineChart{
+		Title:  title,
+		Labels: labels,
+		Min:    0,
+		Max:    100,
+		Series: []plot.Series{
+			{Name: T(locale, "trend.score", TrendRollingWeeks), Values: scores},
+			{Name: T(locale, "trend.participation"), Values: participations},
+		},
+	}
+	return chart.Render(w)
+}
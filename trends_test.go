@@ -0,0 +1,93 @@
+package simba_test
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func fakeMoodAt(userId uint, mood string, createdAt time.Time) simba.DailyMood {
+	return simba.DailyMood{Model: gorm.Model{CreatedAt: createdAt}, CreatedAt: createdAt, UserID: userId, Mood: mood}
+}
+
+func TestWeekStart(t *testing.T) {
+	// Wednesday 7 October 2026
+	assert.Equal(t, utcDay(2026, time.October, 5), simba.WeekStart(time.Date(2026, time.October, 7, 18, 0, 0, 0, time.UTC)))
+	assert.Equal(t, utcDay(2026, time.October, 5), simba.WeekStart(utcDay(2026, time.October, 5)))
+	assert.Equal(t, utcDay(2026, time.October, 5), simba.WeekStart(utcDay(2026, time.October, 11)))
+}
+
+func TestComputeMoodTrend(t *testing.T) {
+	from, to := utcDay(2026, time.October, 5), utcDay(2026, time.October, 18)
+	moods := []simba.DailyMood{
+		fakeMoodAt(1, "good_mood", utcDay(2026, time.October, 5)),
+		fakeMoodAt(2, "bad_mood", utcDay(2026, time.October, 5)),
+		fakeMoodAt(1, "good_mood", utcDay(2026, time.October, 6)),
+		{CreatedAt: utcDay(2026, time.October, 7), UserID: 2, Status: simba.DailyMoodStatusOff},
+	}
+	holidays := []simba.Holiday{{Date: utcDay(2026, time.October, 9)}}
+
+	points := simba.ComputeMoodTrend(moods, holidays, 2, 1, from, to)
+	assert.Len(t, points, 2)
+	assert.Equal(t, 3, points[0].Moods)
+	assert.InDelta(t, 200.0/3, points[0].Score, 0.001)
+	// 4 answers out of 2 people on 4 working days (friday is a holiday)
+	assert.InDelta(t, 50, points[0].Participation, 0.001)
+
+	// Nobody answered the second week, the rolling score keeps the first week
+	assert.Equal(t, 0, points[1].Moods)
+	assert.InDelta(t, 200.0/3, points[1].Score, 0.001)
+	assert.Equal(t, 0.0, points[1].Participation)
+}
+
+func TestComputeMoodTrendNoMood(t *testing.T) {
+	points := simba.ComputeMoodTrend(nil, nil, 0, 1, utcDay(2026, time.October, 5), utcDay(2026, time.October, 11))
+	assert.Len(t, points, 1)
+	assert.True(t, math.IsNaN(points[0].Score))
+	assert.True(t, math.IsNaN(points[0].Participation))
+}
+
+func TestRenderMoodTrend(t *testing.T) {
+	points := simba.ComputeMoodTrend(
+		[]simba.DailyMood{fakeMoodAt(1, "average_mood", utcDay(2026, time.October, 5))},
+		nil,
+		1,
+		1,
+		utcDay(2026, time.September, 1),
+		utcDay(2026, time.October, 11),
+	)
+	var chart bytes.Buffer
+	assert.Nil(t, simba.RenderMoodTrend(points, "fr", &chart))
+	img, err := png.Decode(&chart)
+	assert.Nil(t, err)
+	assert.Equal(t, 800, img.Bounds().Dx())
+}
+
+func TestFetchMoodTrendAudience(t *testing.T) {
+	from, to := utcDay(2026, time.October, 5), utcDay(2026, time.October, 18)
+
+	db, fake := newFakeGormDB(t, nil)
+	_, err := simba.FetchMoodTrend(db, "C1", []string{"U2", "U3"}, 3, from, to)
+	assert.Nil(t, err)
+	moods := fake.Queries(`SELECT * FROM "daily_moods"`)
+	if assert.Len(t, moods, 1) {
+		assert.Contains(t, moods[0].SQL, `user_id IN (SELECT "id" FROM "users" WHERE slack_user_id IN`)
+		assert.Contains(t, moods[0].Args, "U3")
+	}
+	users := fake.Queries(`SELECT * FROM "users"`)
+	if assert.Len(t, users, 1) {
+		assert.Contains(t, users[0].Args, "U2")
+	}
+
+	// Everyone's trend is not filtered
+	db, fake = newFakeGormDB(t, nil)
+	_, err = simba.FetchMoodTrend(db, "C1", nil, 3, from, to)
+	assert.Nil(t, err)
+	assert.NotContains(t, fake.Queries(`SELECT * FROM "daily_moods"`)[0].SQL, "slack_user_id")
+}
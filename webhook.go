@@ -0,0 +1,419 @@
+package simba
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	WebhookEventMoodRecorded   = "mood.recorded"
+	WebhookEventMoodUpdated    = "mood.updated"
+	WebhookEventPollOpened     = "poll.opened"
+	WebhookEventPollClosed     = "poll.closed"
+	WebhookEventAlertTriggered = "alert.triggered"
+
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+
+	// WebhookMaxAttempts is how many times a delivery is tried before being given up
+	WebhookMaxAttempts = 6
+	// WebhookRetryDelay is the wait after the first failed attempt, doubled after each other one
+	WebhookRetryDelay = 30 * time.Second
+	// WebhookPollInterval is how often the scheduler sends the deliveries due
+	WebhookPollInterval = 30 * time.Second
+
+	webhookSecretPrefix = "whsec_"
+	// webhookErrorLength keeps the delivery log readable when endpoints answer whole pages
+	webhookErrorLength = 200
+)
+
+// WebhookEvents lists every event a webhook can receive.
+var WebhookEvents = []string{
+	WebhookEventMoodRecorded,
+	WebhookEventMoodUpdated,
+	WebhookEventPollOpened,
+	WebhookEventPollClosed,
+	WebhookEventAlertTriggered,
+}
+
+// webhookHTTPClient sends the deliveries, endpoints being given a few seconds to answer.
+// Redirects are not followed, a delivery redirected elsewhere fails with the 3xx status.
+var webhookHTTPClient = func() *http.Client {
+	client := newControlledHTTPClient(10*time.Second, webhookAddressOnly)
+	client.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	return client
+}()
+
+// webhooksInProduction tells if webhooks must reach the internet over https. Elsewhere http
+// and local receivers are allowed, to try webhooks locally.
+func webhooksInProduction() bool {
+	return os.Getenv("APP_ENV") == "production"
+}
+
+// webhookAddressOnly refuses to deliver to an internal address once resolved, in production.
+func webhookAddressOnly(network, address string, conn syscall.RawConn) error {
+	if !webhooksInProduction() {
+		return nil
+	}
+	return publicAddressOnly(network, address, conn)
+}
+
+// Webhook is an URL receiving the events of Simba as signed JSON.
+type Webhook struct {
+	gorm.Model
+	URL string
+	// Secret signs the deliveries, it is shown once when the webhook is registered
+	Secret string
+	// Events is the comma separated events sent, every one of them when empty
+	Events               string
+	CreatedBySlackUserID string
+}
+
+// EventList returns the events sent to the webhook, empty meaning every one of them.
+func (w *Webhook) EventList() []string {
+	return splitList(w.Events)
+}
+
+// Subscribes tells if event is sent to the webhook.
+func (w *Webhook) Subscribes(event string) bool {
+	events := w.EventList()
+	for _, e := range events {
+		if e == event {
+			return true
+		}
+	}
+	return len(events) == 0
+}
+
+// WebhookDelivery is an event sent, or to be sent, to a webhook. Together they are the delivery log.
+type WebhookDelivery struct {
+	gorm.Model
+	WebhookID uint `gorm:"index"`
+	EventID   string
+	Event     string
+	Payload   string
+	Status    string `gorm:"index"`
+	Attempts  int
+	// NextAttemptAt is when a pending delivery is tried again
+	NextAttemptAt time.Time
+	LastAttemptAt *time.Time
+	StatusCode    int
+	Error         string
+	// RedeliveryOf is the delivery sent again by this one, 0 for a first delivery
+	RedeliveryOf uint
+}
+
+// WebhookEvent is the JSON body of every delivery.
+type WebhookEvent struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"created_at"`
+	Data      interface{} `json:"data"`
+}
+
+// WebhookMoodData is the data of mood.recorded and mood.updated, the context written by
+// people never leaves Simba.
+type WebhookMoodData struct {
+	ID          uint      `json:"id"`
+	SlackUserID string    `json:"slack_user_id"`
+	ChannelID   string    `json:"channel_id"`
+	Mood        string    `json:"mood,omitempty"`
+	Feeling     string    `json:"feeling,omitempty"`
+	Status      string    `json:"status"`
+	ThreadTS    string    `json:"thread_ts"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// NewWebhookMoodData describes the mood of user, an empty mood being a day off.
+func NewWebhookMoodData(user *User, mood *DailyMood) WebhookMoodData {
+	status := mood.Status
+	if mood.Mood == "" {
+		status = DailyMoodStatusOff
+	} else if status == "" {
+		status = DailyMoodStatusMood
+	}
+	return WebhookMoodData{
+		ID:          mood.ID,
+		SlackUserID: user.SlackUserID,
+		ChannelID:   user.SlackChannelId,
+		Mood:        mood.Mood,
+		Feeling:     mood.Feeling,
+		Status:      status,
+		ThreadTS:    mood.ThreadTS,
+		CreatedAt:   mood.CreatedAt,
+	}
+}
+
+// WebhookPollData is the data of poll.opened and poll.closed, answers being only known once closed.
+type WebhookPollData struct {
+	ChannelID string `json:"channel_id"`
+	ThreadTS  string `json:"thread_ts"`
+	Answers   *int   `json:"answers,omitempty"`
+	DaysOff   *int   `json:"days_off,omitempty"`
+}
+
+// WebhookAlertData is the data of alert.triggered.
+type WebhookAlertData struct {
+	SlackUserID         string   `json:"slack_user_id"`
+	Reasons             []string `json:"reasons"`
+	ManagerSlackUserIDs []string `json:"manager_slack_user_ids"`
+}
+
+// ValidateWebhookURL checks rawUrl is a public https URL, or an absolute http or https URL
+// outside production.
+func ValidateWebhookURL(rawUrl string) error {
+	if webhooksInProduction() {
+		return ValidatePublicURL(rawUrl)
+	}
+	parsed, err := url.Parse(rawUrl)
+	if err != nil {
+		return err
+	} else if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("%q is not an http or https URL", rawUrl)
+	}
+	return nil
+}
+
+// ValidateWebhookEvents checks events are known, none meaning every one of them.
+func ValidateWebhookEvents(events []string) error {
+	for _, event := range events {
+		known := false
+		for _, webhookEvent := range WebhookEvents {
+			known = known || event == webhookEvent
+		}
+		if !known {
+			return fmt.Errorf("unknown event %q", event)
+		}
+	}
+	return nil
+}
+
+// CreateWebhook registers rawUrl for events and generates the secret signing its deliveries.
+func CreateWebhook(dbClient *gorm.DB, rawUrl string, events []string, createdBySlackUserId string) (*Webhook, error) {
+	rawUrl = strings.TrimSpace(rawUrl)
+	if err := ValidateWebhookURL(rawUrl); err != nil {
+		return nil, err
+	} else if err := ValidateWebhookEvents(events); err != nil {
+		return nil, err
+	}
+	secret, err := RandomToken(24)
+	if err != nil {
+		return nil, err
+	}
+	webhook := &Webhook{
+		URL:                  rawUrl,
+		Secret:               webhookSecretPrefix + secret,
+		Events:               strings.Join(events, ","),
+		CreatedBySlackUserID: createdBySlackUserId,
+	}
+	if tx := dbClient.Create(webhook); tx.Error != nil {
+		return nil, tx.Error
+	}
+	return webhook, nil
+}
+
+// FetchWebhooks lists the webhooks not removed, oldest first.
+func FetchWebhooks(dbClient *gorm.DB) ([]Webhook, error) {
+	var webhooks []Webhook
+	if tx := dbClient.Order("created_at").Find(&webhooks); tx.Error != nil {
+		return nil, tx.Error
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook removes the webhook, its pending deliveries then fail.
+func DeleteWebhook(dbClient *gorm.DB, id uint) error {
+	tx := dbClient.Delete(&Webhook{}, id)
+	if tx.Error != nil {
+		return tx.Error
+	} else if tx.RowsAffected == 0 {
+		return fmt.Errorf("webhook %d does not exist", id)
+	}
+	return nil
+}
+
+// EmitWebhookEvent queues a delivery of the event to every webhook subscribing to it. Deliveries
+// are written with dbClient, so within its transaction if any, and sent by the scheduler.
+func EmitWebhookEvent(dbClient *gorm.DB, event string, data interface{}) error {
+	webhooks, err := FetchWebhooks(dbClient)
+	if err != nil {
+		return err
+	}
+	deliveries := []*WebhookDelivery{}
+	for idx := range webhooks {
+		if webhooks[idx].Subscribes(event) {
+			deliveries = append(deliveries, &WebhookDelivery{WebhookID: webhooks[idx].ID})
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	eventId, err := RandomToken(12)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	payload, err := json.Marshal(WebhookEvent{ID: "evt_" + eventId, Type: event, CreatedAt: now, Data: data})
+	if err != nil {
+		return err
+	}
+	for _, delivery := range deliveries {
+		delivery.EventID = "evt_" + eventId
+		delivery.Event = event
+		delivery.Payload = string(payload)
+		delivery.Status = WebhookDeliveryPending
+		delivery.NextAttemptAt = now
+	}
+	return dbClient.Create(&deliveries).Error
+}
+
+// SignWebhookPayload is the signature of a delivery sent at timestamp (unix seconds), given
+// in the X-Simba-Signature header so receivers can check it comes from Simba.
+func SignWebhookPayload(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookBackoff is the wait before the next attempt of a delivery tried attempts times.
+func WebhookBackoff(attempts int) time.Duration {
+	if attempts < 1 {
+		return 0
+	}
+	return WebhookRetryDelay << (attempts - 1)
+}
+
+// DeliverWebhook sends delivery to webhook and returns the status code of the answer,
+// an error meaning the delivery has to be tried again.
+func DeliverWebhook(client *http.Client, webhook *Webhook, delivery *WebhookDelivery, now time.Time) (int, error) {
+	payload := []byte(delivery.Payload)
+	req, err := http.NewRequest(http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Simba-Webhook")
+	req.Header.Set("X-Simba-Event", delivery.Event)
+	req.Header.Set("X-Simba-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-Simba-Timestamp", strconv.FormatInt(timestamp, 10))
+	req.Header.Set("X-Simba-Signature", SignWebhookPayload(webhook.Secret, timestamp, payload))
+
+	res, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(res.Body, webhookErrorLength))
+		return res.StatusCode, fmt.Errorf("%s : %s", res.Status, strings.TrimSpace(string(body)))
+	}
+	return res.StatusCode, nil
+}
+
+// recordAttempt updates delivery after an attempt answered with statusCode and err, giving up
+// after WebhookMaxAttempts.
+func (delivery *WebhookDelivery) recordAttempt(statusCode int, err error, now time.Time) {
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.StatusCode = statusCode
+	switch {
+	case err == nil:
+		delivery.Status = WebhookDeliveryDelivered
+		delivery.Error = ""
+	case delivery.Attempts >= WebhookMaxAttempts:
+		delivery.Status = WebhookDeliveryFailed
+		delivery.Error = err.Error()
+	default:
+		delivery.Error = err.Error()
+		delivery.NextAttemptAt = now.Add(WebhookBackoff(delivery.Attempts))
+	}
+}
+
+// DeliverDueWebhooks sends the pending deliveries whose next attempt is due, oldest first.
+func DeliverDueWebhooks(dbClient *gorm.DB, client *http.Client, now time.Time) error {
+	var deliveries []WebhookDelivery
+	tx := dbClient.Where("status = ? AND next_attempt_at <= ?", WebhookDeliveryPending, now).
+		Order("next_attempt_at").
+		Find(&deliveries)
+	if tx.Error != nil {
+		return tx.Error
+	} else if len(deliveries) == 0 {
+		return nil
+	}
+
+	webhooks, err := FetchWebhooks(dbClient)
+	if err != nil {
+		return err
+	}
+	webhooksById := map[uint]*Webhook{}
+	for idx := range webhooks {
+		webhooksById[webhooks[idx].ID] = &webhooks[idx]
+	}
+
+	for idx := range deliveries {
+		delivery := &deliveries[idx]
+		if webhook, ok := webhooksById[delivery.WebhookID]; !ok {
+			delivery.Status = WebhookDeliveryFailed
+			delivery.Error = "webhook removed"
+		} else {
+			statusCode, err := DeliverWebhook(client, webhook, delivery, now)
+			delivery.recordAttempt(statusCode, err, now)
+		}
+		if tx := dbClient.Save(delivery); tx.Error != nil {
+			return tx.Error
+		}
+	}
+	return nil
+}
+
+// RedeliverWebhook queues the payload of a past delivery again, the log keeping both.
+func RedeliverWebhook(dbClient *gorm.DB, deliveryId uint, now time.Time) (*WebhookDelivery, error) {
+	var source WebhookDelivery
+	if tx := dbClient.Find(&source, deliveryId); tx.Error != nil {
+		return nil, tx.Error
+	} else if source.ID == 0 {
+		return nil, fmt.Errorf("webhook delivery %d does not exist", deliveryId)
+	}
+	delivery := &WebhookDelivery{
+		WebhookID:     source.WebhookID,
+		EventID:       source.EventID,
+		Event:         source.Event,
+		Payload:       source.Payload,
+		Status:        WebhookDeliveryPending,
+		NextAttemptAt: now,
+		RedeliveryOf:  source.ID,
+	}
+	if tx := dbClient.Create(delivery); tx.Error != nil {
+		return nil, tx.Error
+	}
+	return delivery, nil
+}
+
+// FetchWebhookDeliveries returns the last limit deliveries, the most recent first.
+func FetchWebhookDeliveries(dbClient *gorm.DB, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	if tx := dbClient.Order("created_at DESC").Limit(limit).Find(&deliveries); tx.Error != nil {
+		return nil, tx.Error
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,153 @@
+package simba_test
+
+import (
+	"database/sql/driver"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/saisona/simba"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookSubscribes(t *testing.T) {
+	everything := &simba.Webhook{}
+	assert.Empty(t, everything.EventList())
+	for _, event := range simba.WebhookEvents {
+		assert.True(t, everything.Subscribes(event))
+	}
+
+	moods := &simba.Webhook{Events: "mood.recorded,mood.updated"}
+	assert.True(t, moods.Subscribes(simba.WebhookEventMoodUpdated))
+	assert.False(t, moods.Subscribes(simba.WebhookEventAlertTriggered))
+}
+
+func TestValidateWebhook(t *testing.T) {
+	assert.NoError(t, simba.ValidateWebhookURL("https://example.com/hooks/simba"))
+	assert.NoError(t, simba.ValidateWebhookURL("http://localhost:8080"))
+	assert.Error(t, simba.ValidateWebhookURL("ftp://example.com"))
+	assert.Error(t, simba.ValidateWebhookURL("example.com/hooks"))
+	assert.Error(t, simba.ValidateWebhookURL(""))
+
+	t.Setenv("APP_ENV", "production")
+	assert.NoError(t, simba.ValidateWebhookURL("https://example.com/hooks/simba"))
+	assert.Error(t, simba.ValidateWebhookURL("http://example.com/hooks/simba"))
+	assert.ErrorIs(t, simba.ValidateWebhookURL("https://10.0.0.12/hooks"), simba.ErrInternalAddress)
+
+	assert.NoError(t, simba.ValidateWebhookEvents(nil))
+	assert.NoError(t, simba.ValidateWebhookEvents(simba.WebhookEvents))
+	assert.Error(t, simba.ValidateWebhookEvents([]string{"mood.deleted"}))
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	signature := simba.SignWebhookPayload("whsec_test", 1700000000, []byte(`{"type":"poll.opened"}`))
+	assert.Equal(t, "sha256=9bb0b271b8dc21112aa800ae21cb82cd9de36619c35081f23335a12ea7397687", signature)
+	assert.NotEqual(t, signature, simba.SignWebhookPayload("whsec_test", 1700000001, []byte(`{"type":"poll.opened"}`)))
+	assert.NotEqual(t, signature, simba.SignWebhookPayload("whsec_other", 1700000000, []byte(`{"type":"poll.opened"}`)))
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, time.Duration(0), simba.WebhookBackoff(0))
+	assert.Equal(t, simba.WebhookRetryDelay, simba.WebhookBackoff(1))
+	assert.Equal(t, 4*simba.WebhookRetryDelay, simba.WebhookBackoff(3))
+}
+
+func TestDeliverWebhook(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	payload := `{"id":"evt_1","type":"mood.recorded"}`
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	webhook := &simba.Webhook{URL: server.URL, Secret: "whsec_test"}
+	delivery := &simba.WebhookDelivery{Event: simba.WebhookEventMoodRecorded, Payload: payload}
+	delivery.ID = 42
+
+	statusCode, err := simba.DeliverWebhook(server.Client(), webhook, delivery, now)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, statusCode)
+	assert.Equal(t, http.MethodPost, received.Method)
+	assert.Equal(t, payload, string(body))
+	assert.Equal(t, "application/json", received.Header.Get("Content-Type"))
+	assert.Equal(t, "mood.recorded", received.Header.Get("X-Simba-Event"))
+	assert.Equal(t, "42", received.Header.Get("X-Simba-Delivery"))
+	assert.Equal(t, "1700000000", received.Header.Get("X-Simba-Timestamp"))
+	assert.Equal(t, simba.SignWebhookPayload("whsec_test", 1700000000, []byte(payload)), received.Header.Get("X-Simba-Signature"))
+}
+
+func TestDeliverWebhookFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	webhook := &simba.Webhook{URL: server.URL, Secret: "whsec_test"}
+	statusCode, err := simba.DeliverWebhook(server.Client(), webhook, &simba.WebhookDelivery{Payload: "{}"}, time.Now())
+	assert.Equal(t, http.StatusServiceUnavailable, statusCode)
+	assert.ErrorContains(t, err, "maintenance")
+}
+
+func TestDeliverWebhookRedirect(t *testing.T) {
+	redirected := false
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer server.Close()
+
+	webhook := &simba.Webhook{URL: server.URL, Secret: "whsec_test"}
+	statusCode, err := simba.DeliverWebhook(simba.WebhookHTTPClient, webhook, &simba.WebhookDelivery{Payload: "{}"}, time.Now())
+	assert.Equal(t, http.StatusTemporaryRedirect, statusCode)
+	assert.Error(t, err)
+	assert.False(t, redirected)
+}
+
+func TestDeliverWebhookInternalAddress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	t.Setenv("APP_ENV", "production")
+	webhook := &simba.Webhook{URL: server.URL, Secret: "whsec_test"}
+	_, err := simba.DeliverWebhook(simba.WebhookHTTPClient, webhook, &simba.WebhookDelivery{Payload: "{}"}, time.Now())
+	assert.ErrorIs(t, err, simba.ErrInternalAddress)
+}
+
+func TestSaveDailyMoodWebhookPayload(t *testing.T) {
+	db, fake := newFakeGormDB(t, map[string]fakeTable{
+		"daily_moods": {
+			Columns: []string{"id", "user_id", "mood", "thread_ts"},
+			Rows:    [][]driver.Value{{int64(2), int64(1), "bad_mood", "1700000000.000100"}},
+		},
+		"webhooks": {
+			Columns: []string{"id", "url", "secret"},
+			Rows:    [][]driver.Value{{int64(7), "https://example.com/hooks", "whsec_test"}},
+		},
+	})
+
+	dailyMood, err := simba.SaveDailyMood(db, nil, "C1", "U1", "lea", "bad_mood", "Tired", "", "1700000000.000100")
+	assert.Nil(t, err)
+	assert.Equal(t, "Tired", dailyMood.Feeling)
+
+	inserts := fake.Queries(`INSERT INTO "webhook_deliveries"`)
+	if assert.Len(t, inserts, 1) {
+		payload := ""
+		for _, arg := range inserts[0].Args {
+			if str, ok := arg.(string); ok && strings.HasPrefix(str, "{") {
+				payload = str
+			}
+		}
+		assert.Contains(t, payload, `"type":"mood.recorded"`)
+		assert.Contains(t, payload, `"feeling":"Tired"`)
+	}
+}